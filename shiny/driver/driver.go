@@ -21,5 +21,21 @@ import (
 // specific libraries require being on 'the main thread'. It returns when f
 // returns.
 func Main(f func(screen.Screen)) {
-	main(f)
+	main(nil, f)
+}
+
+// Options are optional arguments to MainWithOptions.
+type Options struct {
+	// Display, if non-empty, names the display to connect to, for drivers
+	// that can connect to more than one. For the X11 driver, it is an X11
+	// display name such as "host:0.1", for example of a nested or remote X
+	// server, instead of the DISPLAY environment variable. Other drivers
+	// ignore it.
+	Display string
+}
+
+// MainWithOptions is like Main, but with the given options. A nil opts is
+// valid and means to use the default option values.
+func MainWithOptions(opts *Options, f func(screen.Screen)) {
+	main(opts, f)
 }
@@ -11,6 +11,6 @@ import (
 	"golang.org/x/exp/shiny/screen"
 )
 
-func main(f func(screen.Screen)) {
+func main(opts *Options, f func(screen.Screen)) {
 	gldriver.Main(f)
 }
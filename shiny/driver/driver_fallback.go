@@ -17,6 +17,6 @@ import (
 	"golang.org/x/exp/shiny/screen"
 )
 
-func main(f func(screen.Screen)) {
+func main(opts *Options, f func(screen.Screen)) {
 	f(errscreen.Stub(errors.New("no driver for accessing a screen")))
 }
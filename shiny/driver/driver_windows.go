@@ -9,6 +9,6 @@ import (
 	"golang.org/x/exp/shiny/screen"
 )
 
-func main(f func(screen.Screen)) {
+func main(opts *Options, f func(screen.Screen)) {
 	windriver.Main(f)
 }
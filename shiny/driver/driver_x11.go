@@ -11,6 +11,10 @@ import (
 	"golang.org/x/exp/shiny/screen"
 )
 
-func main(f func(screen.Screen)) {
-	x11driver.Main(f)
+func main(opts *Options, f func(screen.Screen)) {
+	display := ""
+	if opts != nil {
+		display = opts.Display
+	}
+	x11driver.MainDisplay(display, f)
 }
@@ -194,8 +194,8 @@ func onExpose(id uintptr) {
 
 //export onKeysym
 func onKeysym(k, unshifted, shifted uint32) {
-	theKeysyms[k][0] = unshifted
-	theKeysyms[k][1] = shifted
+	theKeysyms.Table[k][0] = unshifted
+	theKeysyms.Table[k][1] = shifted
 }
 
 //export onKey
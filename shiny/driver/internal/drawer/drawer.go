@@ -32,3 +32,19 @@ func Scale(dst screen.Drawer, dr image.Rectangle, src screen.Texture, sr image.R
 		0, ry, float64(dr.Min.Y) - ry*float64(sr.Min.Y),
 	}, src, sr, op, opts)
 }
+
+// ScaleAspect implements the ScaleAspect method of the screen.Window
+// interface by calling the Scale method of the screen.Drawer interface.
+func ScaleAspect(dst screen.Drawer, dr image.Rectangle, src screen.Texture, sr image.Rectangle, fit screen.ScaleFit, op draw.Op) {
+	if sr.Empty() || dr.Empty() {
+		return
+	}
+	r := fit.Rect(dr, sr.Size())
+
+	var opts *screen.DrawOptions
+	if fit == screen.FitCover {
+		// r overflows dr, and the parts outside of dr are cropped.
+		opts = &screen.DrawOptions{Clip: dr}
+	}
+	dst.Scale(r, src, sr, op, opts)
+}
@@ -7,6 +7,9 @@ package errscreen // import "golang.org/x/exp/shiny/driver/internal/errscreen"
 
 import (
 	"image"
+	"image/color"
+	"io"
+	"time"
 
 	"golang.org/x/exp/shiny/screen"
 )
@@ -20,6 +23,35 @@ type stub struct {
 	err error
 }
 
-func (s stub) NewBuffer(size image.Point) (screen.Buffer, error)              { return nil, s.err }
+func (s stub) NewBuffer(size image.Point) (screen.Buffer, error) { return nil, s.err }
+func (s stub) NewPalettedBuffer(size image.Point, p color.Palette) (screen.PalettedBuffer, error) {
+	return nil, s.err
+}
 func (s stub) NewTexture(size image.Point) (screen.Texture, error)            { return nil, s.err }
+func (s stub) NewRenderTarget(size image.Point) (screen.RenderTarget, error)  { return nil, s.err }
+func (s stub) NewTextureFromReader(r io.Reader) (screen.Texture, error)       { return nil, s.err }
 func (s stub) NewWindow(opts *screen.NewWindowOptions) (screen.Window, error) { return nil, s.err }
+func (s stub) Displays() ([]screen.Display, error)                            { return nil, s.err }
+func (s stub) SampleColor(p image.Point) (color.Color, error)                 { return nil, s.err }
+func (s stub) PickColor() (color.Color, error)                                { return nil, s.err }
+func (s stub) SetSessionID(id string)                                         {}
+func (s stub) CreatePointerBarrier(x1, y1, x2, y2 int, directions screen.BarrierDirection) (screen.Barrier, error) {
+	return nil, s.err
+}
+func (s stub) IdleTime() (time.Duration, error) { return 0, s.err }
+func (s stub) SetIdleThreshold(d time.Duration) {}
+func (s stub) KeyRepeat() (delay, interval time.Duration, err error) {
+	return 0, 0, s.err
+}
+func (s stub) SetKeyRepeat(delay, interval time.Duration) error           { return s.err }
+func (s stub) RestoreKeyRepeat() error                                    { return s.err }
+func (s stub) SetCursorSize(px int)                                       {}
+func (s stub) KeyboardMapping() [][]uint32                                { return nil }
+func (s stub) ModifierMapping() [8][]uint8                                { return [8][]uint8{} }
+func (s stub) NewAlarm(interval time.Duration) (<-chan time.Time, func()) { return nil, func() {} }
+func (s stub) PointerMapping() []byte                                     { return nil }
+func (s stub) HasCompositor() bool                                        { return false }
+func (s stub) ClipboardTargets(selection string) ([]string, error)        { return nil, s.err }
+func (s stub) Errors() <-chan error                                       { return nil }
+func (s stub) SetRawEventHandler(handler func(ev interface{}) bool)       {}
+func (s stub) Bell(percent int) error                                     { return s.err }
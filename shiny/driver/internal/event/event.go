@@ -6,7 +6,12 @@
 package event // import "golang.org/x/exp/shiny/driver/internal/event"
 
 import (
+	"reflect"
 	"sync"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/mouse"
+	"golang.org/x/mobile/event/paint"
 )
 
 // Deque is an infinitely buffered double-ended queue of events. The zero value
@@ -14,15 +19,97 @@ import (
 type Deque struct {
 	mu    sync.Mutex
 	cond  sync.Cond     // cond.L is lazily initialized to &Deque.mu.
+	space sync.Cond     // space.L is lazily initialized to &Deque.mu.
 	back  []interface{} // FIFO.
 	front []interface{} // LIFO.
+
+	// max and policy are the limit set by SetLimit.
+	max    int
+	policy screen.OverflowPolicy
+
+	// routes maps the event types passed to EventsByType to the routes that
+	// feed the returned channels.
+	routes map[reflect.Type]*route
+}
+
+// route is a channel returned by EventsByType, and the deque that feeds it.
+type route struct {
+	q *Deque
+	// done is closed when no event types are routed to q any more.
+	done chan struct{}
 }
 
 func (q *Deque) lockAndInit() {
 	q.mu.Lock()
 	if q.cond.L == nil {
 		q.cond.L = &q.mu
+		q.space.L = &q.mu
+	}
+}
+
+// SetLimit sets the number of events that the deque holds before policy
+// applies to events sent via Send or SendUnique. A non-positive max means no
+// limit, which is the default.
+func (q *Deque) SetLimit(max int, policy screen.OverflowPolicy) {
+	q.lockAndInit()
+	defer q.mu.Unlock()
+
+	q.max, q.policy = max, policy
+	q.space.Broadcast()
+}
+
+// full returns whether the deque is at its limit. It must only be called
+// while holding q.mu.
+func (q *Deque) full() bool {
+	return q.max > 0 && len(q.front)+len(q.back) >= q.max
+}
+
+// droppable returns whether e may be dropped when the deque is full.
+func droppable(e interface{}) bool {
+	e2, ok := e.(mouse.Event)
+	return ok && e2.Button == mouse.ButtonNone && e2.Direction == mouse.DirNone
+}
+
+// hasPaint returns whether the deque holds a paint.Event. It must only be
+// called while holding q.mu.
+func (q *Deque) hasPaint() bool {
+	for _, e := range q.back {
+		if _, ok := e.(paint.Event); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pushBack adds event to the end of the deque, applying the limit set by
+// SetLimit. If block is false, an OverflowBlock limit is applied as for
+// OverflowDropMotion instead. It must only be called while holding q.mu.
+func (q *Deque) pushBack(event interface{}, block bool) {
+	if _, ok := event.(paint.Event); ok && q.full() && q.hasPaint() {
+		// The queued paint.Event will repaint the window, so this one is
+		// not needed. A queued paint.Event is never dropped, as the window
+		// might otherwise never be repainted.
+		return
+	}
+	if q.policy == screen.OverflowBlock && block {
+		for q.full() {
+			q.space.Wait()
+		}
+	} else if q.full() {
+		i := 0
+		for i < len(q.back) && !droppable(q.back[i]) {
+			i++
+		}
+		if i < len(q.back) {
+			copy(q.back[i:], q.back[i+1:])
+			q.back[len(q.back)-1] = nil
+			q.back = q.back[:len(q.back)-1]
+		} else if droppable(event) {
+			return
+		}
 	}
+	q.back = append(q.back, event)
+	q.cond.Signal()
 }
 
 // NextEvent implements the screen.EventDeque interface.
@@ -31,38 +118,226 @@ func (q *Deque) NextEvent() interface{} {
 	defer q.mu.Unlock()
 
 	for {
-		if n := len(q.front); n > 0 {
-			e := q.front[n-1]
-			q.front[n-1] = nil
-			q.front = q.front[:n-1]
+		if e, ok := q.pop(); ok {
+			if d, ok := e.(*doEvent); ok {
+				q.do(d.f)
+				continue
+			}
 			return e
 		}
+		q.cond.Wait()
+	}
+}
 
-		if n := len(q.back); n > 0 {
-			e := q.back[0]
-			q.back[0] = nil
-			q.back = q.back[1:]
-			return e
+// TryNextEvent implements the screen.EventDeque interface.
+func (q *Deque) TryNextEvent() (interface{}, bool) {
+	q.lockAndInit()
+	defer q.mu.Unlock()
+
+	for {
+		e, ok := q.pop()
+		if d, isDo := e.(*doEvent); ok && isDo {
+			q.do(d.f)
+			continue
 		}
+		return e, ok
+	}
+}
 
-		q.cond.Wait()
+// doEvent is the event sent by Do. It is a pointer type so that SendUnique
+// never compares two func values.
+type doEvent struct {
+	f func()
+}
+
+// Do implements the screen.EventDeque interface.
+func (q *Deque) Do(f func()) {
+	q.Send(&doEvent{f})
+}
+
+// do calls f without holding q.mu. It must only be called while holding
+// q.mu.
+func (q *Deque) do(f func()) {
+	q.mu.Unlock()
+	defer q.mu.Lock()
+	f()
+}
+
+// pop removes and returns the next event, if there is one. It must only be
+// called while holding q.mu.
+func (q *Deque) pop() (interface{}, bool) {
+	if n := len(q.front); n > 0 {
+		e := q.front[n-1]
+		q.front[n-1] = nil
+		q.front = q.front[:n-1]
+		q.space.Signal()
+		return e, true
+	}
+
+	if n := len(q.back); n > 0 {
+		e := q.back[0]
+		q.back[0] = nil
+		q.back = q.back[1:]
+		q.space.Signal()
+		return e, true
 	}
+
+	return nil, false
+}
+
+// EventsByType returns a channel that receives the events, subsequently sent
+// to q, whose type is one of kinds, instead of q's NextEvent method. Each
+// channel is fed by its own deque, with the same limit as q, so that a slow
+// receiver on one channel does not hold up the others.
+//
+// A later call for the same type replaces the earlier one for that type. A
+// channel that no longer receives any type is closed, and the events queued
+// for it but not yet received are dropped.
+func (q *Deque) EventsByType(kinds ...reflect.Type) <-chan interface{} {
+	r := &route{
+		q:    &Deque{},
+		done: make(chan struct{}),
+	}
+	c := make(chan interface{})
+
+	q.lockAndInit()
+	r.q.max, r.q.policy = q.max, q.policy
+	if q.routes == nil {
+		q.routes = map[reflect.Type]*route{}
+	}
+	var replaced []*route
+	for _, k := range kinds {
+		if old := q.routes[k]; old != nil && old != r {
+			replaced = append(replaced, old)
+		}
+		q.routes[k] = r
+	}
+	for _, old := range replaced {
+		q.endRoute(old)
+	}
+	q.mu.Unlock()
+
+	go func() {
+		defer close(c)
+		for {
+			e := r.q.NextEvent()
+			select {
+			case <-r.done:
+				return
+			default:
+			}
+			select {
+			case c <- e:
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// endOfRoute wakes up the goroutine that feeds a route's channel, so that it
+// notices that the route has ended.
+type endOfRoute struct{}
+
+// endRoute ends r, if no event types are routed to it any more. It must only
+// be called while holding q.mu.
+func (q *Deque) endRoute(r *route) {
+	for _, other := range q.routes {
+		if other == r {
+			return
+		}
+	}
+	select {
+	case <-r.done:
+		// r has already ended.
+	default:
+		close(r.done)
+		r.q.SendFirst(endOfRoute{})
+	}
+}
+
+// route returns the deque that event should be sent to instead of q, if any.
+func (q *Deque) route(event interface{}) *Deque {
+	q.lockAndInit()
+	defer q.mu.Unlock()
+
+	if len(q.routes) == 0 {
+		return nil
+	}
+	if r := q.routes[reflect.TypeOf(event)]; r != nil {
+		return r.q
+	}
+	return nil
 }
 
 // Send implements the screen.EventDeque interface.
 func (q *Deque) Send(event interface{}) {
+	if sub := q.route(event); sub != nil {
+		sub.Send(event)
+		return
+	}
 	q.lockAndInit()
 	defer q.mu.Unlock()
 
-	q.back = append(q.back, event)
-	q.cond.Signal()
+	q.pushBack(event, true)
+}
+
+// Post is like Send, except that it never blocks. A driver uses it for the
+// events that it sends from its own event loop, which must keep running for
+// the program's NextEvent calls and uploads to make progress.
+func (q *Deque) Post(event interface{}) {
+	if sub := q.route(event); sub != nil {
+		sub.Post(event)
+		return
+	}
+	q.lockAndInit()
+	defer q.mu.Unlock()
+
+	q.pushBack(event, false)
 }
 
 // SendFirst implements the screen.EventDeque interface.
 func (q *Deque) SendFirst(event interface{}) {
+	if sub := q.route(event); sub != nil {
+		sub.SendFirst(event)
+		return
+	}
 	q.lockAndInit()
 	defer q.mu.Unlock()
 
 	q.front = append(q.front, event)
 	q.cond.Signal()
 }
+
+// SendUnique is like Send, except that it does nothing if an equal event is
+// already in the deque. The event must be comparable.
+func (q *Deque) SendUnique(event interface{}) {
+	q.sendUnique(event, true)
+}
+
+// PostUnique is like SendUnique, except that it never blocks, as for Post.
+func (q *Deque) PostUnique(event interface{}) {
+	q.sendUnique(event, false)
+}
+
+func (q *Deque) sendUnique(event interface{}, block bool) {
+	if sub := q.route(event); sub != nil {
+		sub.sendUnique(event, block)
+		return
+	}
+	q.lockAndInit()
+	defer q.mu.Unlock()
+
+	for _, e := range q.front {
+		if e == event {
+			return
+		}
+	}
+	for _, e := range q.back {
+		if e == event {
+			return
+		}
+	}
+	q.pushBack(event, block)
+}
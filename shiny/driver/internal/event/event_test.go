@@ -0,0 +1,188 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/mouse"
+	"golang.org/x/mobile/event/paint"
+)
+
+func TestDo(t *testing.T) {
+	q := &Deque{}
+	var got []interface{}
+	q.Send(1)
+	q.Do(func() { got = append(got, "do") })
+	q.SendUnique(2)
+	q.Do(func() { got = append(got, "do") })
+
+	got = append(got, q.NextEvent())
+	got = append(got, q.NextEvent())
+	if e, ok := q.TryNextEvent(); ok {
+		t.Fatalf("TryNextEvent: got %v, want no event", e)
+	}
+
+	want := []interface{}{1, "do", 2, "do"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLimitDropMotion(t *testing.T) {
+	motion := func(x float32) mouse.Event { return mouse.Event{X: x} }
+	press := mouse.Event{Button: mouse.ButtonLeft, Direction: mouse.DirPress}
+	k := key.Event{Rune: 'a', Direction: key.DirPress}
+
+	q := &Deque{}
+	q.SetLimit(3, screen.OverflowDropMotion)
+	q.Send(motion(1))
+	q.Send(press)
+	q.Send(motion(2))
+	q.Send(k)         // Drops motion(1).
+	q.Send(motion(3)) // Drops motion(2).
+	q.Send(k)         // Drops motion(3).
+	q.Send(motion(4)) // Dropped, as there is nothing else to drop.
+	q.Send(k)         // Exceeds the limit.
+
+	want := []interface{}{press, k, k, k}
+	var got []interface{}
+	for {
+		e, ok := q.TryNextEvent()
+		if !ok {
+			break
+		}
+		got = append(got, e)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLimitPaint(t *testing.T) {
+	for _, policy := range []screen.OverflowPolicy{screen.OverflowDropMotion, screen.OverflowBlock} {
+		q := &Deque{}
+		q.SetLimit(2, policy)
+		q.Send(paint.Event{})
+		q.Send(mouse.Event{X: 1})
+
+		// The queued paint.Event is kept, and the new one is not needed, so
+		// sending it neither drops anything nor blocks.
+		done := make(chan struct{})
+		go func() {
+			q.Send(paint.Event{})
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("policy %d: Send blocked", policy)
+		}
+
+		want := []interface{}{paint.Event{}, mouse.Event{X: 1}}
+		var got []interface{}
+		for {
+			e, ok := q.TryNextEvent()
+			if !ok {
+				break
+			}
+			got = append(got, e)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("policy %d: got %v, want %v", policy, got, want)
+		}
+	}
+}
+
+func TestPostNeverBlocks(t *testing.T) {
+	motion := mouse.Event{X: 1}
+	press := mouse.Event{Button: mouse.ButtonLeft, Direction: mouse.DirPress}
+	k := key.Event{Rune: 'a', Direction: key.DirPress}
+
+	q := &Deque{}
+	q.SetLimit(1, screen.OverflowBlock)
+	q.Send(k)
+
+	// The queue is full, so Post applies OverflowDropMotion instead of
+	// blocking.
+	done := make(chan struct{})
+	go func() {
+		q.Post(press)
+		q.Post(motion) // Dropped, as there is nothing else to drop.
+		q.PostUnique(press)
+		q.PostUnique(k)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Post blocked")
+	}
+
+	want := []interface{}{k, press}
+	var got []interface{}
+	for {
+		e, ok := q.TryNextEvent()
+		if !ok {
+			break
+		}
+		got = append(got, e)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEventsByType(t *testing.T) {
+	q := &Deque{}
+	keys := q.EventsByType(reflect.TypeOf(key.Event{}))
+	k := key.Event{Rune: 'a', Direction: key.DirPress}
+	m := mouse.Event{X: 1}
+	q.Send(k)
+	q.Send(m)
+	q.Send(k)
+
+	if got := q.NextEvent(); got != m {
+		t.Errorf("NextEvent: got %v, want %v", got, m)
+	}
+	for i := 0; i < 2; i++ {
+		if got := <-keys; got != k {
+			t.Errorf("receive #%d: got %v, want %v", i, got, k)
+		}
+	}
+}
+
+func TestEventsByTypeReplace(t *testing.T) {
+	q := &Deque{}
+	keyType := reflect.TypeOf(key.Event{})
+	old := q.EventsByType(keyType, reflect.TypeOf(mouse.Event{}))
+	keys := q.EventsByType(keyType)
+	k := key.Event{Rune: 'a', Direction: key.DirPress}
+	m := mouse.Event{X: 1}
+	q.Send(k)
+	q.Send(m)
+
+	if got := <-keys; got != k {
+		t.Errorf("new channel: got %v, want %v", got, k)
+	}
+	if got := <-old; got != m {
+		t.Errorf("old channel: got %v, want %v", got, m)
+	}
+
+	// Replacing the last type routed to old closes it.
+	q.EventsByType(reflect.TypeOf(mouse.Event{}))
+	select {
+	case e, ok := <-old:
+		if ok {
+			t.Errorf("old channel: got %v, want it closed", e)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("old channel was not closed")
+	}
+}
@@ -26,14 +26,21 @@ const (
 	Button5Mask = 1 << 12
 )
 
-type KeysymTable [256][2]uint32
+type KeysymTable struct {
+	// Table holds the unshifted and shifted keysyms of each keycode.
+	Table [256][2]uint32
+
+	// Modifiers holds the key.Code values of the keycodes bound to the
+	// Shift, Control, Mod1 and Mod4 modifiers, as set by SetModifiers.
+	Modifiers [256]key.Code
+}
 
 func (t *KeysymTable) Lookup(detail uint8, state uint16) (rune, key.Code) {
 	// The key event's rune depends on whether the shift key is down.
-	unshifted := rune(t[detail][0])
+	unshifted := rune(t.Table[detail][0])
 	r := unshifted
 	if state&ShiftMask != 0 {
-		r = rune(t[detail][1])
+		r = rune(t.Table[detail][1])
 		// In X11, a zero keysym when shift is down means to use what the
 		// keysym is when shift is up.
 		if r == 0 {
@@ -50,11 +57,57 @@ func (t *KeysymTable) Lookup(detail uint8, state uint16) (rune, key.Code) {
 	} else {
 		r, c = -1, nonUnicodeKeycodes[unshifted]
 	}
+	if r == -1 && c == key.CodeUnknown {
+		// Some keyboard layouts map modifier keys to keysyms, such as
+		// Hyper_L, that have no key.Code, so fall back to the modifier that
+		// the key is bound to.
+		c = t.Modifiers[detail]
+	}
 
 	// TODO: Unicode-but-not-ASCII keysyms like the Swiss keyboard's 'ö'.
 	return r, c
 }
 
+// SetModifiers sets t.Modifiers from the X11 modifier mapping, where
+// modifiers[i] holds the keycodes bound to modifier i, from Shift to Mod5.
+// A key is the right-hand key of its modifier if one of its keysyms is a
+// right-hand modifier keysym, such as Control_R, and the left-hand key
+// otherwise.
+func (t *KeysymTable) SetModifiers(modifiers [8][]uint8) {
+	t.Modifiers = [256]key.Code{}
+	for mod, keycodes := range modifiers {
+		codes := modifierCodes[mod]
+		if codes[0] == key.CodeUnknown {
+			continue
+		}
+		for _, kc := range keycodes {
+			if isRightModifier(t.Table[kc][0]) || isRightModifier(t.Table[kc][1]) {
+				t.Modifiers[kc] = codes[1]
+			} else {
+				t.Modifiers[kc] = codes[0]
+			}
+		}
+	}
+}
+
+// modifierCodes maps the X11 modifiers to the key.Code values of their left-
+// and right-hand keys.
+var modifierCodes = [8][2]key.Code{
+	0: {key.CodeLeftShift, key.CodeRightShift},     // Shift.
+	2: {key.CodeLeftControl, key.CodeRightControl}, // Control.
+	3: {key.CodeLeftAlt, key.CodeRightAlt},         // Mod1.
+	6: {key.CodeLeftGUI, key.CodeRightGUI},         // Mod4.
+}
+
+// isRightModifier returns whether keysym is a right-hand modifier keysym.
+func isRightModifier(keysym uint32) bool {
+	switch keysym {
+	case xkShiftR, xkControlR, xkMetaR, xkAltR, xkSuperR, xkHyperR:
+		return true
+	}
+	return false
+}
+
 func KeyModifiers(state uint16) (m key.Modifiers) {
 	if state&ShiftMask != 0 {
 		m |= key.ModShift
@@ -73,43 +126,48 @@ func KeyModifiers(state uint16) (m key.Modifiers) {
 
 // These constants come from /usr/include/X11/{keysymdef,XF86keysym}.h
 const (
-	xkISOLeftTab = 0xfe20
-	xkBackSpace  = 0xff08
-	xkTab        = 0xff09
-	xkReturn     = 0xff0d
-	xkEscape     = 0xff1b
-	xkMultiKey   = 0xff20
-	xkHome       = 0xff50
-	xkLeft       = 0xff51
-	xkUp         = 0xff52
-	xkRight      = 0xff53
-	xkDown       = 0xff54
-	xkPageUp     = 0xff55
-	xkPageDown   = 0xff56
-	xkEnd        = 0xff57
-	xkInsert     = 0xff63
-	xkMenu       = 0xff67
-	xkF1         = 0xffbe
-	xkF2         = 0xffbf
-	xkF3         = 0xffc0
-	xkF4         = 0xffc1
-	xkF5         = 0xffc2
-	xkF6         = 0xffc3
-	xkF7         = 0xffc4
-	xkF8         = 0xffc5
-	xkF9         = 0xffc6
-	xkF10        = 0xffc7
-	xkF11        = 0xffc8
-	xkF12        = 0xffc9
-	xkShiftL     = 0xffe1
-	xkShiftR     = 0xffe2
-	xkControlL   = 0xffe3
-	xkControlR   = 0xffe4
-	xkAltL       = 0xffe9
-	xkAltR       = 0xffea
-	xkSuperL     = 0xffeb
-	xkSuperR     = 0xffec
-	xkDelete     = 0xffff
+	xkISOLevel3Shift = 0xfe03
+	xkISOLeftTab     = 0xfe20
+	xkBackSpace      = 0xff08
+	xkTab            = 0xff09
+	xkReturn         = 0xff0d
+	xkEscape         = 0xff1b
+	xkMultiKey       = 0xff20
+	xkHome           = 0xff50
+	xkLeft           = 0xff51
+	xkUp             = 0xff52
+	xkRight          = 0xff53
+	xkDown           = 0xff54
+	xkPageUp         = 0xff55
+	xkPageDown       = 0xff56
+	xkEnd            = 0xff57
+	xkInsert         = 0xff63
+	xkMenu           = 0xff67
+	xkF1             = 0xffbe
+	xkF2             = 0xffbf
+	xkF3             = 0xffc0
+	xkF4             = 0xffc1
+	xkF5             = 0xffc2
+	xkF6             = 0xffc3
+	xkF7             = 0xffc4
+	xkF8             = 0xffc5
+	xkF9             = 0xffc6
+	xkF10            = 0xffc7
+	xkF11            = 0xffc8
+	xkF12            = 0xffc9
+	xkShiftL         = 0xffe1
+	xkShiftR         = 0xffe2
+	xkControlL       = 0xffe3
+	xkControlR       = 0xffe4
+	xkMetaL          = 0xffe7
+	xkMetaR          = 0xffe8
+	xkAltL           = 0xffe9
+	xkAltR           = 0xffea
+	xkSuperL         = 0xffeb
+	xkSuperR         = 0xffec
+	xkHyperL         = 0xffed
+	xkHyperR         = 0xffee
+	xkDelete         = 0xffff
 
 	xf86xkAudioLowerVolume = 0x1008ff11
 	xf86xkAudioMute        = 0x1008ff12
@@ -156,8 +214,13 @@ var nonUnicodeKeycodes = map[rune]key.Code{
 	xkControlR: key.CodeRightControl,
 	xkAltL:     key.CodeLeftAlt,
 	xkAltR:     key.CodeRightAlt,
-	xkSuperL:   key.CodeLeftGUI,
-	xkSuperR:   key.CodeRightGUI,
+	xkMetaL:    key.CodeLeftGUI,
+	xkMetaR:    key.CodeRightGUI,
+
+	// AltGr, the right Alt key on many non-US layouts.
+	xkISOLevel3Shift: key.CodeRightAlt,
+	xkSuperL:         key.CodeLeftGUI,
+	xkSuperR:         key.CodeRightGUI,
 
 	xkDelete: key.CodeDeleteForward,
 
@@ -0,0 +1,62 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11key
+
+import (
+	"testing"
+
+	"golang.org/x/mobile/event/key"
+)
+
+func TestLookupModifiers(t *testing.T) {
+	var table KeysymTable
+	table.Table[37] = [2]uint32{xkControlL, 0}
+	table.Table[105] = [2]uint32{xkControlR, 0}
+	table.Table[50] = [2]uint32{xkShiftL, 0}
+	table.Table[62] = [2]uint32{xkShiftR, 0}
+	table.Table[64] = [2]uint32{xkAltL, xkMetaL}
+	table.Table[108] = [2]uint32{xkISOLevel3Shift, 0}
+	// Hyper_L and Hyper_R have no key.Code, so the side that they name and
+	// the Mod4 modifier that they are bound to give their codes.
+	table.Table[133] = [2]uint32{xkHyperL, 0}
+	table.Table[134] = [2]uint32{xkHyperR, 0}
+	// Keycode 135 is Hyper_L but bound to no modifier.
+	table.Table[135] = [2]uint32{xkHyperL, 0}
+	// Keycode 38 is 'a', which keeps its rune and code even if bound to a
+	// modifier.
+	table.Table[38] = [2]uint32{'a', 'A'}
+
+	var modifiers [8][]uint8
+	modifiers[0] = []uint8{50, 62}
+	modifiers[2] = []uint8{37, 105}
+	modifiers[3] = []uint8{64, 38}
+	modifiers[6] = []uint8{133, 134}
+	table.SetModifiers(modifiers)
+
+	testCases := []struct {
+		detail   uint8
+		state    uint16
+		wantRune rune
+		wantCode key.Code
+	}{
+		{37, 0, -1, key.CodeLeftControl},
+		{105, 0, -1, key.CodeRightControl},
+		{105, ControlMask, -1, key.CodeRightControl},
+		{50, 0, -1, key.CodeLeftShift},
+		{62, ShiftMask, -1, key.CodeRightShift},
+		{64, ShiftMask, -1, key.CodeLeftAlt},
+		{108, 0, -1, key.CodeRightAlt},
+		{133, 0, -1, key.CodeLeftGUI},
+		{134, 0, -1, key.CodeRightGUI},
+		{135, 0, -1, key.CodeUnknown},
+		{38, 0, 'a', key.CodeA},
+	}
+	for _, tc := range testCases {
+		r, c := table.Lookup(tc.detail, tc.state)
+		if r != tc.wantRune || c != tc.wantCode {
+			t.Errorf("keycode %d, state %#x: got %v, %v, want %v, %v", tc.detail, tc.state, r, c, tc.wantRune, tc.wantCode)
+		}
+	}
+}
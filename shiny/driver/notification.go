@@ -0,0 +1,128 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/paint"
+)
+
+// notificationMargin is the distance, in pixels, between notifications and
+// between a notification and the edges of the display.
+const notificationMargin = 16
+
+var notifications struct {
+	mu sync.Mutex
+	// active are the bounds of the notifications being shown.
+	active []image.Rectangle
+}
+
+// notificationTimeout is sent to a notification window when it should be
+// dismissed.
+type notificationTimeout struct{}
+
+// ShowNotification shows img in a small, undecorated window in the top-right
+// corner of the primary display, above other windows, for the duration d.
+// Multiple notifications are stacked below each other without overlapping.
+//
+// It returns once the window is shown, without waiting for d to elapse.
+func ShowNotification(s screen.Screen, img image.Image, d time.Duration) error {
+	size := img.Bounds().Size()
+	display := image.Rectangle{}
+	displays, err := s.Displays()
+	if err != nil {
+		return err
+	}
+	for _, dp := range displays {
+		if dp.Primary() {
+			display = dp.Bounds()
+			break
+		}
+	}
+
+	r := allocNotification(display, size)
+	b, err := s.NewBuffer(size)
+	if err != nil {
+		freeNotification(r)
+		return err
+	}
+	draw.Draw(b.RGBA(), b.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	w, err := s.NewWindow(&screen.NewWindowOptions{
+		Width:   size.X,
+		Height:  size.Y,
+		Overlay: true,
+		X:       r.Min.X,
+		Y:       r.Min.Y,
+	})
+	if err != nil {
+		b.Release()
+		freeNotification(r)
+		return err
+	}
+
+	go func() {
+		defer freeNotification(r)
+		defer b.Release()
+		defer w.Release()
+
+		time.AfterFunc(d, func() {
+			w.Send(notificationTimeout{})
+		})
+		for {
+			switch w.NextEvent().(type) {
+			case notificationTimeout:
+				return
+			case paint.Event:
+				w.Upload(image.Point{}, b, b.Bounds())
+				w.Publish()
+			}
+		}
+	}()
+	return nil
+}
+
+// allocNotification returns where to show a notification of the given size
+// on display, below any notifications already being shown.
+func allocNotification(display image.Rectangle, size image.Point) image.Rectangle {
+	notifications.mu.Lock()
+	defer notifications.mu.Unlock()
+
+	r := image.Rectangle{
+		Min: image.Point{
+			X: display.Max.X - notificationMargin - size.X,
+			Y: display.Min.Y + notificationMargin,
+		},
+	}
+	r.Max = r.Min.Add(size)
+	for moved := true; moved; {
+		moved = false
+		for _, a := range notifications.active {
+			if a.Overlaps(r.Inset(-notificationMargin / 2)) {
+				r = r.Add(image.Point{Y: a.Max.Y + notificationMargin - r.Min.Y})
+				moved = true
+			}
+		}
+	}
+	notifications.active = append(notifications.active, r)
+	return r
+}
+
+func freeNotification(r image.Rectangle) {
+	notifications.mu.Lock()
+	defer notifications.mu.Unlock()
+
+	for i, a := range notifications.active {
+		if a == r {
+			notifications.active = append(notifications.active[:i], notifications.active[i+1:]...)
+			return
+		}
+	}
+}
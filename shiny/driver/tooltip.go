@@ -0,0 +1,71 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver
+
+import (
+	"image"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// NewTooltipWindow returns a new undecorated window of the given size, with
+// its top-left corner at the screen position at, such as just below the
+// mouse cursor. Like other overlay windows, it is not managed by the window
+// manager, stays above normal windows and does not take the keyboard focus,
+// but unlike them it receives mouse events.
+//
+// If the window would extend past an edge of the display that contains at,
+// it is moved to fit within that display.
+func NewTooltipWindow(s screen.Screen, at image.Point, size image.Point) (screen.Window, error) {
+	displays, err := s.Displays()
+	if err != nil {
+		return nil, err
+	}
+	display := image.Rectangle{}
+	for _, d := range displays {
+		if at.In(d.Bounds()) {
+			display = d.Bounds()
+			break
+		}
+		if d.Primary() {
+			display = d.Bounds()
+		}
+	}
+	r := clampTooltip(image.Rectangle{Min: at, Max: at.Add(size)}, display)
+
+	return s.NewWindow(&screen.NewWindowOptions{
+		Width:   size.X,
+		Height:  size.Y,
+		Overlay: true,
+		Tooltip: true,
+		NoFocus: true,
+		X:       r.Min.X,
+		Y:       r.Min.Y,
+	})
+}
+
+// clampTooltip moves r to fit within display. If r is larger than display,
+// its top-left corner is kept within display.
+func clampTooltip(r, display image.Rectangle) image.Rectangle {
+	if display.Empty() {
+		return r
+	}
+	var d image.Point
+	if r.Max.X > display.Max.X {
+		d.X = display.Max.X - r.Max.X
+	}
+	if r.Max.Y > display.Max.Y {
+		d.Y = display.Max.Y - r.Max.Y
+	}
+	r = r.Add(d)
+	d = image.Point{}
+	if r.Min.X < display.Min.X {
+		d.X = display.Min.X - r.Min.X
+	}
+	if r.Min.Y < display.Min.Y {
+		d.Y = display.Min.Y - r.Min.Y
+	}
+	return r.Add(d)
+}
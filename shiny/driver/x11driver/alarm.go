@@ -0,0 +1,210 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/xgb"
+)
+
+// Alarms use the X SYNC extension's SERVERTIME system counter, which counts
+// the X11 server's milliseconds. The github.com/BurntSushi/xgb package does
+// not support the SYNC extension, so its requests and its AlarmNotify event
+// are built and decoded by hand.
+const (
+	syncInitialize         = 0
+	syncListSystemCounters = 1
+	syncCreateAlarm        = 8
+	syncDestroyAlarm       = 11
+
+	// syncAlarmNotify is the AlarmNotify event number, relative to the
+	// extension's first event.
+	syncAlarmNotify = 1
+	// syncNumErrors is the number of errors that the SYNC extension
+	// defines: Counter and Alarm.
+	syncNumErrors = 2
+
+	// CreateAlarm value mask bits.
+	syncCACounter   = 1 << 0
+	syncCAValueType = 1 << 1
+	syncCAValue     = 1 << 2
+	syncCATestType  = 1 << 3
+	syncCADelta     = 1 << 4
+	syncCAEvents    = 1 << 5
+
+	syncValueTypeRelative          = 1
+	syncTestTypePositiveComparison = 2
+)
+
+// syncAlarmNotifyEvent is a SYNC AlarmNotify event.
+type syncAlarmNotifyEvent struct {
+	buf   []byte
+	alarm uint32
+}
+
+func newSyncAlarmNotifyEvent(buf []byte) xgb.Event {
+	return syncAlarmNotifyEvent{
+		buf:   buf,
+		alarm: xgb.Get32(buf[4:]),
+	}
+}
+
+func (ev syncAlarmNotifyEvent) Bytes() []byte { return ev.buf }
+func (ev syncAlarmNotifyEvent) String() string {
+	return fmt.Sprintf("AlarmNotify {Alarm: %d}", ev.alarm)
+}
+
+// initSync finds and initializes the SYNC extension and its SERVERTIME
+// counter, if the X11 server supports them.
+func (s *screenImpl) initSync() {
+	e, ok, err := s.queryExtension("SYNC", syncNumErrors)
+	if err != nil || !ok {
+		return
+	}
+	// The SYNC protocol requires that Initialize is the first request.
+	buf := e.newRequest(syncInitialize, 8)
+	buf[4] = 3 // desired_major_version.
+	buf[5] = 1 // desired_minor_version.
+	if _, err := e.send(s.xc, buf, true, true).Reply(); err != nil {
+		return
+	}
+
+	r, err := e.send(s.xc, e.newRequest(syncListSystemCounters, 4), true, true).Reply()
+	if err != nil || len(r) < 32 {
+		return
+	}
+	n := int(xgb.Get32(r[8:]))
+	for i, b := 0, r[32:]; i < n && len(b) >= 14; i++ {
+		// Each counter is its ID, its 8 byte resolution, and its name,
+		// padded to a multiple of 4 bytes.
+		nameLen := int(xgb.Get16(b[12:]))
+		size := (14 + nameLen + 3) &^ 3
+		if len(b) < 14+nameLen {
+			break
+		}
+		if string(b[14:14+nameLen]) == "SERVERTIME" {
+			s.syncExt = e
+			s.syncServerTime = xgb.Get32(b)
+			s.alarms = map[uint32]chan time.Time{}
+			xgb.NewEventFuncs[int(e.firstEvent)+syncAlarmNotify] = newSyncAlarmNotifyEvent
+			return
+		}
+		if len(b) < size {
+			break
+		}
+		b = b[size:]
+	}
+}
+
+func (s *screenImpl) NewAlarm(interval time.Duration) (<-chan time.Time, func()) {
+	c := make(chan time.Time, 1)
+	if s.syncServerTime != 0 {
+		stop, err := s.newSyncAlarm(c, interval)
+		if err == nil {
+			return c, stop
+		}
+		log.Print(err)
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case t := <-ticker.C:
+				sendTick(c, t)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+// sendTick sends t to c. Like a time.Ticker, it drops ticks for slow
+// receivers.
+func sendTick(c chan time.Time, t time.Time) {
+	select {
+	case c <- t:
+	default:
+	}
+}
+
+// newSyncAlarm creates a SYNC alarm that triggers every interval of the
+// SERVERTIME counter, sending to c.
+func (s *screenImpl) newSyncAlarm(c chan time.Time, interval time.Duration) (func(), error) {
+	// SERVERTIME counts milliseconds.
+	ms := interval / time.Millisecond
+	if ms < 1 {
+		ms = 1
+	} else if ms > 0x7fffffff {
+		ms = 0x7fffffff
+	}
+	alarm, err := s.xc.NewId()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xgb.NewId failed: %v", err)
+	}
+
+	s.mu.Lock()
+	s.alarms[alarm] = c
+	s.mu.Unlock()
+
+	// The alarm triggers when the counter reaches the current time plus one
+	// interval. Each time it does, its value is advanced by whole intervals
+	// until it is in the future again, so that missed ticks are dropped.
+	const mask = syncCACounter | syncCAValueType | syncCAValue | syncCATestType | syncCADelta | syncCAEvents
+	buf := s.syncExt.newRequest(syncCreateAlarm, 44)
+	xgb.Put32(buf[4:], alarm)
+	xgb.Put32(buf[8:], mask)
+	xgb.Put32(buf[12:], s.syncServerTime)
+	xgb.Put32(buf[16:], syncValueTypeRelative)
+	xgb.Put32(buf[20:], 0) // Value, high word.
+	xgb.Put32(buf[24:], uint32(ms))
+	xgb.Put32(buf[28:], syncTestTypePositiveComparison)
+	xgb.Put32(buf[32:], 0) // Delta, high word.
+	xgb.Put32(buf[36:], uint32(ms))
+	xgb.Put32(buf[40:], 1) // Events.
+	if err := s.syncExt.send(s.xc, buf, true, false).Check(); err != nil {
+		s.mu.Lock()
+		delete(s.alarms, alarm)
+		s.mu.Unlock()
+		return nil, fmt.Errorf("x11driver: sync.CreateAlarm failed: %v", err)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.alarms, alarm)
+			s.mu.Unlock()
+
+			buf := s.syncExt.newRequest(syncDestroyAlarm, 8)
+			xgb.Put32(buf[4:], alarm)
+			s.syncExt.send(s.xc, buf, false, false)
+		})
+	}, nil
+}
+
+// handleAlarmNotify handles a SYNC alarm triggering.
+func (s *screenImpl) handleAlarmNotify(ev syncAlarmNotifyEvent) {
+	s.mu.Lock()
+	c := s.alarms[ev.alarm]
+	s.mu.Unlock()
+
+	if c != nil {
+		sendTick(c, time.Now())
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// backBuffer is an off-screen pixmap that a window draws to. Publish copies
+// it to the window.
+type backBuffer struct {
+	xm   xproto.Pixmap
+	xp   render.Picture
+	size image.Point
+}
+
+func (s *screenImpl) newBackBuffer(xw xproto.Window, depth byte, pictformat render.Pictformat, size image.Point) (*backBuffer, error) {
+	// X11 pixmaps cannot be empty.
+	if size.X < 1 {
+		size.X = 1
+	}
+	if size.Y < 1 {
+		size.Y = 1
+	}
+	xm, err := xproto.NewPixmapId(s.xc)
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.NewPixmapId failed: %v", err)
+	}
+	xp, err := render.NewPictureId(s.xc)
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: render.NewPictureId failed: %v", err)
+	}
+	xproto.CreatePixmap(s.xc, depth, xm, xproto.Drawable(xw), uint16(size.X), uint16(size.Y))
+	render.CreatePicture(s.xc, xp, xproto.Drawable(xm), pictformat, 0, nil)
+	return &backBuffer{
+		xm:   xm,
+		xp:   xp,
+		size: size,
+	}, nil
+}
+
+func (s *screenImpl) releaseBackBuffer(b *backBuffer) {
+	render.FreePicture(s.xc, b.xp)
+	xproto.FreePixmap(s.xc, b.xm)
+}
+
+// copyBackBuffer copies the top-left of src to dst, cropping it to the
+// smaller of the two sizes.
+func (w *windowImpl) copyBackBuffer(dst xproto.Drawable, dstSize image.Point, src *backBuffer) {
+	r := image.Rectangle{Max: src.size}.Intersect(image.Rectangle{Max: dstSize})
+	if r.Empty() {
+		return
+	}
+	xproto.CopyArea(w.s.xc, xproto.Drawable(src.xm), dst, w.frontG,
+		0, 0, 0, 0, uint16(r.Dx()), uint16(r.Dy()))
+}
+
+// target returns the back buffer that drawing operations should use,
+// reallocating it first if the window has been resized since the last call.
+//
+// During an interactive resize, the window manager can send many
+// ConfigureNotify events in quick succession. The new back buffer starts as a
+// cropped copy of the old one, so that apps that only repaint part of the
+// window still see the rest of their previous frame. Expose events keep
+// showing w.front until the app publishes a frame at the new size.
+func (w *windowImpl) target() *backBuffer {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.back.size == w.wantSize || w.released {
+		return w.back
+	}
+	b, err := w.s.newBackBuffer(w.xw, w.depth, w.pictformat, w.wantSize)
+	if err != nil {
+		log.Print(err)
+		return w.back
+	}
+	w.copyBackBuffer(xproto.Drawable(b.xm), b.size, w.back)
+	w.s.releaseBackBuffer(w.back)
+	w.back = b
+	w.wantSize = b.size
+	if n := len(w.clips); n > 0 {
+		w.setClip(w.clips[n-1], true)
+	}
+	return b
+}
+
+// publish copies the back buffer to w.front, reallocating it first if its
+// size differs, and then to the window. It is called while holding w.mu.
+//
+// The window never shows the back buffer itself, which the app may be half
+// way through drawing the next frame to when an expose event arrives.
+func (w *windowImpl) publish() {
+	b := w.back
+	if w.front == nil || w.front.size != b.size {
+		f, err := w.s.newBackBuffer(w.xw, w.depth, w.pictformat, b.size)
+		if err != nil {
+			log.Print(err)
+			w.copyBackBuffer(xproto.Drawable(w.xw), b.size, b)
+			return
+		}
+		if w.front != nil {
+			w.s.releaseBackBuffer(w.front)
+		}
+		w.front = f
+	}
+	w.copyBackBuffer(xproto.Drawable(w.front.xm), w.front.size, b)
+	w.copyBackBuffer(xproto.Drawable(w.xw), w.front.size, w.front)
+}
+
+// present copies the most recently published frame to the window, for
+// example after part of the window has been exposed.
+func (w *windowImpl) present() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.released || w.front == nil {
+		return
+	}
+	w.copyBackBuffer(xproto.Drawable(w.xw), image.Point{w.width, w.height}, w.front)
+}
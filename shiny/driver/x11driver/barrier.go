@@ -0,0 +1,97 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xfixes"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+type barrierImpl struct {
+	s  *screenImpl
+	xb xfixes.Barrier
+
+	releasedMu sync.Mutex
+	released   bool
+}
+
+func (b *barrierImpl) Release() {
+	b.releasedMu.Lock()
+	released := b.released
+	b.released = true
+	b.releasedMu.Unlock()
+
+	if released {
+		return
+	}
+	b.s.mu.Lock()
+	delete(b.s.barriers, b.xb)
+	b.s.mu.Unlock()
+	xfixes.DeletePointerBarrier(b.s.xc, b.xb)
+}
+
+func (s *screenImpl) CreatePointerBarrier(x1, y1, x2, y2 int, directions screen.BarrierDirection) (screen.Barrier, error) {
+	if !s.hasXFixes5 {
+		return nil, errors.New("x11driver: pointer barriers require XFixes 5.0")
+	}
+	if x1 != x2 && y1 != y2 {
+		return nil, fmt.Errorf("x11driver: pointer barrier (%d, %d)-(%d, %d) is neither horizontal nor vertical", x1, y1, x2, y2)
+	}
+	for _, v := range [...]int{x1, y1, x2, y2} {
+		if v < 0 || 0xffff < v {
+			return nil, fmt.Errorf("x11driver: pointer barrier (%d, %d)-(%d, %d) is out of range", x1, y1, x2, y2)
+		}
+	}
+
+	xb, err := xfixes.NewBarrierId(s.xc)
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xfixes.NewBarrierId failed: %v", err)
+	}
+	// The screen.BarrierDirection bits have the same values as the
+	// xfixes.BarrierDirectionsXxx constants.
+	err = xfixes.CreatePointerBarrierChecked(s.xc, xb, s.xsi.Root,
+		uint16(x1), uint16(y1), uint16(x2), uint16(y2), uint32(directions), 0, nil).Check()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xfixes.CreatePointerBarrier failed: %v", err)
+	}
+	b := &barrierImpl{
+		s:  s,
+		xb: xb,
+	}
+	s.mu.Lock()
+	if s.barriers == nil {
+		s.barriers = map[xfixes.Barrier]*barrierImpl{}
+	}
+	s.barriers[xb] = b
+	s.mu.Unlock()
+	return b, nil
+}
+
+// handleBarrierHit handles an XInput2 BarrierHit event, sending a
+// screen.BarrierHitEvent.
+func (s *screenImpl) handleBarrierHit(buf []byte) {
+	if len(buf) < 68 {
+		return
+	}
+	s.mu.Lock()
+	b := s.barriers[xfixes.Barrier(xgb.Get32(buf[28:]))]
+	s.mu.Unlock()
+	if b == nil {
+		return
+	}
+	s.sendAll(screen.BarrierHitEvent{
+		Barrier: b,
+		X:       fp1616(buf[44:]),
+		Y:       fp1616(buf[48:]),
+		DX:      fp3232(buf[52:]),
+		DY:      fp3232(buf[60:]),
+	})
+}
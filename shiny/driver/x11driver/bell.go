@@ -0,0 +1,21 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func (s *screenImpl) Bell(percent int) error {
+	if percent < -100 || 100 < percent {
+		return fmt.Errorf("x11driver: invalid bell volume %d", percent)
+	}
+	if err := xproto.BellChecked(s.xc, int8(percent)).Check(); err != nil {
+		return fmt.Errorf("x11driver: xproto.Bell failed: %v", err)
+	}
+	return nil
+}
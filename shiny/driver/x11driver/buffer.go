@@ -33,6 +33,15 @@ type bufferImpl struct {
 	nUpload   uint32
 	released  bool
 	cleanedUp bool
+
+	// convert, if non-nil, writes the pixels in a rectangle of buf, as BGRA,
+	// just before they are uploaded. It is called while holding mu.
+	convert func(sr image.Rectangle)
+
+	// trackDirty is whether MarkDirty or MarkAllDirty has been called, and
+	// dirty is the bounding box of the pixels changed since the last upload.
+	trackDirty bool
+	dirty      image.Rectangle
 }
 
 func (b *bufferImpl) degenerate() bool        { return b.size.X == 0 || b.size.Y == 0 }
@@ -40,7 +49,35 @@ func (b *bufferImpl) Size() image.Point       { return b.size }
 func (b *bufferImpl) Bounds() image.Rectangle { return image.Rectangle{Max: b.size} }
 func (b *bufferImpl) RGBA() *image.RGBA       { return &b.rgba }
 
-func (b *bufferImpl) preUpload() {
+func (b *bufferImpl) MarkDirty(r image.Rectangle) {
+	b.mu.Lock()
+	b.trackDirty = true
+	b.dirty = b.dirty.Union(r.Intersect(b.Bounds()))
+	b.mu.Unlock()
+}
+
+func (b *bufferImpl) MarkAllDirty() {
+	b.MarkDirty(b.Bounds())
+}
+
+// clipDirty restricts sr to the pixels changed since the last upload, if
+// dirty-region tracking is enabled, and clears the dirty region if sr covers
+// it.
+func (b *bufferImpl) clipDirty(sr image.Rectangle) image.Rectangle {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.trackDirty {
+		return sr
+	}
+	dirty := b.dirty
+	if dirty.In(sr) {
+		b.dirty = image.Rectangle{}
+	}
+	return sr.Intersect(dirty)
+}
+
+func (b *bufferImpl) preUpload(sr image.Rectangle) {
 	// Check that the program hasn't tried to modify the rgba field via the
 	// pointer returned by the bufferImpl.RGBA method. This check doesn't catch
 	// 100% of all cases; it simply tries to detect some invalid uses of a
@@ -60,6 +97,9 @@ func (b *bufferImpl) preUpload() {
 		swizzle.BGRA(b.buf)
 	}
 	b.nUpload++
+	if b.convert != nil {
+		b.convert(sr)
+	}
 }
 
 func (b *bufferImpl) postUpload() {
@@ -111,13 +151,28 @@ func (b *bufferImpl) cleanUp() {
 }
 
 func (b *bufferImpl) upload(xd xproto.Drawable, xg xproto.Gcontext, depth uint8, dp image.Point, sr image.Rectangle) {
+	if p := b.sendUpload(xd, xg, depth, dp, sr); p != nil {
+		p.wait(p.check())
+	}
+}
+
+// pendingUpload is an upload whose request has been sent to the X11 server.
+type pendingUpload struct {
+	b      *bufferImpl
+	cookie shm.PutImageCookie
+}
+
+// sendUpload sends the request to upload the sr part of b to dp in xd. It
+// returns nil if there is nothing to upload. Otherwise, the caller must call
+// the pendingUpload's check method, and then its wait method.
+func (b *bufferImpl) sendUpload(xd xproto.Drawable, xg xproto.Gcontext, depth uint8, dp image.Point, sr image.Rectangle) *pendingUpload {
 	originalSRMin := sr.Min
-	sr = sr.Intersect(b.Bounds())
+	sr = b.clipDirty(sr.Intersect(b.Bounds()))
 	if sr.Empty() {
-		return
+		return nil
 	}
 	dp = dp.Add(sr.Min.Sub(originalSRMin))
-	b.preUpload()
+	b.preUpload(sr)
 
 	b.s.mu.Lock()
 	b.s.nPendingUploads++
@@ -132,31 +187,50 @@ func (b *bufferImpl) upload(xd xproto.Drawable, xg xproto.Gcontext, depth uint8,
 		depth, xproto.ImageFormatZPixmap,
 		1, b.xs, 0, // 1 means send a completion event, 0 means a zero offset.
 	)
+	return &pendingUpload{b: b, cookie: cookie}
+}
 
-	err := cookie.Check()
+// check waits for the X11 server to accept the upload, returning a channel
+// that is closed when the upload completes, or nil if it failed. Checking
+// the first of several uploads sent in a row waits for a single round trip,
+// after which checking the others doesn't block.
+func (p *pendingUpload) check() chan struct{} {
+	b := p.b
+	err := p.cookie.Check()
 	if err != nil {
 		b.s.mu.Lock()
 		b.s.nPendingUploads--
 		b.s.handleCompletions()
 		b.s.mu.Unlock()
 		//fmt.Fprintf(os.Stderr, "Error drawing image size:%v sr:%v, dr:%v, depth:%v, %v\n", b.size, sr, dr, depth, err)
-		return
+		return nil
 	}
 
 	completion := make(chan struct{})
 
 	b.s.mu.Lock()
-	b.s.uploads[cookie.Sequence] = completion
+	b.s.uploads[p.cookie.Sequence] = completion
 	b.s.nPendingUploads--
 	b.s.handleCompletions()
 	b.s.mu.Unlock()
 
-	<-completion
+	return completion
+}
 
-	b.postUpload()
+// wait waits for the upload to complete, given the result of check.
+func (p *pendingUpload) wait(completion chan struct{}) {
+	if completion == nil {
+		return
+	}
+	<-completion
+	p.b.postUpload()
 }
 
 func fill(xc *xgb.Conn, xp render.Picture, dr image.Rectangle, src color.Color, op draw.Op) {
+	fillOp(xc, xp, dr, src, renderOp(op))
+}
+
+func fillOp(xc *xgb.Conn, xp render.Picture, dr image.Rectangle, src color.Color, pictOp byte) {
 	r, g, b, a := src.RGBA()
 	c := render.Color{
 		Red:   uint16(r),
@@ -164,18 +238,28 @@ func fill(xc *xgb.Conn, xp render.Picture, dr image.Rectangle, src color.Color,
 		Blue:  uint16(b),
 		Alpha: uint16(a),
 	}
-	x, y := dr.Min.X, dr.Min.Y
-	if x < -0x8000 || 0x7fff < x || y < -0x8000 || 0x7fff < y {
+	xr, ok := xRectangle(dr)
+	if !ok {
 		return
 	}
-	dx, dy := dr.Dx(), dr.Dy()
+	render.FillRectangles(xc, pictOp, xp, c, []xproto.Rectangle{xr})
+}
+
+// xRectangle converts r to an xproto.Rectangle. It returns false if r's
+// position or size cannot be represented on the wire.
+func xRectangle(r image.Rectangle) (xproto.Rectangle, bool) {
+	x, y := r.Min.X, r.Min.Y
+	if x < -0x8000 || 0x7fff < x || y < -0x8000 || 0x7fff < y {
+		return xproto.Rectangle{}, false
+	}
+	dx, dy := r.Dx(), r.Dy()
 	if dx < 0 || 0xffff < dx || dy < 0 || 0xffff < dy {
-		return
+		return xproto.Rectangle{}, false
 	}
-	render.FillRectangles(xc, renderOp(op), xp, c, []xproto.Rectangle{{
+	return xproto.Rectangle{
 		X:      int16(x),
 		Y:      int16(y),
 		Width:  uint16(dx),
 		Height: uint16(dy),
-	}})
+	}, true
 }
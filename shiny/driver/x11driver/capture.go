@@ -0,0 +1,48 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func (w *windowImpl) CaptureBackBuffer() (*image.RGBA, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.released {
+		return nil, errors.New("x11driver: CaptureBackBuffer called after Window.Release")
+	}
+	b := w.back
+	// The X11 server processes requests in order, so the reply includes
+	// every Upload and Draw call made before this one.
+	r, err := xproto.GetImage(w.s.xc, xproto.ImageFormatZPixmap, xproto.Drawable(b.xm),
+		0, 0, uint16(b.size.X), uint16(b.size.Y), 0xffffffff).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.GetImage failed: %v", err)
+	}
+	m := image.NewRGBA(image.Rectangle{Max: b.size})
+	if len(r.Data) < len(m.Pix) {
+		return nil, fmt.Errorf("x11driver: xproto.GetImage returned %d bytes", len(r.Data))
+	}
+	// This presumes little-endian BGRX or BGRA, as does findPictformat. Both
+	// the X11 server and image.RGBA use premultiplied alpha.
+	opaque := w.depth != 32
+	for i := 0; i < len(m.Pix); i += 4 {
+		m.Pix[i+0] = r.Data[i+2]
+		m.Pix[i+1] = r.Data[i+1]
+		m.Pix[i+2] = r.Data[i+0]
+		if opaque {
+			m.Pix[i+3] = 0xff
+		} else {
+			m.Pix[i+3] = r.Data[i+3]
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// selectionTimeout is how long to wait for a selection's owner to reply.
+const selectionTimeout = 2 * time.Second
+
+func (s *screenImpl) ClipboardTargets(selection string) ([]string, error) {
+	sel, err := s.internAtom(selection)
+	if err != nil {
+		return nil, err
+	}
+	ev, err := s.convertSelection(sel, s.atomTargets)
+	if err != nil {
+		return nil, err
+	}
+	if ev.Property == xproto.AtomNone {
+		// There is no owner, or it refused the conversion.
+		return nil, nil
+	}
+	v, err := s.getProperty32(s.window32, ev.Property, xproto.AtomAtom)
+	xproto.DeleteProperty(s.xc, s.window32, ev.Property)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]string, 0, len(v))
+	for _, a := range v {
+		name, err := s.atomName(xproto.Atom(a))
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, name)
+	}
+	return targets, nil
+}
+
+// convertSelection asks the owner of sel to convert it to target, storing the
+// result in a property of s.window32, and waits for the owner's reply.
+func (s *screenImpl) convertSelection(sel, target xproto.Atom) (xproto.SelectionNotifyEvent, error) {
+	// Conversions are serialized, as they share the reply property.
+	s.selectionMu.Lock()
+	defer s.selectionMu.Unlock()
+
+	c := make(chan xproto.SelectionNotifyEvent, 1)
+	s.mu.Lock()
+	s.selectionReply = c
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.selectionReply = nil
+		s.mu.Unlock()
+	}()
+
+	err := xproto.ConvertSelectionChecked(s.xc, s.window32, sel, target,
+		s.atomShinySelection, xproto.TimeCurrentTime).Check()
+	if err != nil {
+		return xproto.SelectionNotifyEvent{}, fmt.Errorf("x11driver: xproto.ConvertSelection failed: %v", err)
+	}
+	select {
+	case ev := <-c:
+		return ev, nil
+	case <-time.After(selectionTimeout):
+		return xproto.SelectionNotifyEvent{}, errors.New("x11driver: timed out waiting for the selection owner")
+	}
+}
+
+// handleSelectionReply reports whether ev replied to a convertSelection
+// call.
+func (s *screenImpl) handleSelectionReply(ev xproto.SelectionNotifyEvent) bool {
+	if ev.Requestor != s.window32 {
+		return false
+	}
+	s.mu.Lock()
+	c := s.selectionReply
+	s.mu.Unlock()
+	if c != nil {
+		select {
+		case c <- ev:
+		default:
+		}
+	}
+	return true
+}
+
+// clipboardData is the data offered by a Window.SetClipboard call.
+type clipboardData struct {
+	w    *windowImpl
+	data map[xproto.Atom][]byte
+	// targets are the keys of data, sorted by name.
+	targets []xproto.Atom
+}
+
+func (w *windowImpl) SetClipboard(selection string, data map[string][]byte) error {
+	if len(data) == 0 {
+		return errors.New("x11driver: SetClipboard called with no data")
+	}
+	s := w.s
+	sel, err := s.internAtom(selection)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &clipboardData{
+		w:    w,
+		data: map[xproto.Atom][]byte{},
+	}
+	for _, name := range names {
+		atom, err := s.internAtom(name)
+		if err != nil {
+			return err
+		}
+		c.data[atom] = data[name]
+		c.targets = append(c.targets, atom)
+	}
+	// Programs that predate MIME types ask for UTF8_STRING.
+	if b, ok := data["text/plain;charset=utf-8"]; ok {
+		c.addAlias(s.atomUTF8String, b)
+	} else if b, ok := data["text/plain"]; ok {
+		c.addAlias(s.atomUTF8String, b)
+	}
+
+	s.mu.Lock()
+	if s.clipboards == nil {
+		s.clipboards = map[xproto.Atom]*clipboardData{}
+	}
+	s.clipboards[sel] = c
+	s.mu.Unlock()
+
+	err = xproto.SetSelectionOwnerChecked(s.xc, w.xw, sel, xproto.TimeCurrentTime).Check()
+	if err != nil {
+		s.clearClipboard(sel, w)
+		return fmt.Errorf("x11driver: xproto.SetSelectionOwner failed: %v", err)
+	}
+	return nil
+}
+
+func (c *clipboardData) addAlias(target xproto.Atom, b []byte) {
+	if _, ok := c.data[target]; !ok {
+		c.data[target] = b
+		c.targets = append(c.targets, target)
+	}
+}
+
+// clearClipboard forgets the data that w offered for sel, if any. A nil w
+// means any window.
+func (s *screenImpl) clearClipboard(sel xproto.Atom, w *windowImpl) {
+	s.mu.Lock()
+	if c := s.clipboards[sel]; c != nil && (w == nil || c.w == w) {
+		delete(s.clipboards, sel)
+	}
+	s.mu.Unlock()
+}
+
+// clearClipboards forgets all of the data that w offered, as w is being
+// released.
+func (s *screenImpl) clearClipboards(w *windowImpl) {
+	s.mu.Lock()
+	for sel, c := range s.clipboards {
+		if c.w == w {
+			delete(s.clipboards, sel)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// handleClipboardRequest answers a request for the data of a selection set
+// by SetClipboard, or refuses it if there is no such data.
+func (s *screenImpl) handleClipboardRequest(ev xproto.SelectionRequestEvent) {
+	property := ev.Property
+	if property == xproto.AtomNone {
+		// Obsolete clients use the target as the property.
+		property = ev.Target
+	}
+	s.mu.Lock()
+	c := s.clipboards[ev.Selection]
+	s.mu.Unlock()
+
+	switch {
+	case c == nil || ev.Owner != c.w.xw:
+		property = xproto.AtomNone
+	case ev.Target == s.atomTargets:
+		targets := append([]xproto.Atom{s.atomTargets}, c.targets...)
+		s.setProperty(ev.Requestor, property, targets...)
+	default:
+		if b, ok := c.data[ev.Target]; ok {
+			s.sendSelectionData(ev.Requestor, property, ev.Target, b)
+		} else {
+			property = xproto.AtomNone
+		}
+	}
+	reply := xproto.SelectionNotifyEvent{
+		Time:      ev.Time,
+		Requestor: ev.Requestor,
+		Selection: ev.Selection,
+		Target:    ev.Target,
+		Property:  property,
+	}
+	xproto.SendEvent(s.xc, false, ev.Requestor, xproto.EventMaskNoEvent, string(reply.Bytes()))
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func (s *screenImpl) SampleColor(p image.Point) (color.Color, error) {
+	// The root window spans every display, so p is already in root window
+	// coordinates.
+	if !p.In(image.Rect(0, 0, int(s.xsi.WidthInPixels), int(s.xsi.HeightInPixels))) {
+		return nil, fmt.Errorf("x11driver: point %v is outside the screen", p)
+	}
+	r, err := xproto.GetImage(s.xc, xproto.ImageFormatZPixmap, xproto.Drawable(s.xsi.Root),
+		int16(p.X), int16(p.Y), 1, 1, 0xffffffff).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.GetImage failed: %v", err)
+	}
+	if len(r.Data) < 4 {
+		return nil, fmt.Errorf("x11driver: xproto.GetImage returned %d bytes", len(r.Data))
+	}
+	// This presumes little-endian BGRX, as does findPictformat.
+	return color.RGBA{
+		R: r.Data[2],
+		G: r.Data[1],
+		B: r.Data[0],
+		A: 0xff,
+	}, nil
+}
+
+func (s *screenImpl) PickColor() (color.Color, error) {
+	c := make(chan image.Point, 1)
+	s.mu.Lock()
+	if s.pickColor != nil {
+		s.mu.Unlock()
+		return nil, errors.New("x11driver: PickColor is already in progress")
+	}
+	s.pickColor = c
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.pickColor = nil
+		s.mu.Unlock()
+	}()
+
+	r, err := xproto.GrabPointer(s.xc, false, s.xsi.Root, xproto.EventMaskButtonPress,
+		xproto.GrabModeAsync, xproto.GrabModeAsync, 0,
+		s.cursorCache[screen.CrosshairCursor], xproto.TimeCurrentTime).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.GrabPointer failed: %v", err)
+	}
+	if r.Status != xproto.GrabStatusSuccess {
+		return nil, fmt.Errorf("x11driver: xproto.GrabPointer failed: status %d", r.Status)
+	}
+
+	p := <-c
+	xproto.UngrabPointer(s.xc, xproto.TimeCurrentTime)
+	return s.SampleColor(p)
+}
+
+// handlePickColor reports whether ev completed a PickColor call.
+func (s *screenImpl) handlePickColor(ev xproto.ButtonPressEvent) bool {
+	if ev.Event != s.xsi.Root {
+		return false
+	}
+	s.mu.Lock()
+	c := s.pickColor
+	s.mu.Unlock()
+	if c == nil {
+		return false
+	}
+	select {
+	case c <- image.Point{X: int(ev.RootX), Y: int(ev.RootY)}:
+	default:
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// initCompositor finds out whether a compositing manager is running, which
+// per the EWMH spec is whether the _NET_WM_CM_Sn selection, for screen
+// number n, has an owner, and asks to be told when that changes. If it
+// cannot find out, it assumes that there is no compositing manager.
+func (s *screenImpl) initCompositor() {
+	atom, err := s.internAtom(fmt.Sprintf("_NET_WM_CM_S%d", s.xc.DefaultScreen))
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	r, err := xproto.GetSelectionOwner(s.xc, atom).Reply()
+	if err != nil {
+		log.Printf("x11driver: xproto.GetSelectionOwner failed: %v", err)
+		return
+	}
+	s.atomNetWMCMS = atom
+	s.hasCompositor = r.Owner != 0
+
+	if s.hasXFixes {
+		const mask = xfixes.SelectionEventMaskSetSelectionOwner |
+			xfixes.SelectionEventMaskSelectionWindowDestroy |
+			xfixes.SelectionEventMaskSelectionClientClose
+		if err := xfixes.SelectSelectionInputChecked(s.xc, s.window32, atom, mask).Check(); err != nil {
+			log.Printf("x11driver: xfixes.SelectSelectionInput failed: %v", err)
+		}
+	}
+}
+
+func (s *screenImpl) HasCompositor() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hasCompositor
+}
+
+// handleSelectionNotify handles an XFixes selection owner change, sending a
+// CompositorChangeEvent if a compositing manager started or stopped.
+func (s *screenImpl) handleSelectionNotify(ev xfixes.SelectionNotifyEvent) {
+	if ev.Selection != s.atomNetWMCMS {
+		return
+	}
+	running := ev.Owner != 0
+
+	s.mu.Lock()
+	changed := s.hasCompositor != running
+	s.hasCompositor = running
+	windows := make([]*windowImpl, 0, len(s.windows))
+	for _, w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mu.Unlock()
+
+	if changed {
+		for _, w := range windows {
+			w.Post(screen.CompositorChangeEvent{Running: running})
+		}
+	}
+}
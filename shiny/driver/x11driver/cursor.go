@@ -0,0 +1,220 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/image/draw"
+)
+
+// maxCursorSize is the largest cursor size, in pixels, that SetCursorSize
+// accepts. The X11 server may support smaller cursors only, in which case it
+// scales them down itself.
+const maxCursorSize = 128
+
+// queryCursorSize returns the default cursor size in pixels. It uses the
+// Gtk/CursorThemeSize XSETTINGS setting, which desktop environments set, or
+// failing that, the Xcursor.size X resource or the XCURSOR_SIZE environment
+// variable, which are what libXcursor uses. Failing all of them, it scales
+// the conventional 24 pixel cursor at 96 DPI to the screen's DPI.
+func (s *screenImpl) queryCursorSize() int {
+	n, ok, err := s.xsettingsInt("Gtk/CursorThemeSize")
+	if err != nil {
+		log.Print(err)
+	} else if ok && n > 0 {
+		return clampCursorSize(int(n))
+	}
+	if r, err := xproto.GetProperty(s.xc, false, s.xsi.Root, xproto.AtomResourceManager,
+		xproto.AtomString, 0, 1<<16).Reply(); err == nil {
+		for _, line := range strings.Split(string(r.Value), "\n") {
+			if !strings.HasPrefix(line, "Xcursor.size:") {
+				continue
+			}
+			if n, err := strconv.Atoi(strings.TrimSpace(line[len("Xcursor.size:"):])); err == nil && n > 0 {
+				return clampCursorSize(n)
+			}
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("XCURSOR_SIZE")); err == nil && n > 0 {
+		return clampCursorSize(n)
+	}
+	const ptPerInch = 72
+	dpi := float64(s.pixelsPerPt) * ptPerInch
+	if dpi <= 0 || math.IsInf(dpi, 0) || math.IsNaN(dpi) {
+		return 24
+	}
+	return clampCursorSize(int(math.Round(24 * dpi / 96)))
+}
+
+func clampCursorSize(n int) int {
+	if n > maxCursorSize {
+		return maxCursorSize
+	}
+	return n
+}
+
+func (s *screenImpl) SetCursorSize(px int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if px <= 0 {
+		s.cursorSize = s.defaultCursorSize
+	} else {
+		s.cursorSize = clampCursorSize(px)
+	}
+}
+
+func (w *windowImpl) SetCursorImage(img image.Image, hotspot image.Point) error {
+	xc, err := w.s.newImageCursor(img, hotspot)
+	if err != nil {
+		return err
+	}
+	w.setImageCursor(xc)
+	return nil
+}
+
+func (w *windowImpl) SetAnimatedCursor(frames []image.Image, delays []time.Duration, hotspot image.Point) error {
+	if len(frames) == 0 || len(frames) != len(delays) {
+		return fmt.Errorf("x11driver: %d cursor frames for %d delays", len(frames), len(delays))
+	}
+	s := w.s
+	elts := make([]render.Animcursorelt, 0, len(frames))
+	defer func() {
+		// The animated cursor keeps its own references to the frames.
+		for _, e := range elts {
+			xproto.FreeCursor(s.xc, e.Cursor)
+		}
+	}()
+	for i, img := range frames {
+		xc, err := s.newImageCursor(img, hotspot)
+		if err != nil {
+			return err
+		}
+		elts = append(elts, render.Animcursorelt{
+			Cursor: xc,
+			Delay:  uint32(delays[i] / time.Millisecond),
+		})
+	}
+
+	xc, err := xproto.NewCursorId(s.xc)
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.NewCursorId failed: %v", err)
+	}
+	if err := render.CreateAnimCursorChecked(s.xc, xc, elts).Check(); err != nil {
+		return fmt.Errorf("x11driver: render.CreateAnimCursor failed: %v", err)
+	}
+	w.setImageCursor(xc)
+	return nil
+}
+
+// newImageCursor returns a new cursor showing img, as for SetCursorImage.
+func (s *screenImpl) newImageCursor(img image.Image, hotspot image.Point) (xproto.Cursor, error) {
+	m, hotspot, err := s.scaleCursorImage(img, hotspot)
+	if err != nil {
+		return 0, err
+	}
+	dsize := m.Bounds().Size()
+
+	b, err := s.NewBuffer(dsize)
+	if err != nil {
+		return 0, err
+	}
+	defer b.Release()
+	draw.Draw(b.RGBA(), b.Bounds(), m, image.Point{}, draw.Src)
+	t, err := s.NewTexture(dsize)
+	if err != nil {
+		return 0, err
+	}
+	defer t.Release()
+	t.Upload(image.Point{}, b, b.Bounds())
+
+	xc, err := xproto.NewCursorId(s.xc)
+	if err != nil {
+		return 0, fmt.Errorf("x11driver: xproto.NewCursorId failed: %v", err)
+	}
+	err = render.CreateCursorChecked(s.xc, xc, t.(*textureImpl).xp, uint16(hotspot.X), uint16(hotspot.Y)).Check()
+	if err != nil {
+		return 0, fmt.Errorf("x11driver: render.CreateCursor failed: %v", err)
+	}
+	return xc, nil
+}
+
+// scaleCursorImage returns img scaled so that its larger dimension is the
+// cursor size, and the hotspot of the scaled image.
+func (s *screenImpl) scaleCursorImage(img image.Image, hotspot image.Point) (*image.RGBA, image.Point, error) {
+	s.mu.Lock()
+	px := s.cursorSize
+	s.mu.Unlock()
+
+	sr := img.Bounds()
+	size, hotspot := sr.Size(), hotspot.Sub(sr.Min)
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, image.Point{}, fmt.Errorf("x11driver: empty cursor image")
+	}
+	scale := float64(px) / float64(size.X)
+	if size.Y > size.X {
+		scale = float64(px) / float64(size.Y)
+	}
+	dsize := image.Point{
+		X: int(math.Max(1, math.Round(float64(size.X)*scale))),
+		Y: int(math.Max(1, math.Round(float64(size.Y)*scale))),
+	}
+	if !hotspot.In(image.Rectangle{Max: size}) {
+		return nil, image.Point{}, fmt.Errorf("x11driver: cursor hotspot %v is outside the cursor image", hotspot.Add(sr.Min))
+	}
+
+	m := image.NewRGBA(image.Rectangle{Max: dsize})
+	if dsize == size {
+		draw.Draw(m, m.Bounds(), img, sr.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(m, m.Bounds(), img, sr, draw.Src, nil)
+	}
+	return m, scaleHotspot(hotspot, size, dsize), nil
+}
+
+// scaleHotspot returns the pixel of a cursor image scaled from size to dsize
+// that the hotspot pixel p of the unscaled image maps to. X treats the
+// hotspot as a pixel in the cursor image, so p is mapped via its center, and
+// each axis uses its own scale, as the image's dimensions are rounded
+// separately. The result is always inside the scaled image.
+func scaleHotspot(p, size, dsize image.Point) image.Point {
+	f := func(p, size, dsize int) int {
+		q := (2*p + 1) * dsize / (2 * size)
+		if q >= dsize {
+			q = dsize - 1
+		}
+		return q
+	}
+	return image.Point{
+		X: f(p.X, size.X, dsize.X),
+		Y: f(p.Y, size.Y, dsize.Y),
+	}
+}
+
+// setImageCursor sets the window's cursor to c, which is either zero or a
+// cursor owned by the window, freeing any cursor that the window previously
+// owned.
+func (w *windowImpl) setImageCursor(c xproto.Cursor) {
+	xproto.ChangeWindowAttributes(w.s.xc, w.xw, xproto.CwCursor, []uint32{uint32(c)})
+
+	w.mu.Lock()
+	old := w.imageCursor
+	w.imageCursor = c
+	w.mu.Unlock()
+
+	if old != 0 {
+		xproto.FreeCursor(w.s.xc, old)
+	}
+}
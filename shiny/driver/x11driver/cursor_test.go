@@ -0,0 +1,89 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestScaleHotspot(t *testing.T) {
+	testCases := []struct {
+		p, size, dsize, want image.Point
+	}{
+		// Unscaled cursors keep their hotspot.
+		{image.Pt(0, 0), image.Pt(16, 16), image.Pt(16, 16), image.Pt(0, 0)},
+		{image.Pt(7, 3), image.Pt(16, 16), image.Pt(16, 16), image.Pt(7, 3)},
+		{image.Pt(15, 15), image.Pt(16, 16), image.Pt(16, 16), image.Pt(15, 15)},
+
+		// Scaling up maps the hotspot to the middle of the pixels that the
+		// hotspot pixel becomes.
+		{image.Pt(0, 0), image.Pt(16, 16), image.Pt(32, 32), image.Pt(1, 1)},
+		{image.Pt(15, 15), image.Pt(16, 16), image.Pt(32, 32), image.Pt(31, 31)},
+		{image.Pt(1, 1), image.Pt(3, 3), image.Pt(24, 24), image.Pt(12, 12)},
+
+		// Scaling down keeps a hotspot on the last pixel inside the image.
+		{image.Pt(4, 4), image.Pt(5, 5), image.Pt(2, 2), image.Pt(1, 1)},
+		{image.Pt(31, 0), image.Pt(32, 32), image.Pt(24, 24), image.Pt(23, 0)},
+
+		// Each axis is scaled separately.
+		{image.Pt(9, 4), image.Pt(10, 5), image.Pt(24, 12), image.Pt(22, 10)},
+	}
+	for _, tc := range testCases {
+		got := scaleHotspot(tc.p, tc.size, tc.dsize)
+		if got != tc.want {
+			t.Errorf("p=%v, size=%v, dsize=%v: got %v, want %v", tc.p, tc.size, tc.dsize, got, tc.want)
+		}
+	}
+}
+
+func TestScaleCursorImage(t *testing.T) {
+	testCases := []struct {
+		px      int
+		bounds  image.Rectangle
+		hotspot image.Point
+	}{
+		{16, image.Rect(0, 0, 16, 16), image.Pt(3, 5)},
+		{48, image.Rect(0, 0, 16, 16), image.Pt(0, 0)},
+		{48, image.Rect(0, 0, 16, 16), image.Pt(15, 9)},
+		{40, image.Rect(10, 20, 26, 28), image.Pt(12, 27)},
+		{24, image.Rect(0, 0, 32, 32), image.Pt(31, 0)},
+		{10, image.Rect(0, 0, 32, 32), image.Pt(17, 6)},
+	}
+	for _, tc := range testCases {
+		// The cursor image is transparent apart from its hotspot pixel.
+		img := image.NewRGBA(tc.bounds)
+		img.Set(tc.hotspot.X, tc.hotspot.Y, color.Black)
+
+		s := &screenImpl{}
+		s.SetCursorSize(tc.px)
+		m, hotspot, err := s.scaleCursorImage(img, tc.hotspot)
+		if err != nil {
+			t.Errorf("px=%d, hotspot=%v: %v", tc.px, tc.hotspot, err)
+			continue
+		}
+		size := m.Bounds().Size()
+		if size.X != tc.px && size.Y != tc.px {
+			t.Errorf("px=%d, hotspot=%v: scaled size %v", tc.px, tc.hotspot, size)
+		}
+
+		// The unscaled hotspot pixel is spread over the pixels that it
+		// scales to, so the scaled hotspot must be one of the most opaque
+		// pixels of the scaled image.
+		var max uint8
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				if a := m.RGBAAt(x, y).A; a > max {
+					max = a
+				}
+			}
+		}
+		if a := m.RGBAAt(hotspot.X, hotspot.Y).A; 2*int(a) < int(max) {
+			t.Errorf("px=%d, hotspot=%v: scaled hotspot %v has alpha %d, want at least half of %d",
+				tc.px, tc.hotspot, hotspot, a, max)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+const (
+	// XInput2 device uses.
+	xiMasterPointer  = 1
+	xiMasterKeyboard = 2
+	xiSlavePointer   = 3
+	xiSlaveKeyboard  = 4
+
+	// XInput2 device classes.
+	xiKeyClass      = 0
+	xiButtonClass   = 1
+	xiValuatorClass = 2
+	xiTouchClass    = 8
+
+	// xiDirectTouch is the mode of a touch class for a touchscreen, as
+	// opposed to a touchpad.
+	xiDirectTouch = 1
+
+	// The flags of an XI_HierarchyChanged event's device infos.
+	xiMasterRemoved  = 1 << 1
+	xiSlaveRemoved   = 1 << 3
+	xiDeviceDisabled = 1 << 7
+)
+
+// xiDevice is an input device, as described by XIQueryDevice.
+type xiDevice struct {
+	id, use     uint16
+	enabled     bool
+	name        string
+	keys        bool
+	buttons     bool
+	directTouch bool
+	valuators   []xiValuator
+
+	// stylus is the last StylusEvent sent for the device, if it is a
+	// tablet.
+	stylus screen.StylusEvent
+}
+
+// xiValuator is one of an input device's axes.
+type xiValuator struct {
+	number   uint16
+	label    xproto.Atom
+	min, max float64
+}
+
+// parseXIDevices decodes an XIQueryDevice reply.
+func parseXIDevices(r []byte) ([]xiDevice, error) {
+	errShort := fmt.Errorf("x11driver: xinput.XIQueryDevice returned %d bytes", len(r))
+	if len(r) < 32 {
+		return nil, errShort
+	}
+	n := int(xgb.Get16(r[8:]))
+	devices := make([]xiDevice, 0, n)
+	b := r[32:]
+	for i := 0; i < n; i++ {
+		if len(b) < 12 {
+			return nil, errShort
+		}
+		d := xiDevice{
+			id:      xgb.Get16(b[0:]),
+			use:     xgb.Get16(b[2:]),
+			enabled: b[10] != 0,
+		}
+		nClasses, nameLen := int(xgb.Get16(b[6:])), int(xgb.Get16(b[8:]))
+		if len(b) < 12+nameLen {
+			return nil, errShort
+		}
+		d.name = string(b[12 : 12+nameLen])
+		b = b[12+(nameLen+3)&^3:]
+
+		for j := 0; j < nClasses; j++ {
+			if len(b) < 4 {
+				return nil, errShort
+			}
+			cLen := 4 * int(xgb.Get16(b[2:]))
+			if cLen < 4 || len(b) < cLen {
+				return nil, errShort
+			}
+			c := b[:cLen]
+			switch xgb.Get16(c[0:]) {
+			case xiKeyClass:
+				d.keys = true
+			case xiButtonClass:
+				d.buttons = true
+			case xiValuatorClass:
+				if len(c) >= 44 {
+					d.valuators = append(d.valuators, xiValuator{
+						number: xgb.Get16(c[6:]),
+						label:  xproto.Atom(xgb.Get32(c[8:])),
+						min:    fp3232(c[12:]),
+						max:    fp3232(c[20:]),
+					})
+				}
+			case xiTouchClass:
+				if len(c) >= 8 && c[6] == xiDirectTouch {
+					d.directTouch = true
+				}
+			}
+			b = b[len(c):]
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// queryXIDevices returns the given device, or all devices for xiAllDevices.
+func (s *screenImpl) queryXIDevices(device uint16) ([]xiDevice, error) {
+	buf := s.xinput.newRequest(xiQueryDevice, 8)
+	xgb.Put16(buf[4:], device)
+	r, err := s.xinput.send(s.xc, buf, true, true).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xinput.XIQueryDevice failed: %v", err)
+	}
+	return parseXIDevices(r)
+}
+
+// valuator returns the device's valuator with the given label, if it has one.
+func (d *xiDevice) valuator(label xproto.Atom) (xiValuator, bool) {
+	for _, v := range d.valuators {
+		if v.label == label && label != 0 {
+			return v, true
+		}
+	}
+	return xiValuator{}, false
+}
+
+// deviceInfo returns the screen.DeviceInfo for d.
+func (s *screenImpl) deviceInfo(d *xiDevice) screen.DeviceInfo {
+	info := screen.DeviceInfo{
+		ID:   int(d.id),
+		Name: d.name,
+	}
+	// The X11 server does not say what kind of pointer a device is, so it
+	// is guessed from the device's name and classes.
+	name := strings.ToLower(d.name)
+	_, pressure := d.valuator(s.atomAbsPressure)
+	switch {
+	case strings.Contains(name, "gamepad") || strings.Contains(name, "joystick"):
+		info.Type = screen.DeviceGamepad
+	case d.use == xiMasterKeyboard || d.use == xiSlaveKeyboard:
+		info.Type = screen.DeviceKeyboard
+	case pressure:
+		info.Type = screen.DeviceTablet
+	case d.directTouch:
+		info.Type = screen.DeviceTouchscreen
+	case d.use == xiMasterPointer || d.use == xiSlavePointer || d.buttons:
+		info.Type = screen.DevicePointer
+	case d.keys:
+		info.Type = screen.DeviceKeyboard
+	}
+	return info
+}
+
+// initDevices records the enabled input devices, and selects the events for
+// devices being connected or disconnected.
+func (s *screenImpl) initDevices() {
+	devices, err := s.queryXIDevices(xiAllDevices)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	s.devices = map[uint16]*xiDevice{}
+	for i := range devices {
+		if d := &devices[i]; d.enabled {
+			s.devices[d.id] = d
+		}
+	}
+	// XI_HierarchyChanged events are only sent to the root window, for
+	// XIAllDevices.
+	if err := s.selectXIEvents(s.xsi.Root, xiAllDevices, xiHierarchyChanged); err != nil {
+		log.Print(err)
+	}
+}
+
+// handleHierarchyChanged handles an XI_HierarchyChanged event.
+func (s *screenImpl) handleHierarchyChanged(buf []byte) {
+	e := s.updateDevices(buf, s.queryXIDevices)
+	if len(e.Added) == 0 && len(e.Removed) == 0 {
+		return
+	}
+
+	// Tablets that have been plugged in are selected on every window.
+	var tablets []uint16
+	s.mu.Lock()
+	for _, info := range e.Added {
+		if d := s.devices[uint16(info.ID)]; d != nil && s.isTablet(d) {
+			tablets = append(tablets, d.id)
+		}
+	}
+	var windows []xproto.Window
+	if len(tablets) != 0 {
+		for xw := range s.windows {
+			windows = append(windows, xw)
+		}
+	}
+	s.mu.Unlock()
+	for _, xw := range windows {
+		s.selectStylus(xw, tablets)
+	}
+
+	s.sendAll(e)
+}
+
+// updateDevices updates s.devices for an XI_HierarchyChanged event, with
+// query describing the devices that have been added or enabled. A disabled
+// device counts as removed, as it sends no events until it is enabled again.
+func (s *screenImpl) updateDevices(buf []byte, query func(device uint16) ([]xiDevice, error)) screen.InputDeviceEvent {
+	var e screen.InputDeviceEvent
+	if len(buf) < 32 {
+		return e
+	}
+	n := int(xgb.Get16(buf[20:]))
+	if len(buf) < 32+12*n {
+		return e
+	}
+	for i := 0; i < n; i++ {
+		info := buf[32+12*i:]
+		id, enabled, flags := xgb.Get16(info[0:]), info[5] != 0, xgb.Get32(info[8:])
+		gone := !enabled || flags&(xiMasterRemoved|xiSlaveRemoved|xiDeviceDisabled) != 0
+
+		s.mu.Lock()
+		d := s.devices[id]
+		if d != nil && gone {
+			delete(s.devices, id)
+		}
+		s.mu.Unlock()
+
+		if d != nil && gone {
+			e.Removed = append(e.Removed, s.deviceInfo(d))
+		} else if d == nil && !gone {
+			devices, err := query(id)
+			if err != nil {
+				log.Print(err)
+				continue
+			}
+			if len(devices) != 1 || !devices[0].enabled {
+				continue
+			}
+			d := &devices[0]
+			s.mu.Lock()
+			if s.devices == nil {
+				s.devices = map[uint16]*xiDevice{}
+			}
+			s.devices[id] = d
+			s.mu.Unlock()
+			e.Added = append(e.Added, s.deviceInfo(d))
+		}
+	}
+	return e
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// xiDeviceReply returns an XIQueryDevice reply for devices.
+func xiDeviceReply(devices ...xiDevice) []byte {
+	r := make([]byte, 32)
+	r[0] = 1
+	xgb.Put16(r[8:], uint16(len(devices)))
+	for _, d := range devices {
+		var classes [][]byte
+		if d.keys {
+			classes = append(classes, make([]byte, 8))
+		}
+		if d.buttons {
+			c := make([]byte, 8)
+			xgb.Put16(c, xiButtonClass)
+			classes = append(classes, c)
+		}
+		for _, v := range d.valuators {
+			c := make([]byte, 44)
+			xgb.Put16(c, xiValuatorClass)
+			xgb.Put16(c[6:], v.number)
+			xgb.Put32(c[8:], uint32(v.label))
+			putFP3232(c[12:], v.min)
+			putFP3232(c[20:], v.max)
+			classes = append(classes, c)
+		}
+		if d.directTouch {
+			c := make([]byte, 8)
+			xgb.Put16(c, xiTouchClass)
+			c[6] = xiDirectTouch
+			classes = append(classes, c)
+		}
+
+		b := make([]byte, 12+(len(d.name)+3)&^3)
+		xgb.Put16(b[0:], d.id)
+		xgb.Put16(b[2:], d.use)
+		xgb.Put16(b[6:], uint16(len(classes)))
+		xgb.Put16(b[8:], uint16(len(d.name)))
+		if d.enabled {
+			b[10] = 1
+		}
+		copy(b[12:], d.name)
+		for _, c := range classes {
+			xgb.Put16(c[2:], uint16(len(c)/4))
+			b = append(b, c...)
+		}
+		r = append(r, b...)
+	}
+	xgb.Put32(r[4:], uint32(len(r)-32)/4)
+	return r
+}
+
+func TestParseXIDevices(t *testing.T) {
+	const absPressure = xproto.Atom(100)
+	s := &screenImpl{atomAbsPressure: absPressure}
+	devices := []xiDevice{{
+		id:      2,
+		use:     xiMasterPointer,
+		enabled: true,
+		name:    "Virtual core pointer",
+		buttons: true,
+		valuators: []xiValuator{
+			{number: 0, label: 101, min: -1, max: 1919.5},
+		},
+	}, {
+		id:      11,
+		use:     xiSlavePointer,
+		enabled: true,
+		name:    "Wacom Intuos Pen",
+		buttons: true,
+		valuators: []xiValuator{
+			{number: 0, label: 101, min: 0, max: 44704},
+			{number: 2, label: absPressure, min: 0, max: 2047},
+		},
+	}, {
+		id:          12,
+		use:         xiSlavePointer,
+		name:        "ELAN Touchscreen",
+		directTouch: true,
+	}, {
+		id:      13,
+		use:     xiSlaveKeyboard,
+		enabled: true,
+		name:    "AT Translated Set 2 keyboard",
+		keys:    true,
+	}, {
+		id:      14,
+		use:     xiSlavePointer,
+		enabled: true,
+		name:    "Logitech Gamepad F310",
+		buttons: true,
+	}}
+	got, err := parseXIDevices(xiDeviceReply(devices...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, devices) {
+		t.Fatalf("got %+v, want %+v", got, devices)
+	}
+
+	wantTypes := []screen.DeviceType{
+		screen.DevicePointer,
+		screen.DeviceTablet,
+		screen.DeviceTouchscreen,
+		screen.DeviceKeyboard,
+		screen.DeviceGamepad,
+	}
+	for i := range got {
+		info := s.deviceInfo(&got[i])
+		want := screen.DeviceInfo{ID: int(devices[i].id), Name: devices[i].name, Type: wantTypes[i]}
+		if info != want {
+			t.Errorf("device %d: got %+v, want %+v", i, info, want)
+		}
+	}
+
+	reply := xiDeviceReply(devices...)
+	for _, n := range []int{31, 40, len(reply) - 1} {
+		if _, err := parseXIDevices(reply[:n]); err == nil {
+			t.Errorf("%d bytes: no error", n)
+		}
+	}
+}
+
+func TestUpdateDevices(t *testing.T) {
+	keyboard := &xiDevice{id: 3, use: xiMasterKeyboard, enabled: true, name: "Virtual core keyboard", keys: true}
+	mouse := &xiDevice{id: 9, use: xiSlavePointer, enabled: true, name: "USB Mouse", buttons: true}
+	s := &screenImpl{
+		devices: map[uint16]*xiDevice{3: keyboard, 9: mouse},
+	}
+	pen := xiDevice{id: 10, use: xiSlavePointer, enabled: true, name: "Pen", buttons: true}
+	query := func(device uint16) ([]xiDevice, error) {
+		if device != 10 {
+			t.Errorf("queried device %d", device)
+		}
+		return []xiDevice{pen}, nil
+	}
+
+	// The mouse is unplugged, the pen is plugged in, and the keyboard is
+	// attached to another master device.
+	buf := xiEvent(xiHierarchyChanged, 32+12*3)
+	xgb.Put16(buf[20:], 3)
+	info := func(i int, id uint16, enabled bool, flags uint32) {
+		b := buf[32+12*i:]
+		xgb.Put16(b[0:], id)
+		if enabled {
+			b[5] = 1
+		}
+		xgb.Put32(b[8:], flags)
+	}
+	info(0, 9, false, xiSlaveRemoved)
+	info(1, 10, true, 1<<6) // XIDeviceEnabled.
+	info(2, 3, true, 1<<4)  // XISlaveAttached.
+
+	got := s.updateDevices(buf, query)
+	want := screen.InputDeviceEvent{
+		Added:   []screen.DeviceInfo{{ID: 10, Name: "Pen", Type: screen.DevicePointer}},
+		Removed: []screen.DeviceInfo{{ID: 9, Name: "USB Mouse", Type: screen.DevicePointer}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(s.devices) != 2 || s.devices[3] != keyboard || s.devices[10] == nil {
+		t.Errorf("devices: got %v", s.devices)
+	}
+}
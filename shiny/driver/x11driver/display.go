@@ -0,0 +1,304 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/BurntSushi/xgb/randr"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+type displayImpl struct {
+	s *screenImpl
+
+	name    string
+	bounds  image.Rectangle
+	primary bool
+
+	// output, crtc and mode are zero if the X11 server does not support
+	// RandR. modes are the output's modes, and modeIDs their RandR IDs.
+	output  randr.Output
+	crtc    randr.Crtc
+	mode    randr.Mode
+	modes   []screen.VideoMode
+	modeIDs []randr.Mode
+}
+
+func (d *displayImpl) Name() string            { return d.name }
+func (d *displayImpl) Bounds() image.Rectangle { return d.bounds }
+func (d *displayImpl) Primary() bool           { return d.primary }
+
+func (d *displayImpl) WorkArea() image.Rectangle {
+	s := d.s
+	v, err := s.getProperty32(s.xsi.Root, s.atomNetWorkarea, xproto.AtomCardinal)
+	if err != nil {
+		log.Print(err)
+		return d.bounds
+	}
+	// _NET_WORKAREA holds an x, y, width and height for each workspace.
+	// Window managers set it to the work area of the whole screen, rather
+	// than of each display, so it is intersected with the display's bounds.
+	i := 0
+	if c, err := s.getProperty32(s.xsi.Root, s.atomNetCurrentDesktop, xproto.AtomCardinal); err == nil && len(c) == 1 {
+		i = int(c[0])
+	}
+	if len(v) < 4*(i+1) {
+		return d.bounds
+	}
+	v = v[4*i:]
+	// The values are CARDINALs, but x and y may be negative.
+	x, y := int(int32(v[0])), int(int32(v[1]))
+	r := image.Rect(x, y, x+int(v[2]), y+int(v[3])).Intersect(d.bounds)
+	if r.Empty() {
+		return d.bounds
+	}
+	return r
+}
+
+func (d *displayImpl) equal(e *displayImpl) bool {
+	return d.name == e.name && d.bounds == e.bounds && d.primary == e.primary &&
+		d.output == e.output && d.crtc == e.crtc && d.mode == e.mode
+}
+
+func (d *displayImpl) Modes() []screen.VideoMode {
+	return append([]screen.VideoMode(nil), d.modes...)
+}
+
+func (d *displayImpl) RefreshRate() float64 {
+	for i, id := range d.modeIDs {
+		if id == d.mode {
+			return d.modes[i].RefreshRate
+		}
+	}
+	return 0
+}
+
+func (d *displayImpl) SetMode(m screen.VideoMode) error {
+	mode := randr.Mode(0)
+	for i, n := range d.modes {
+		if n == m {
+			mode = d.modeIDs[i]
+			break
+		}
+	}
+	if mode == 0 {
+		return fmt.Errorf("x11driver: display %q does not support video mode %v", d.name, m)
+	}
+
+	s := d.s
+	s.mu.Lock()
+	if _, ok := s.origModes[d.crtc]; !ok {
+		s.origModes[d.crtc] = d.mode
+	}
+	s.mu.Unlock()
+	return s.setCrtcMode(d.crtc, mode)
+}
+
+// setCrtcMode changes crtc's mode, keeping its position, rotation and
+// outputs.
+func (s *screenImpl) setCrtcMode(crtc randr.Crtc, mode randr.Mode) error {
+	res, err := randr.GetScreenResourcesCurrent(s.xc, s.xsi.Root).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: randr.GetScreenResourcesCurrent failed: %v", err)
+	}
+	ci, err := randr.GetCrtcInfo(s.xc, crtc, res.ConfigTimestamp).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: randr.GetCrtcInfo failed: %v", err)
+	}
+	r, err := randr.SetCrtcConfig(s.xc, crtc, ci.Timestamp, res.ConfigTimestamp,
+		ci.X, ci.Y, mode, ci.Rotation, ci.Outputs).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: randr.SetCrtcConfig failed: %v", err)
+	}
+	if r.Status != randr.SetConfigSuccess {
+		return fmt.Errorf("x11driver: randr.SetCrtcConfig failed: status %d", r.Status)
+	}
+	return nil
+}
+
+// restoreModes restores the original mode of every display changed by
+// SetMode.
+func (s *screenImpl) restoreModes() {
+	s.mu.Lock()
+	origModes := s.origModes
+	s.origModes = map[randr.Crtc]randr.Mode{}
+	s.mu.Unlock()
+
+	for crtc, mode := range origModes {
+		if err := s.setCrtcMode(crtc, mode); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// videoMode returns the screen.VideoMode for the RandR mode m.
+func videoMode(m randr.ModeInfo) screen.VideoMode {
+	vm := screen.VideoMode{
+		Width:  int(m.Width),
+		Height: int(m.Height),
+	}
+	vtotal := float64(m.Vtotal)
+	if m.ModeFlags&randr.ModeFlagDoubleScan != 0 {
+		vtotal *= 2
+	}
+	if m.ModeFlags&randr.ModeFlagInterlace != 0 {
+		vtotal /= 2
+	}
+	if m.Htotal != 0 && vtotal != 0 {
+		vm.RefreshRate = float64(m.DotClock) / (float64(m.Htotal) * vtotal)
+	}
+	return vm
+}
+
+// initRandR initializes the RandR extension, if the X11 server supports it,
+// and the initial set of displays.
+func (s *screenImpl) initRandR() error {
+	if err := randr.Init(s.xc); err == nil {
+		// RandR 1.3 is needed for GetScreenResourcesCurrent and
+		// GetOutputPrimary.
+		if r, err := randr.QueryVersion(s.xc, 1, 3).Reply(); err == nil &&
+			(r.MajorVersion > 1 || (r.MajorVersion == 1 && r.MinorVersion >= 3)) {
+			s.hasRandR = true
+		}
+	}
+	if s.hasRandR {
+		const mask = randr.NotifyMaskScreenChange | randr.NotifyMaskCrtcChange | randr.NotifyMaskOutputChange
+		if err := randr.SelectInputChecked(s.xc, s.xsi.Root, mask).Check(); err != nil {
+			return fmt.Errorf("x11driver: randr.SelectInput failed: %v", err)
+		}
+	}
+	displays, err := s.queryDisplays()
+	if err != nil {
+		return err
+	}
+	s.displays = displays
+	return nil
+}
+
+func (s *screenImpl) Displays() ([]screen.Display, error) {
+	s.mu.Lock()
+	displays := s.displays
+	s.mu.Unlock()
+	return toScreenDisplays(displays), nil
+}
+
+func toScreenDisplays(displays []*displayImpl) []screen.Display {
+	ret := make([]screen.Display, len(displays))
+	for i, d := range displays {
+		ret[i] = d
+	}
+	return ret
+}
+
+func (s *screenImpl) queryDisplays() ([]*displayImpl, error) {
+	if !s.hasRandR {
+		return []*displayImpl{{
+			s:       s,
+			name:    "default",
+			bounds:  image.Rect(0, 0, int(s.xsi.WidthInPixels), int(s.xsi.HeightInPixels)),
+			primary: true,
+			modes: []screen.VideoMode{{
+				Width:  int(s.xsi.WidthInPixels),
+				Height: int(s.xsi.HeightInPixels),
+			}},
+			// The zero mode ID makes SetMode fail for the current mode too.
+			modeIDs: []randr.Mode{0},
+		}}, nil
+	}
+
+	res, err := randr.GetScreenResourcesCurrent(s.xc, s.xsi.Root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: randr.GetScreenResourcesCurrent failed: %v", err)
+	}
+	primary, err := randr.GetOutputPrimary(s.xc, s.xsi.Root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: randr.GetOutputPrimary failed: %v", err)
+	}
+
+	modeInfos := map[randr.Mode]randr.ModeInfo{}
+	for _, m := range res.Modes {
+		modeInfos[randr.Mode(m.Id)] = m
+	}
+
+	var displays []*displayImpl
+	for _, o := range res.Outputs {
+		oi, err := randr.GetOutputInfo(s.xc, o, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("x11driver: randr.GetOutputInfo failed: %v", err)
+		}
+		if oi.Connection != randr.ConnectionConnected || oi.Crtc == 0 {
+			continue
+		}
+		ci, err := randr.GetCrtcInfo(s.xc, oi.Crtc, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("x11driver: randr.GetCrtcInfo failed: %v", err)
+		}
+		d := &displayImpl{
+			s:       s,
+			name:    string(oi.Name),
+			bounds:  image.Rect(int(ci.X), int(ci.Y), int(ci.X)+int(ci.Width), int(ci.Y)+int(ci.Height)),
+			primary: o == primary.Output,
+			output:  o,
+			crtc:    oi.Crtc,
+			mode:    ci.Mode,
+		}
+		for _, m := range oi.Modes {
+			if mi, ok := modeInfos[m]; ok {
+				d.modes = append(d.modes, videoMode(mi))
+				d.modeIDs = append(d.modeIDs, m)
+			}
+		}
+		displays = append(displays, d)
+	}
+
+	// If no output is marked as primary, treat the first one as primary.
+	hasPrimary := false
+	for _, d := range displays {
+		hasPrimary = hasPrimary || d.primary
+	}
+	if !hasPrimary && len(displays) > 0 {
+		displays[0].primary = true
+	}
+	return displays, nil
+}
+
+// handleDisplayChange re-queries the set of displays after a RandR
+// notification and, if it changed, sends a DisplayChangeEvent to every
+// window.
+func (s *screenImpl) handleDisplayChange() {
+	displays, err := s.queryDisplays()
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	s.mu.Lock()
+	changed := len(displays) != len(s.displays)
+	for i := 0; !changed && i < len(displays); i++ {
+		changed = !displays[i].equal(s.displays[i])
+	}
+	if changed {
+		s.displays = displays
+	}
+	windows := make([]*windowImpl, 0, len(s.windows))
+	for _, w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	for _, w := range windows {
+		w.Post(screen.DisplayChangeEvent{
+			Displays: toScreenDisplays(displays),
+		})
+	}
+}
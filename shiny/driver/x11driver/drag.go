@@ -0,0 +1,360 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// xdndVersion is the version of the XDND protocol that this driver speaks.
+const xdndVersion = 5
+
+// xdndTimeout is how long to wait, after the pointer button is released, for
+// the drop target to finish with the drop before giving up on it.
+const xdndTimeout = 10 * time.Second
+
+// dragState is the state of a drag started by Window.StartDrag, from the
+// time the pointer is grabbed until the drop target reports that it has
+// finished with the data.
+type dragState struct {
+	w       *windowImpl
+	data    map[xproto.Atom][]byte
+	typeIDs []xproto.Atom
+
+	mu sync.Mutex
+
+	// target is the XdndAware window under the pointer, or zero if there is
+	// none, and version is the XDND version it speaks.
+	target  xproto.Window
+	version uint32
+
+	// accepted is whether the target's most recent XdndStatus accepted the
+	// drop. waiting is whether an XdndPosition has been sent but not yet
+	// answered, and pending holds the pointer position to send once it is.
+	accepted bool
+	waiting  bool
+	pending  *image.Point
+
+	// released is whether the pointer button has been released, ending the
+	// drag, and dropped is whether XdndDrop has been sent. timer, if non-nil,
+	// gives up on the drop target once the drag has ended.
+	released bool
+	dropped  bool
+	timer    *time.Timer
+}
+
+func (w *windowImpl) StartDrag(data map[string][]byte, hotspot image.Point) error {
+	if len(data) == 0 {
+		return errors.New("x11driver: StartDrag called with no data")
+	}
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	d := &dragState{
+		w:    w,
+		data: map[xproto.Atom][]byte{},
+	}
+	for _, name := range names {
+		atom, err := w.s.internAtom(name)
+		if err != nil {
+			return err
+		}
+		d.data[atom] = data[name]
+		d.typeIDs = append(d.typeIDs, atom)
+	}
+	if old := w.s.currentDrag(); old != nil {
+		old.finish(false)
+	}
+	w.s.setProperty(w.xw, w.s.atomXdndTypeList, d.typeIDs...)
+
+	err := xproto.SetSelectionOwnerChecked(w.s.xc, w.xw, w.s.atomXdndSelection, xproto.TimeCurrentTime).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.SetSelectionOwner failed: %v", err)
+	}
+	r, err := xproto.GrabPointer(w.s.xc, false, w.xw,
+		xproto.EventMaskButtonRelease|xproto.EventMaskPointerMotion,
+		xproto.GrabModeAsync, xproto.GrabModeAsync, 0, 0, xproto.TimeCurrentTime).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.GrabPointer failed: %v", err)
+	}
+	if r.Status != xproto.GrabStatusSuccess {
+		return fmt.Errorf("x11driver: xproto.GrabPointer failed: status %d", r.Status)
+	}
+
+	w.s.mu.Lock()
+	w.s.drag = d
+	w.s.mu.Unlock()
+
+	p, err := w.translateToScreen(w.s.xsi, hotspot)
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.TranslateCoordinates failed: %v", err)
+	}
+	d.mu.Lock()
+	d.motion(p, xproto.TimeCurrentTime)
+	d.mu.Unlock()
+	return nil
+}
+
+// currentDrag returns the drag in progress, if any.
+func (s *screenImpl) currentDrag() *dragState {
+	s.mu.Lock()
+	d := s.drag
+	s.mu.Unlock()
+	return d
+}
+
+// handleDragMotion reports whether ev was consumed by a drag in progress.
+// Once the pointer button is released, the drag no longer consumes events,
+// even while it waits for the drop target to finish.
+func (s *screenImpl) handleDragMotion(ev xproto.MotionNotifyEvent) bool {
+	d := s.currentDrag()
+	if d == nil || ev.Event != d.w.xw {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.released {
+		return false
+	}
+	d.motion(image.Point{X: int(ev.RootX), Y: int(ev.RootY)}, ev.Time)
+	return true
+}
+
+// handleDragRelease reports whether ev was consumed by a drag in progress,
+// which is only the case for the release that ends the drag.
+func (s *screenImpl) handleDragRelease(ev xproto.ButtonReleaseEvent) bool {
+	d := s.currentDrag()
+	if d == nil || ev.Event != d.w.xw {
+		return false
+	}
+	d.mu.Lock()
+	if d.released {
+		d.mu.Unlock()
+		return false
+	}
+	d.released = true
+	d.timer = time.AfterFunc(xdndTimeout, d.timeout)
+	if !d.waiting {
+		d.drop(ev.Time)
+	}
+	d.mu.Unlock()
+	xproto.UngrabPointer(s.xc, ev.Time)
+	return true
+}
+
+// handleXdnd reports whether ev was an XDND message for a drag in progress.
+func (s *screenImpl) handleXdnd(ev xproto.ClientMessageEvent) bool {
+	if ev.Format != 32 || (ev.Type != s.atomXdndStatus && ev.Type != s.atomXdndFinished) {
+		return false
+	}
+	d := s.currentDrag()
+	if d == nil || ev.Window != d.w.xw {
+		return true
+	}
+	data := ev.Data.Data32
+
+	d.mu.Lock()
+	if xproto.Window(data[0]) != d.target {
+		d.mu.Unlock()
+		return true
+	}
+	if ev.Type == s.atomXdndFinished {
+		// XdndFinished's accepted flag was added in version 5.
+		accepted := d.version < 5 || data[1]&1 != 0
+		d.mu.Unlock()
+		d.finish(accepted)
+		return true
+	}
+	d.accepted = data[1]&1 != 0
+	d.waiting = false
+	switch {
+	case d.released && !d.dropped:
+		d.drop(xproto.TimeCurrentTime)
+	case d.pending != nil:
+		p := *d.pending
+		d.pending = nil
+		d.motion(p, xproto.TimeCurrentTime)
+	}
+	d.mu.Unlock()
+	return true
+}
+
+// handleDragSelectionRequest reports whether ev asked for the data of a drag
+// in progress.
+func (s *screenImpl) handleDragSelectionRequest(ev xproto.SelectionRequestEvent) bool {
+	if ev.Selection != s.atomXdndSelection {
+		return false
+	}
+	property := ev.Property
+	if property == xproto.AtomNone {
+		// Obsolete clients use the target as the property.
+		property = ev.Target
+	}
+	if d := s.currentDrag(); d != nil && ev.Owner == d.w.xw {
+		if b, ok := d.data[ev.Target]; ok {
+			s.sendSelectionData(ev.Requestor, property, ev.Target, b)
+		} else {
+			property = xproto.AtomNone
+		}
+	} else {
+		property = xproto.AtomNone
+	}
+	reply := xproto.SelectionNotifyEvent{
+		Time:      ev.Time,
+		Requestor: ev.Requestor,
+		Selection: ev.Selection,
+		Target:    ev.Target,
+		Property:  property,
+	}
+	xproto.SendEvent(s.xc, false, ev.Requestor, xproto.EventMaskNoEvent, string(reply.Bytes()))
+	return true
+}
+
+// motion handles the pointer moving to p, in root window coordinates. It
+// must only be called while holding d.mu.
+func (d *dragState) motion(p image.Point, t xproto.Timestamp) {
+	target, version := d.w.s.findXdndAware(p)
+	if target != d.target {
+		if d.target != 0 {
+			d.send(d.w.s.atomXdndLeave, 0)
+		}
+		d.target, d.version = target, version
+		d.accepted, d.waiting, d.pending = false, false, nil
+		if d.target == 0 {
+			return
+		}
+		flags := d.version << 24
+		if len(d.typeIDs) > 3 {
+			flags |= 1
+		}
+		types := [3]uint32{}
+		for i := 0; i < len(d.typeIDs) && i < len(types); i++ {
+			types[i] = uint32(d.typeIDs[i])
+		}
+		d.send(d.w.s.atomXdndEnter, flags, types[0], types[1], types[2])
+	}
+	if d.target == 0 {
+		return
+	}
+	if d.waiting {
+		d.pending = &p
+		return
+	}
+	d.waiting = true
+	d.send(d.w.s.atomXdndPosition, 0, uint32(p.X)<<16|uint32(p.Y)&0xffff, uint32(t), uint32(d.w.s.atomXdndActionCopy))
+}
+
+// drop ends the drag, dropping the data onto the target if it accepted the
+// drop. It must only be called while holding d.mu.
+func (d *dragState) drop(t xproto.Timestamp) {
+	if d.target != 0 && d.accepted {
+		d.dropped = true
+		d.send(d.w.s.atomXdndDrop, 0, uint32(t))
+		return
+	}
+	if d.target != 0 {
+		d.send(d.w.s.atomXdndLeave, 0)
+	}
+	go d.finish(false)
+}
+
+// finish sends a DragFinishedEvent, unless one was already sent, and clears
+// the drag in progress. It must not be called while holding d.mu.
+func (d *dragState) finish(accepted bool) {
+	s := d.w.s
+	s.mu.Lock()
+	current := s.drag == d
+	if current {
+		s.drag = nil
+	}
+	s.mu.Unlock()
+
+	d.mu.Lock()
+	released := d.released
+	d.released = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.mu.Unlock()
+	if !released {
+		xproto.UngrabPointer(s.xc, xproto.TimeCurrentTime)
+	}
+
+	if current {
+		d.w.Post(screen.DragFinishedEvent{
+			Accepted: accepted,
+		})
+	}
+}
+
+// timeout gives up on a drop target that has not answered within
+// xdndTimeout of the drag ending, finishing the drag as not accepted.
+func (d *dragState) timeout() {
+	d.mu.Lock()
+	if d.target != 0 && !d.dropped {
+		// The target never answered the last XdndPosition.
+		d.send(d.w.s.atomXdndLeave, 0)
+	}
+	d.mu.Unlock()
+	d.finish(false)
+}
+
+// send sends an XDND client message to the target. It must only be called
+// while holding d.mu.
+func (d *dragState) send(typ xproto.Atom, data ...uint32) {
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: d.target,
+		Type:   typ,
+		Data: xproto.ClientMessageDataUnionData32New(append([]uint32{
+			uint32(d.w.xw),
+		}, append(data, make([]uint32, 4-len(data))...)...)),
+	}
+	xproto.SendEvent(d.w.s.xc, false, d.target, xproto.EventMaskNoEvent, string(ev.Bytes()))
+}
+
+// findXdndAware returns the XdndAware window at p, in root window
+// coordinates, and the XDND version that both it and this driver speak. It
+// returns zero if there is no such window.
+func (s *screenImpl) findXdndAware(p image.Point) (xproto.Window, uint32) {
+	xw := s.xsi.Root
+	for {
+		r, err := xproto.TranslateCoordinates(s.xc, s.xsi.Root, xw, int16(p.X), int16(p.Y)).Reply()
+		if err != nil {
+			log.Printf("x11driver: xproto.TranslateCoordinates failed: %v", err)
+			return 0, 0
+		}
+		if r.Child == 0 {
+			return 0, 0
+		}
+		xw = r.Child
+
+		prop, err := xproto.GetProperty(s.xc, false, xw, s.atomXdndAware, xproto.AtomAtom, 0, 1).Reply()
+		if err != nil {
+			log.Printf("x11driver: xproto.GetProperty failed: %v", err)
+			return 0, 0
+		}
+		if prop.Format == 32 && len(prop.Value) >= 4 {
+			version := uint32(prop.Value[0]) | uint32(prop.Value[1])<<8 | uint32(prop.Value[2])<<16 | uint32(prop.Value[3])<<24
+			if version > xdndVersion {
+				version = xdndVersion
+			}
+			return xw, version
+		}
+	}
+}
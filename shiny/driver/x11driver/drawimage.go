@@ -0,0 +1,68 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/draw"
+	"log"
+)
+
+func (w *windowImpl) DrawImage(dp image.Point, img image.Image, op draw.Op) {
+	sr := img.Bounds()
+	size := sr.Size()
+	if size.X <= 0 || size.Y <= 0 {
+		return
+	}
+
+	s := w.s
+	s.scratchMu.Lock()
+	defer s.scratchMu.Unlock()
+
+	if err := s.growScratch(size); err != nil {
+		log.Print(err)
+		return
+	}
+	r := image.Rectangle{Max: size}
+	draw.Draw(s.scratchBuf.RGBA(), r, img, sr.Min, draw.Src)
+	s.scratchTex.Upload(image.Point{}, s.scratchBuf, r)
+	// The X11 server handles requests in order, so the texture can be
+	// reused as soon as the Copy request is sent.
+	w.Copy(dp, s.scratchTex, r, op, nil)
+}
+
+// growScratch makes s.scratchBuf and s.scratchTex at least size, reallocating
+// them if necessary. It must only be called while holding s.scratchMu.
+func (s *screenImpl) growScratch(size image.Point) error {
+	if s.scratchBuf != nil {
+		have := s.scratchBuf.Size()
+		if size.X <= have.X && size.Y <= have.Y {
+			return nil
+		}
+		// Grow both dimensions, so that alternately drawing wide and tall
+		// images doesn't reallocate every time.
+		if size.X < have.X {
+			size.X = have.X
+		}
+		if size.Y < have.Y {
+			size.Y = have.Y
+		}
+		s.scratchBuf.Release()
+		s.scratchTex.Release()
+		s.scratchBuf, s.scratchTex = nil, nil
+	}
+
+	b, err := s.NewBuffer(size)
+	if err != nil {
+		return err
+	}
+	t, err := s.NewTexture(size)
+	if err != nil {
+		b.Release()
+		return err
+	}
+	s.scratchBuf, s.scratchTex = b, t
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// fadeInterval is the time between opacity changes while fading.
+const fadeInterval = time.Second / 60
+
+func (w *windowImpl) SetOpacity(opacity float64) {
+	w.mu.Lock()
+	w.fadeGen++
+	w.mu.Unlock()
+	w.setOpacity(opacity)
+}
+
+func (w *windowImpl) setOpacity(opacity float64) {
+	// Per the compositing manager conventions, a fully opaque window has no
+	// _NET_WM_WINDOW_OPACITY property.
+	if opacity >= 1 {
+		xproto.DeleteProperty(w.s.xc, w.xw, w.s.atomNetWMWindowOpacity)
+		return
+	}
+	if opacity < 0 {
+		opacity = 0
+	}
+	w.s.setProperty32(w.xw, w.s.atomNetWMWindowOpacity, xproto.AtomCardinal, uint32(opacity*0xffffffff))
+}
+
+// fade animates the window's opacity from from to to over d. It returns
+// early, without reaching to, if another fade or SetOpacity call starts in
+// the meantime.
+func (w *windowImpl) fade(from, to float64, d time.Duration) {
+	w.mu.Lock()
+	w.fadeGen++
+	gen := w.fadeGen
+	w.mu.Unlock()
+
+	ticks, stop := w.s.NewAlarm(fadeInterval)
+	defer stop()
+	start := time.Now()
+	for t := range ticks {
+		w.mu.Lock()
+		cancelled := w.fadeGen != gen || w.released
+		w.mu.Unlock()
+		if cancelled {
+			return
+		}
+		f := float64(t.Sub(start)) / float64(d)
+		if f >= 1 {
+			break
+		}
+		w.setOpacity(from + (to-from)*f)
+	}
+	w.setOpacity(to)
+}
+
+func (w *windowImpl) FadeOutAndRelease(d time.Duration) {
+	if d > 0 {
+		w.fade(1, 0, d)
+	}
+	w.Release()
+}
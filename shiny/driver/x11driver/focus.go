@@ -0,0 +1,66 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func (w *windowImpl) Focus() error {
+	// Respect the ICCCM input hint set for NewWindowOptions.NoFocus.
+	if w.noFocus {
+		return nil
+	}
+	a, err := xproto.GetWindowAttributes(w.s.xc, w.xw).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.GetWindowAttributes failed: %v", err)
+	}
+	if a.MapState != xproto.MapStateViewable {
+		return nil
+	}
+
+	// Use the time of the window's latest user input, so that the window
+	// manager's focus stealing prevention can tell a request that follows
+	// user interaction from a stale one. Without any input yet, this is
+	// zero, which is xproto.TimeCurrentTime.
+	w.mu.Lock()
+	t := w.lastInputTime
+	w.mu.Unlock()
+
+	supported, err := w.s.wmSupports(w.s.atomNetActiveWindow)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		// Without an EWMH window manager, nothing else manages the focus.
+		err := xproto.SetInputFocusChecked(w.s.xc, xproto.InputFocusParent, w.xw, t).Check()
+		if err != nil {
+			return fmt.Errorf("x11driver: xproto.SetInputFocus failed: %v", err)
+		}
+		return nil
+	}
+	// Ask the window manager to activate the window, which also focuses it.
+	// It may refuse, to prevent focus stealing, so this does not fall back
+	// to setting the focus directly.
+	w.sendWMMessage(w.s.atomNetActiveWindow, sourceNormalApp, uint32(t), 0)
+	return nil
+}
+
+// wmSupports returns whether the window manager lists atom in the root
+// window's _NET_SUPPORTED property.
+func (s *screenImpl) wmSupports(atom xproto.Atom) (bool, error) {
+	v, err := s.getProperty32(s.xsi.Root, s.atomNetSupported, xproto.AtomAtom)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range v {
+		if xproto.Atom(a) == atom {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/image/math/f64"
+)
+
+type frameImpl struct {
+	w     *windowImpl
+	ended bool
+}
+
+func (w *windowImpl) BeginFrame() screen.Frame {
+	// Make sure that the back buffer is the window's current size before the
+	// frame's first drawing operation.
+	w.target()
+	return &frameImpl{w: w}
+}
+
+func (f *frameImpl) check() {
+	if f.ended {
+		panic("x11driver: Frame used after Frame.End")
+	}
+}
+
+func (f *frameImpl) Upload(dp image.Point, src screen.Buffer, sr image.Rectangle) {
+	f.check()
+	f.w.Upload(dp, src, sr)
+}
+
+func (f *frameImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	f.check()
+	f.w.Fill(dr, src, op)
+}
+
+func (f *frameImpl) DrawUniform(src2dst f64.Aff3, src color.Color, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	f.check()
+	f.w.DrawUniform(src2dst, src, sr, op, opts)
+}
+
+func (f *frameImpl) Draw(src2dst f64.Aff3, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	f.check()
+	f.w.Draw(src2dst, src, sr, op, opts)
+}
+
+func (f *frameImpl) Copy(dp image.Point, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	f.check()
+	f.w.Copy(dp, src, sr, op, opts)
+}
+
+func (f *frameImpl) Scale(dr image.Rectangle, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	f.check()
+	f.w.Scale(dr, src, sr, op, opts)
+}
+
+func (f *frameImpl) Size() image.Point {
+	f.check()
+	return f.w.target().size
+}
+
+func (f *frameImpl) End() screen.PublishResult {
+	f.check()
+	f.ended = true
+	return f.w.Publish()
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"time"
+
+	"golang.org/x/mobile/event/paint"
+)
+
+func (w *windowImpl) SetMaxFrameRate(fps int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if fps <= 0 {
+		w.frameInterval = 0
+	} else {
+		w.frameInterval = time.Second / time.Duration(fps)
+	}
+}
+
+func (w *windowImpl) RequestPaint() {
+	w.mu.Lock()
+	if w.frameInterval == 0 {
+		w.mu.Unlock()
+		w.PostUnique(paint.Event{})
+		return
+	}
+	if w.paintTimer != nil {
+		// A paint.Event is already scheduled, and this request is coalesced
+		// into it.
+		w.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	if wait := w.lastPaint.Add(w.frameInterval).Sub(now); wait > 0 {
+		w.paintTimer = time.AfterFunc(wait, w.sendScheduledPaint)
+		w.mu.Unlock()
+		return
+	}
+	w.lastPaint = now
+	w.mu.Unlock()
+	w.PostUnique(paint.Event{})
+}
+
+// sendScheduledPaint sends the paint.Event for the RequestPaint calls that
+// came too soon after the previous one.
+func (w *windowImpl) sendScheduledPaint() {
+	w.mu.Lock()
+	w.paintTimer = nil
+	w.lastPaint = time.Now()
+	released := w.released
+	w.mu.Unlock()
+	if !released {
+		w.PostUnique(paint.Event{})
+	}
+}
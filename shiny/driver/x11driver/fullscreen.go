@@ -0,0 +1,52 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/BurntSushi/xgb/xinerama"
+)
+
+func (w *windowImpl) FullscreenOnMonitor(i int) error {
+	if i < 0 {
+		w.changeWMState(false, w.s.atomNetWMStateFullscreen)
+		return nil
+	}
+
+	s := w.s
+	s.mu.Lock()
+	displays := s.displays
+	s.mu.Unlock()
+	if i >= len(displays) {
+		return fmt.Errorf("x11driver: invalid monitor index %d", i)
+	}
+
+	// _NET_WM_FULLSCREEN_MONITORS uses Xinerama's monitor numbering, which
+	// need not be the same as the order of Screen.Displays, so look up the
+	// display's bounds in the Xinerama screens.
+	monitor := i
+	if s.hasXinerama {
+		qs, err := xinerama.QueryScreens(s.xc).Reply()
+		if err != nil {
+			return fmt.Errorf("x11driver: xinerama.QueryScreens failed: %v", err)
+		}
+		for j, si := range qs.ScreenInfo {
+			r := image.Rect(int(si.XOrg), int(si.YOrg), int(si.XOrg)+int(si.Width), int(si.YOrg)+int(si.Height))
+			if r == displays[i].bounds {
+				monitor = j
+				break
+			}
+		}
+	}
+
+	// The monitors are the top, bottom, left and right edges of the
+	// fullscreen area, which spans just the one monitor.
+	m := uint32(monitor)
+	w.sendWMMessage(s.atomNetWMFullscreenMonitors, m, m, m, m, sourceNormalApp)
+	w.changeWMState(true, s.atomNetWMStateFullscreen)
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func (w *windowImpl) Geometry() (screen.GeometrySpec, error) {
+	s := w.s
+	gg, err := xproto.GetGeometry(s.xc, xproto.Drawable(w.xw)).Reply()
+	if err != nil {
+		return screen.GeometrySpec{}, fmt.Errorf("x11driver: xproto.GetGeometry failed: %v", err)
+	}
+	origin, err := w.translateToScreen(s.xsi, image.Point{})
+	if err != nil {
+		return screen.GeometrySpec{}, fmt.Errorf("x11driver: xproto.TranslateCoordinates failed: %v", err)
+	}
+	size := image.Point{int(gg.Width), int(gg.Height)}
+	g := screen.GeometrySpec{
+		Bounds:     image.Rectangle{Min: origin, Max: origin.Add(size)},
+		ClientSize: size,
+	}
+
+	// _NET_FRAME_EXTENTS is the left, right, top and bottom size of the
+	// window manager's decorations, if any.
+	if e, err := s.getProperty32(w.xw, s.atomNetFrameExtents, xproto.AtomCardinal); err == nil && len(e) == 4 {
+		g.Bounds.Min.X -= int(e[0])
+		g.Bounds.Max.X += int(e[1])
+		g.Bounds.Min.Y -= int(e[2])
+		g.Bounds.Max.Y += int(e[3])
+	}
+
+	if state, err := s.getProperty32(w.xw, s.atomNetWMState, xproto.AtomAtom); err == nil {
+		g.Maximized = s.windowState(state).Maximized
+	}
+	if d := s.displayAt(g.Bounds); d != nil {
+		g.Display = d.name
+	}
+	return g, nil
+}
+
+func (w *windowImpl) CurrentDisplay() screen.Display {
+	g, err := w.Geometry()
+	if err != nil {
+		log.Print(err)
+		return nil
+	}
+	if d := w.s.displayAt(g.Bounds); d != nil {
+		return d
+	}
+	return nil
+}
+
+// displayAt returns the display that shows the largest part of r, or nil if
+// none of r is shown.
+func (s *screenImpl) displayAt(r image.Rectangle) *displayImpl {
+	s.mu.Lock()
+	displays := s.displays
+	s.mu.Unlock()
+
+	var ret *displayImpl
+	area := 0
+	for _, d := range displays {
+		i := d.bounds.Intersect(r)
+		if a := i.Dx() * i.Dy(); a > area {
+			ret, area = d, a
+		}
+	}
+	return ret
+}
+
+// placeGeometry returns the position, in screen coordinates, at which to
+// create a window restored from g whose contents are width by height pixels.
+func (s *screenImpl) placeGeometry(g *screen.GeometrySpec, width, height int) (x, y int) {
+	s.mu.Lock()
+	displays := s.displays
+	s.mu.Unlock()
+
+	var primary *displayImpl
+	for _, d := range displays {
+		if d.name == g.Display && d.bounds.Overlaps(g.Bounds) {
+			return g.Bounds.Min.X, g.Bounds.Min.Y
+		}
+		if d.primary || primary == nil {
+			primary = d
+		}
+	}
+	if primary == nil {
+		return 0, 0
+	}
+
+	// Center the window, including its saved decorations, on the primary
+	// display's work area.
+	outer := image.Point{width, height}.Add(g.Bounds.Size()).Sub(g.ClientSize)
+	b := primary.WorkArea()
+	return b.Min.X + (b.Dx()-outer.X)/2, b.Min.Y + (b.Dy()-outer.Y)/2
+}
+
+// setGeometry sets the properties that ask the window manager to place the
+// window, before it is mapped, at its created position and maximized state.
+func (w *windowImpl) setGeometry(g *screen.GeometrySpec, x, y, width, height int) {
+	const (
+		usPosition  = 1 << 0
+		usSize      = 1 << 1
+		pWinGravity = 1 << 9
+	)
+	// The northwest gravity means that (x, y) is the position of the
+	// top-left corner of the window manager's decorations, as saved in
+	// g.Bounds, rather than of the window's contents.
+	hints := make([]uint32, sizeHintsLen)
+	hints[0] = usPosition | usSize | pWinGravity
+	hints[1], hints[2] = uint32(x), uint32(y)
+	hints[3], hints[4] = uint32(width), uint32(height)
+	hints[17] = xproto.GravityNorthWest
+	w.s.setProperty32(w.xw, xproto.AtomWmNormalHints, xproto.AtomWmSizeHints, hints...)
+
+	if g.Maximized {
+		w.s.setProperty(w.xw, w.s.atomNetWMState,
+			w.s.atomNetWMStateMaximizedVert, w.s.atomNetWMStateMaximizedHorz)
+	}
+}
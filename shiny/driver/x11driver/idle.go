@@ -0,0 +1,107 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/BurntSushi/xgb/screensaver"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func (s *screenImpl) IdleTime() (time.Duration, error) {
+	if !s.hasScreenSaver {
+		return 0, errors.New("x11driver: idle time requires the MIT-SCREEN-SAVER extension")
+	}
+	r, err := screensaver.QueryInfo(s.xc, xproto.Drawable(s.xsi.Root)).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("x11driver: screensaver.QueryInfo failed: %v", err)
+	}
+	return time.Duration(r.MsSinceUserInput) * time.Millisecond, nil
+}
+
+func (s *screenImpl) SetIdleThreshold(d time.Duration) {
+	s.mu.Lock()
+	c := s.idleThreshold
+	if c == nil && d > 0 {
+		c = make(chan time.Duration, 1)
+		s.idleThreshold = c
+		go s.watchIdle(c)
+	}
+	s.mu.Unlock()
+
+	if c == nil {
+		return
+	}
+	// Replace any threshold that watchIdle hasn't picked up yet.
+	select {
+	case <-c:
+	default:
+	}
+	c <- d
+}
+
+// watchIdle polls the user's idle time, sending a UserActiveEvent to every
+// window when the user becomes active after being idle for longer than the
+// most recent threshold received on c.
+func (s *screenImpl) watchIdle(c chan time.Duration) {
+	var (
+		threshold time.Duration
+		idle      bool
+		maxIdle   time.Duration
+	)
+	for {
+		if threshold <= 0 {
+			threshold, idle, maxIdle = <-c, false, 0
+			continue
+		}
+
+		// Poll often enough to notice the user becoming active reasonably
+		// promptly, but not so often that we flood the X11 server.
+		interval := threshold / 4
+		if interval > time.Second {
+			interval = time.Second
+		}
+		select {
+		case threshold = <-c:
+			continue
+		case <-time.After(interval):
+		}
+
+		t, err := s.IdleTime()
+		if err != nil {
+			log.Print(err)
+			s.mu.Lock()
+			s.idleThreshold = nil
+			s.mu.Unlock()
+			return
+		}
+		if t >= threshold {
+			idle, maxIdle = true, t
+			continue
+		}
+		if !idle {
+			continue
+		}
+		idle = false
+
+		s.mu.Lock()
+		windows := make([]*windowImpl, 0, len(s.windows))
+		for _, w := range s.windows {
+			windows = append(windows, w)
+		}
+		s.mu.Unlock()
+		for _, w := range windows {
+			w.Post(screen.UserActiveEvent{
+				IdleTime: maxIdle,
+			})
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// incrTimeout is how long to wait for the requestor of selection data sent
+// with the INCR protocol to ask for the next chunk before giving up.
+const incrTimeout = 10 * time.Second
+
+// incrKey identifies an INCR transfer by the requestor's window and property.
+type incrKey struct {
+	requestor xproto.Window
+	property  xproto.Atom
+}
+
+// incrTransfer is selection data being sent with the INCR protocol, as
+// described in the ICCCM section 2.7.2.
+type incrTransfer struct {
+	target xproto.Atom
+	data   []byte
+	// done is whether the final, zero-length, chunk has been sent.
+	done  bool
+	timer *time.Timer
+}
+
+// maxPropertyChunk returns the largest number of bytes that a single
+// ChangeProperty request can hold.
+func (s *screenImpl) maxPropertyChunk() int {
+	// The maximum request length is in 4 byte units, and a ChangeProperty
+	// request has a 24 byte header.
+	return 4*int(xproto.Setup(s.xc).MaximumRequestLength) - 24
+}
+
+// sendSelectionData sets the requestor's property to b, of type target, in
+// answer to a SelectionRequest. If b is too large for a single request, it
+// starts an INCR transfer, which handleIncrProperty continues.
+func (s *screenImpl) sendSelectionData(requestor xproto.Window, property, target xproto.Atom, b []byte) {
+	if len(b) <= s.maxPropertyChunk() {
+		xproto.ChangeProperty(s.xc, xproto.PropModeReplace, requestor, property, target, 8, uint32(len(b)), b)
+		return
+	}
+
+	k := incrKey{requestor, property}
+	t := &incrTransfer{
+		target: target,
+		data:   b,
+	}
+	t.timer = time.AfterFunc(incrTimeout, func() { s.endIncr(k, t) })
+
+	s.mu.Lock()
+	if s.incrs == nil {
+		s.incrs = map[incrKey]*incrTransfer{}
+	}
+	if old := s.incrs[k]; old != nil {
+		old.timer.Stop()
+	}
+	s.incrs[k] = t
+	s.mu.Unlock()
+
+	// Listen for the requestor deleting the property, which asks for the
+	// next chunk, before telling it that the transfer has started. The
+	// property's value is a lower bound on the size of the data.
+	xproto.ChangeWindowAttributes(s.xc, requestor, xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange})
+	s.setProperty32(requestor, property, s.atomIncr, uint32(len(b)))
+}
+
+// handleIncrProperty reports whether ev is about the property of an INCR
+// transfer, sending the next chunk of the data if the requestor deleted the
+// property.
+func (s *screenImpl) handleIncrProperty(ev xproto.PropertyNotifyEvent) bool {
+	k := incrKey{ev.Window, ev.Atom}
+	s.mu.Lock()
+	t := s.incrs[k]
+	s.mu.Unlock()
+	if t == nil {
+		return false
+	}
+	if ev.State != xproto.PropertyDelete {
+		// This is our own change to the property.
+		return true
+	}
+	if t.done {
+		// The requestor has received the final chunk.
+		s.endIncr(k, t)
+		return true
+	}
+
+	n := len(t.data)
+	if max := s.maxPropertyChunk(); n > max {
+		n = max
+	}
+	xproto.ChangeProperty(s.xc, xproto.PropModeReplace, k.requestor, k.property, t.target, 8, uint32(n), t.data[:n])
+	t.data = t.data[n:]
+	t.done = n == 0
+	t.timer.Reset(incrTimeout)
+	return true
+}
+
+// endIncr forgets the INCR transfer t, if it is still the transfer for k.
+func (s *screenImpl) endIncr(k incrKey, t *incrTransfer) {
+	s.mu.Lock()
+	if s.incrs[k] != t {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.incrs, k)
+	busy := false
+	for other := range s.incrs {
+		busy = busy || other.requestor == k.requestor
+	}
+	s.mu.Unlock()
+
+	t.timer.Stop()
+	if !busy {
+		xproto.ChangeWindowAttributes(s.xc, k.requestor, xproto.CwEventMask, []uint32{0})
+	}
+}
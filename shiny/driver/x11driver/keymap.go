@@ -0,0 +1,88 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func (s *screenImpl) KeyboardMapping() [][]uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make([][]uint32, len(s.keyboardMapping))
+	for i, keysyms := range s.keyboardMapping {
+		m[i] = append([]uint32(nil), keysyms...)
+	}
+	return m
+}
+
+func (s *screenImpl) ModifierMapping() (m [8][]uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, keycodes := range s.modifierMapping {
+		m[i] = append([]uint8(nil), keycodes...)
+	}
+	return m
+}
+
+func (s *screenImpl) PointerMapping() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]byte(nil), s.pointerMapping...)
+}
+
+func (s *screenImpl) initPointerMapping() error {
+	pm, err := xproto.GetPointerMapping(s.xc).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.GetPointerMapping failed: %v", err)
+	}
+	s.mu.Lock()
+	s.pointerMapping = pm.Map
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *screenImpl) initModifierMapping() error {
+	mm, err := xproto.GetModifierMapping(s.xc).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.GetModifierMapping failed: %v", err)
+	}
+	var m [8][]uint8
+	n := int(mm.KeycodesPerModifier)
+	for i := range m {
+		for _, kc := range mm.Keycodes[i*n : (i+1)*n] {
+			// Unused entries are zero.
+			if kc != 0 {
+				m[i] = append(m[i], uint8(kc))
+			}
+		}
+	}
+	s.mu.Lock()
+	s.modifierMapping = m
+	s.keysyms.SetModifiers(m)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *screenImpl) handleMappingNotify(ev xproto.MappingNotifyEvent) {
+	var err error
+	switch ev.Request {
+	case xproto.MappingKeyboard:
+		err = s.initKeyboardMapping()
+	case xproto.MappingModifier:
+		err = s.initModifierMapping()
+	case xproto.MappingPointer:
+		err = s.initPointerMapping()
+	}
+	if err != nil {
+		log.Print(err)
+	}
+}
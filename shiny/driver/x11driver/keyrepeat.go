@@ -0,0 +1,146 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/xgb"
+)
+
+// The key repeat delay and interval are XKB controls. The github.com/
+// BurntSushi/xgb package does not support the XKB extension, so its
+// UseExtension, GetControls and SetControls requests are built by hand.
+const (
+	xkbUseExtension = 0
+	xkbGetControls  = 6
+	xkbSetControls  = 7
+
+	// xkbUseCoreKbd is the device spec of the core keyboard.
+	xkbUseCoreKbd = 0x100
+	// xkbRepeatKeysMask is the SetControls changeControls bit that applies
+	// the repeatDelay and repeatInterval fields.
+	xkbRepeatKeysMask = 1 << 0
+	// xkbNumErrors is the number of errors that the XKB extension defines.
+	xkbNumErrors = 1
+)
+
+var errNoXKB = errors.New("x11driver: the X11 server does not support the XKEYBOARD extension")
+
+// keyRepeat is the key repeat delay and interval, in milliseconds.
+type keyRepeat struct {
+	delay, interval uint16
+}
+
+// initXKB finds and initializes the XKB extension, if the X11 server
+// supports it.
+func (s *screenImpl) initXKB() {
+	e, ok, err := s.queryExtension("XKEYBOARD", xkbNumErrors)
+	if err != nil || !ok {
+		return
+	}
+	// The XKB protocol requires that UseExtension is the first request, and
+	// fails any other request until it succeeds.
+	buf := e.newRequest(xkbUseExtension, 8)
+	xgb.Put16(buf[4:], 1) // wantedMajor.
+	xgb.Put16(buf[6:], 0) // wantedMinor.
+	r, err := e.send(s.xc, buf, true, true).Reply()
+	if err != nil || len(r) < 2 || r[1] == 0 { // r[1] is the supported field.
+		return
+	}
+	s.xkb = e
+	s.hasXKB = true
+}
+
+func (s *screenImpl) getKeyRepeat() (keyRepeat, error) {
+	if !s.hasXKB {
+		return keyRepeat{}, errNoXKB
+	}
+	buf := s.xkb.newRequest(xkbGetControls, 8)
+	xgb.Put16(buf[4:], xkbUseCoreKbd)
+	r, err := s.xkb.send(s.xc, buf, true, true).Reply()
+	if err != nil {
+		return keyRepeat{}, fmt.Errorf("x11driver: xkb.GetControls failed: %v", err)
+	}
+	if len(r) < 24 {
+		return keyRepeat{}, errors.New("x11driver: xkb.GetControls reply is too short")
+	}
+	return keyRepeat{
+		delay:    xgb.Get16(r[20:]),
+		interval: xgb.Get16(r[22:]),
+	}, nil
+}
+
+func (s *screenImpl) setKeyRepeat(k keyRepeat) error {
+	if !s.hasXKB {
+		return errNoXKB
+	}
+	// Every field other than the device, the changeControls mask and the
+	// repeat delay and interval is left zero, which leaves the other
+	// controls unchanged.
+	buf := s.xkb.newRequest(xkbSetControls, 100)
+	xgb.Put16(buf[4:], xkbUseCoreKbd)
+	xgb.Put32(buf[32:], xkbRepeatKeysMask)
+	xgb.Put16(buf[36:], k.delay)
+	xgb.Put16(buf[38:], k.interval)
+	if err := s.xkb.send(s.xc, buf, true, false).Check(); err != nil {
+		return fmt.Errorf("x11driver: xkb.SetControls failed: %v", err)
+	}
+	return nil
+}
+
+func (s *screenImpl) KeyRepeat() (delay, interval time.Duration, err error) {
+	k, err := s.getKeyRepeat()
+	if err != nil {
+		return 0, 0, err
+	}
+	return time.Duration(k.delay) * time.Millisecond, time.Duration(k.interval) * time.Millisecond, nil
+}
+
+// repeatMillis converts d to the milliseconds that XKB uses.
+func repeatMillis(d time.Duration) (uint16, error) {
+	ms := d / time.Millisecond
+	if ms < 1 || 0xffff < ms {
+		return 0, fmt.Errorf("x11driver: invalid key repeat duration %v", d)
+	}
+	return uint16(ms), nil
+}
+
+func (s *screenImpl) SetKeyRepeat(delay, interval time.Duration) error {
+	var k keyRepeat
+	var err error
+	if k.delay, err = repeatMillis(delay); err != nil {
+		return err
+	}
+	if k.interval, err = repeatMillis(interval); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.savedKeyRepeat == nil {
+		saved, err := s.getKeyRepeat()
+		if err != nil {
+			return err
+		}
+		s.savedKeyRepeat = &saved
+	}
+	return s.setKeyRepeat(k)
+}
+
+func (s *screenImpl) RestoreKeyRepeat() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.savedKeyRepeat
+	if k == nil {
+		return nil
+	}
+	s.savedKeyRepeat = nil
+	return s.setKeyRepeat(*k)
+}
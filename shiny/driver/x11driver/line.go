@@ -0,0 +1,96 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/BurntSushi/xgb/render"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func (w *windowImpl) DrawLine(p0, p1 image.Point, width float64, src color.Color, style screen.StrokeStyle, op draw.Op) {
+	w.s.drawLine(w.target().xp, p0, p1, width, src, style, op)
+}
+
+// dashPattern returns the lengths of the on and off parts of style's dashes,
+// for a line of the given width. A zero off length means a solid line.
+func dashPattern(style screen.StrokeStyle, width float64) (on, off float64) {
+	width = math.Max(width, 1)
+	switch style {
+	case screen.StrokeDashed:
+		return 3 * width, 2 * width
+	case screen.StrokeDotted:
+		return width, width
+	}
+	return 0, 0
+}
+
+func (s *screenImpl) drawLine(xp render.Picture, p0, p1 image.Point, width float64, src color.Color, style screen.StrokeStyle, op draw.Op) {
+	if width <= 0 {
+		return
+	}
+	// The line runs between pixel centers.
+	x0, y0 := float64(p0.X)+0.5, float64(p0.Y)+0.5
+	dx, dy := float64(p1.X-p0.X), float64(p1.Y-p0.Y)
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	// (ux, uy) is the unit vector along the line and (nx, ny) is half of
+	// the line's width, perpendicular to it.
+	ux, uy := dx/length, dy/length
+	nx, ny := -uy*width/2, ux*width/2
+
+	segment := func(a, b float64) [4]render.Pointfix {
+		ax, ay := x0+ux*a, y0+uy*a
+		bx, by := x0+ux*b, y0+uy*b
+		return [4]render.Pointfix{
+			{X: f64ToFixed(ax + nx), Y: f64ToFixed(ay + ny)},
+			{X: f64ToFixed(bx + nx), Y: f64ToFixed(by + ny)},
+			{X: f64ToFixed(bx - nx), Y: f64ToFixed(by - ny)},
+			{X: f64ToFixed(ax - nx), Y: f64ToFixed(ay - ny)},
+		}
+	}
+	var segments [][4]render.Pointfix
+	on, off := dashPattern(style, width)
+	if off == 0 {
+		segments = append(segments, segment(0, length))
+	} else {
+		for a := 0.0; a < length; a += on + off {
+			segments = append(segments, segment(a, math.Min(a+on, length)))
+		}
+	}
+
+	r, g, b, a := src.RGBA()
+	c := render.Color{
+		Red:   uint16(r),
+		Green: uint16(g),
+		Blue:  uint16(b),
+		Alpha: uint16(a),
+	}
+
+	s.uniformMu.Lock()
+	defer s.uniformMu.Unlock()
+
+	if s.uniformC != c {
+		s.uniformC = c
+		render.FreePicture(s.xc, s.uniformP)
+		render.CreateSolidFill(s.xc, s.uniformP, c)
+	}
+
+	for _, points := range segments {
+		if op == draw.Src {
+			// We implement draw.Src as render.PictOpOutReverse followed by
+			// render.PictOpOver, for the same reason as in textureImpl.draw.
+			render.TriFan(s.xc, render.PictOpOutReverse, s.opaqueP, xp, 0, 0, 0, points[:])
+		}
+		render.TriFan(s.xc, render.PictOpOver, s.uniformP, xp, 0, 0, 0, points[:])
+	}
+}
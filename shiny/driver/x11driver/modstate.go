@@ -0,0 +1,86 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/driver/internal/x11key"
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/key"
+)
+
+// These keysyms come from /usr/include/X11/keysymdef.h.
+const (
+	xkNumLock    = 0xff7f
+	xkScrollLock = 0xff14
+)
+
+func (w *windowImpl) ModifierState() (key.Modifiers, screen.LockState, error) {
+	qp, err := xproto.QueryPointer(w.s.xc, w.xw).Reply()
+	if err != nil {
+		return 0, 0, fmt.Errorf("x11driver: xproto.QueryPointer failed: %v", err)
+	}
+	numLockMask, scrollLockMask := w.s.lockMasks()
+
+	var locks screen.LockState
+	if qp.Mask&x11key.LockMask != 0 {
+		locks |= screen.CapsLock
+	}
+	if qp.Mask&numLockMask != 0 {
+		locks |= screen.NumLock
+	}
+	if qp.Mask&scrollLockMask != 0 {
+		locks |= screen.ScrollLock
+	}
+	return x11key.KeyModifiers(qp.Mask), locks, nil
+}
+
+func (w *windowImpl) PressedKeys() (map[key.Code]bool, error) {
+	km, err := xproto.QueryKeymap(w.s.xc).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.QueryKeymap failed: %v", err)
+	}
+
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+
+	// km.Keys is a bit vector, with bit i%8 of byte i/8 set if keycode i is
+	// pressed.
+	pressed := map[key.Code]bool{}
+	for i, b := range km.Keys {
+		for j := uint(0); j < 8; j++ {
+			if b&(1<<j) == 0 {
+				continue
+			}
+			if _, c := w.s.keysyms.Lookup(uint8(i*8)+uint8(j), 0); c != key.CodeUnknown {
+				pressed[c] = true
+			}
+		}
+	}
+	return pressed, nil
+}
+
+// lockMasks returns the modifier masks that the Num Lock and Scroll Lock keys
+// are mapped to. Unlike Caps Lock, which is always the Lock modifier, these
+// are bound to one of Mod1 to Mod5 by the modifier mapping.
+func (s *screenImpl) lockMasks() (numLock, scrollLock uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for mod, keycodes := range s.modifierMapping {
+		for _, kc := range keycodes {
+			switch s.keysyms.Table[kc][0] {
+			case xkNumLock:
+				numLock |= 1 << uint(mod)
+			case xkScrollLock:
+				scrollLock |= 1 << uint(mod)
+			}
+		}
+	}
+	return numLock, scrollLock
+}
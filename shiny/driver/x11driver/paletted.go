@@ -0,0 +1,100 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// palettedBufferImpl is a screen.PalettedBuffer. Its pixels are converted
+// from palette indexes to premultiplied BGRA, in the embedded bufferImpl's
+// shared memory, just before each upload.
+type palettedBufferImpl struct {
+	*bufferImpl
+
+	paletted image.Paletted
+
+	mu      sync.Mutex
+	palette [256]color.RGBA
+}
+
+func (s *screenImpl) NewPalettedBuffer(size image.Point, p color.Palette) (screen.PalettedBuffer, error) {
+	b, err := s.NewBuffer(size)
+	if err != nil {
+		return nil, err
+	}
+	return newPalettedBuffer(b.(*bufferImpl), p), nil
+}
+
+func newPalettedBuffer(b *bufferImpl, p color.Palette) *palettedBufferImpl {
+	pb := &palettedBufferImpl{
+		bufferImpl: b,
+		paletted: image.Paletted{
+			Pix:    make([]uint8, b.size.X*b.size.Y),
+			Stride: b.size.X,
+			Rect:   image.Rectangle{Max: b.size},
+		},
+	}
+	pb.SetPalette(p)
+	b.convert = pb.convert
+	return pb
+}
+
+func (b *palettedBufferImpl) Paletted() *image.Paletted { return &b.paletted }
+
+func (b *palettedBufferImpl) SetPalette(p color.Palette) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.paletted.Palette = p
+	for i := range b.palette {
+		if i < len(p) {
+			r, g, bb, a := p[i].RGBA()
+			b.palette[i] = color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(bb >> 8), uint8(a >> 8)}
+		} else {
+			b.palette[i] = color.RGBA{}
+		}
+	}
+}
+
+// convert converts the pixels in sr from palette indexes to BGRA. It is
+// called by preUpload, while holding b.bufferImpl.mu, when the shared memory
+// is in BGRA order whether or not other uploads are in flight.
+func (b *palettedBufferImpl) convert(sr image.Rectangle) {
+	sr = sr.Intersect(b.Bounds())
+	if sr.Empty() {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dst, src := &b.rgba, &b.paletted
+	for y := sr.Min.Y; y < sr.Max.Y; y++ {
+		d := dst.Pix[dst.PixOffset(sr.Min.X, y):]
+		s := src.Pix[src.PixOffset(sr.Min.X, y):][:sr.Dx()]
+		for i, index := range s {
+			c := b.palette[index]
+			d[4*i+0] = c.B
+			d[4*i+1] = c.G
+			d[4*i+2] = c.R
+			d[4*i+3] = c.A
+		}
+	}
+}
+
+// uploadBuffer returns the bufferImpl that holds src's pixels.
+func uploadBuffer(src screen.Buffer) *bufferImpl {
+	switch src := src.(type) {
+	case *palettedBufferImpl:
+		return src.bufferImpl
+	default:
+		return src.(*bufferImpl)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPalettedOverlappingUploads(t *testing.T) {
+	size := image.Point{2, 1}
+	buf := make([]byte, 4*size.X*size.Y)
+	b := &bufferImpl{
+		buf: buf,
+		rgba: image.RGBA{
+			Pix:    buf,
+			Stride: 4 * size.X,
+			Rect:   image.Rectangle{Max: size},
+		},
+		size: size,
+	}
+	pb := newPalettedBuffer(b, color.Palette{
+		color.RGBA{0x10, 0x20, 0x30, 0xff},
+		color.RGBA{0x40, 0x50, 0x60, 0xff},
+	})
+
+	// The second upload starts while the first is in flight, so the shared
+	// memory is already in BGRA order when its pixel is converted.
+	pb.paletted.Pix[0] = 1
+	b.preUpload(image.Rect(0, 0, 1, 1))
+	pb.paletted.Pix[1] = 1
+	b.preUpload(image.Rect(1, 0, 2, 1))
+	if want := []byte{0x60, 0x50, 0x40, 0xff, 0x60, 0x50, 0x40, 0xff}; !bytes.Equal(buf, want) {
+		t.Errorf("during uploads: got %#x, want %#x", buf, want)
+	}
+
+	b.postUpload()
+	b.postUpload()
+	if want := []byte{0x40, 0x50, 0x60, 0xff, 0x40, 0x50, 0x60, 0xff}; !bytes.Equal(buf, want) {
+		t.Errorf("after uploads: got %#x, want %#x", buf, want)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// TODO: use the XInput2 "libinput Accel Profile Enabled" device property,
+// which affects just the one device, instead of the core protocol's
+// ChangePointerControl request, once github.com/BurntSushi/xgb supports
+// XInput2. Until then, acceleration is turned off for the whole X11 server,
+// but only while a window that asked for it has the keyboard focus.
+
+// pointerControl is the pointer acceleration, as set by ChangePointerControl.
+type pointerControl struct {
+	numerator, denominator, threshold int16
+}
+
+func (w *windowImpl) SetPointerAcceleration(enabled bool) error {
+	w.mu.Lock()
+	w.noPointerAccel = !enabled
+	focused := w.focused
+	w.mu.Unlock()
+
+	if !focused {
+		return nil
+	}
+	if enabled {
+		return w.s.restorePointerAccel()
+	}
+	return w.s.disablePointerAccel()
+}
+
+// handleFocus handles the window gaining or losing the keyboard focus.
+func (w *windowImpl) handleFocus(focused bool) {
+	w.mu.Lock()
+	w.focused = focused
+	noAccel := w.noPointerAccel
+	w.mu.Unlock()
+
+	if !noAccel {
+		return
+	}
+	var err error
+	if focused {
+		err = w.s.disablePointerAccel()
+	} else {
+		err = w.s.restorePointerAccel()
+	}
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+// disablePointerAccel turns pointer acceleration off, saving the previous
+// setting for restorePointerAccel.
+func (s *screenImpl) disablePointerAccel() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.savedPointerControl == nil {
+		r, err := xproto.GetPointerControl(s.xc).Reply()
+		if err != nil {
+			return fmt.Errorf("x11driver: xproto.GetPointerControl failed: %v", err)
+		}
+		s.savedPointerControl = &pointerControl{
+			numerator:   int16(r.AccelerationNumerator),
+			denominator: int16(r.AccelerationDenominator),
+			threshold:   int16(r.Threshold),
+		}
+	}
+	// An acceleration of 1/1 moves the pointer by exactly as much as the
+	// device reports.
+	err := xproto.ChangePointerControlChecked(s.xc, 1, 1, 0, true, true).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.ChangePointerControl failed: %v", err)
+	}
+	return nil
+}
+
+// restorePointerAccel restores the pointer acceleration saved by
+// disablePointerAccel. It is a no-op if acceleration is not disabled.
+func (s *screenImpl) restorePointerAccel() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pc := s.savedPointerControl
+	if pc == nil {
+		return nil
+	}
+	s.savedPointerControl = nil
+	err := xproto.ChangePointerControlChecked(s.xc, pc.numerator, pc.denominator, pc.threshold, true, true).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.ChangePointerControl failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func (w *windowImpl) OnProtocol(protocol string, handler func(data [5]uint32)) error {
+	s := w.s
+	atom, err := s.internAtom(protocol)
+	if err != nil {
+		return err
+	}
+	switch atom {
+	case s.atomWMDeleteWindow, s.atomWMTakeFocus, s.atomWMSaveYourself, s.atomNetWMPing:
+		return fmt.Errorf("x11driver: protocol %s is handled by the driver", protocol)
+	}
+
+	w.mu.Lock()
+	if handler == nil {
+		delete(w.protocols, atom)
+	} else {
+		if w.protocols == nil {
+			w.protocols = map[xproto.Atom]func([5]uint32){}
+		}
+		w.protocols[atom] = handler
+	}
+	w.mu.Unlock()
+
+	w.setProtocols()
+	return nil
+}
+
+// handleProtocol handles a WM_PROTOCOLS client message that is not one of
+// the protocols that the run loop handles itself.
+func (w *windowImpl) handleProtocol(ev xproto.ClientMessageEvent) {
+	protocol := xproto.Atom(ev.Data.Data32[0])
+	if protocol == w.s.atomNetWMPing {
+		// Reply by sending the message back to the root window, per the
+		// EWMH, so that the window manager knows that we aren't hung.
+		ev.Window = w.s.xsi.Root
+		xproto.SendEvent(w.s.xc, false, w.s.xsi.Root,
+			xproto.EventMaskSubstructureNotify|xproto.EventMaskSubstructureRedirect,
+			string(ev.Bytes()))
+		return
+	}
+
+	w.mu.Lock()
+	handler := w.protocols[protocol]
+	w.mu.Unlock()
+
+	if handler != nil {
+		var data [5]uint32
+		copy(data[:], ev.Data.Data32)
+		handler(data)
+	}
+}
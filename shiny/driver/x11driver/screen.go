@@ -9,12 +9,21 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"io"
 	"log"
+	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/randr"
 	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/screensaver"
+	"github.com/BurntSushi/xgb/shape"
 	"github.com/BurntSushi/xgb/shm"
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xinerama"
 	"github.com/BurntSushi/xgb/xproto"
 
 	"golang.org/x/exp/shiny/driver/internal/x11key"
@@ -33,18 +42,150 @@ type screenImpl struct {
 	xsi     *xproto.ScreenInfo
 	keysyms x11key.KeysymTable
 
-	atomNETWMName      xproto.Atom
-	atomUTF8String     xproto.Atom
-	atomWMDeleteWindow xproto.Atom
-	atomWMProtocols    xproto.Atom
-	atomWMTakeFocus    xproto.Atom
-	atomNetWMName      xproto.Atom
-	cursorCache        map[screen.Cursor]xproto.Cursor
+	// keyboardMapping is every keysym of every keycode, modifierMapping is
+	// the keycodes of each modifier, and pointerMapping is the logical button
+	// of each physical pointer button. They are guarded by mu.
+	keyboardMapping [][]uint32
+	modifierMapping [8][]uint8
+	pointerMapping  []byte
+
+	atomNETWMName                   xproto.Atom
+	atomUTF8String                  xproto.Atom
+	atomWMDeleteWindow              xproto.Atom
+	atomWMProtocols                 xproto.Atom
+	atomWMTakeFocus                 xproto.Atom
+	atomNetWMName                   xproto.Atom
+	atomSMClientID                  xproto.Atom
+	atomWMClientLeader              xproto.Atom
+	atomWMCommand                   xproto.Atom
+	atomWMSaveYourself              xproto.Atom
+	atomGTKFrameExtents             xproto.Atom
+	atomNetWMBypassComp             xproto.Atom
+	atomNetWMState                  xproto.Atom
+	atomNetWMStateModal             xproto.Atom
+	atomXdndAware                   xproto.Atom
+	atomXdndSelection               xproto.Atom
+	atomXdndTypeList                xproto.Atom
+	atomXdndEnter                   xproto.Atom
+	atomXdndPosition                xproto.Atom
+	atomXdndStatus                  xproto.Atom
+	atomXdndLeave                   xproto.Atom
+	atomXdndDrop                    xproto.Atom
+	atomXdndFinished                xproto.Atom
+	atomXdndActionCopy              xproto.Atom
+	atomNetWMWindowType             xproto.Atom
+	atomNetWMWindowTypeNotification xproto.Atom
+	atomNetWMWindowTypeTooltip      xproto.Atom
+	atomNetWMUserTime               xproto.Atom
+	atomNetWMWindowTypeUtility      xproto.Atom
+	atomNetWMStateHidden            xproto.Atom
+	atomNetWMPID                    xproto.Atom
+	atomNetFrameExtents             xproto.Atom
+	atomNetWMStateMaximizedVert     xproto.Atom
+	atomNetWMStateMaximizedHorz     xproto.Atom
+	atomNetWMWindowOpacity          xproto.Atom
+	atomNetWMStateFullscreen        xproto.Atom
+	atomNetWMFullscreenMonitors     xproto.Atom
+	atomNetWMCMS                    xproto.Atom
+	atomNetWMDesktop                xproto.Atom
+	atomNetCurrentDesktop           xproto.Atom
+	atomNetWMPing                   xproto.Atom
+	atomNetWorkarea                 xproto.Atom
+	atomNetActiveWindow             xproto.Atom
+	atomNetSupported                xproto.Atom
+	atomXEmbed                      xproto.Atom
+	atomXEmbedInfo                  xproto.Atom
+	atomTargets                     xproto.Atom
+	atomShinySelection              xproto.Atom
+	atomIncr                        xproto.Atom
+	atomAbsPressure                 xproto.Atom
+	atomAbsTiltX                    xproto.Atom
+	atomAbsTiltY                    xproto.Atom
+	cursorCache                     map[screen.Cursor]xproto.Cursor
+
+	// hasRandR is whether the X11 server supports RandR 1.3 or later.
+	hasRandR bool
+
+	// hasXFixes is whether the X11 server supports the XFixes extension,
+	// used to track the compositing manager's selection.
+	hasXFixes bool
+
+	// hasXFixes5 is whether the X11 server supports XFixes 5.0 or later,
+	// which adds pointer barriers.
+	hasXFixes5 bool
+
+	// xinput is the XInput extension, if the X11 server supports XInput2,
+	// and its minor version is 2.xiMinor. Otherwise, xinput.major is zero.
+	xinput  extension
+	xiMinor uint16
+
+	// barriers are the pointer barriers created by CreatePointerBarrier
+	// and not yet released. It is guarded by mu.
+	barriers map[xfixes.Barrier]*barrierImpl
+
+	// devices are the enabled XInput2 input devices, by device ID. It is
+	// guarded by mu.
+	devices map[uint16]*xiDevice
+
+	// hasScreenSaver is whether the X11 server supports the MIT-SCREEN-SAVER
+	// extension, used to query the user's idle time.
+	hasScreenSaver bool
+
+	// hasShape is whether the X11 server supports the SHAPE extension, used
+	// to make overlay windows ignore input.
+	hasShape bool
+
+	// hasXinerama is whether the X11 server supports the XINERAMA extension,
+	// whose monitor numbering _NET_WM_FULLSCREEN_MONITORS uses.
+	hasXinerama bool
+
+	// hasCompositor is whether a compositing manager is running. It is
+	// guarded by mu.
+	hasCompositor bool
+
+	// savedPointerControl, if non-nil, is the pointer acceleration to
+	// restore after disablePointerAccel. It is guarded by mu.
+	savedPointerControl *pointerControl
+
+	// xkb is the XKB extension, if hasXKB is true. savedKeyRepeat, if
+	// non-nil, is the key repeat setting to restore after SetKeyRepeat. It
+	// is guarded by mu.
+	xkb            extension
+	hasXKB         bool
+	savedKeyRepeat *keyRepeat
+
+	// syncExt is the SYNC extension and syncServerTime is its SERVERTIME
+	// counter, if syncServerTime is non-zero. alarms maps each SYNC alarm
+	// created by NewAlarm to its channel. It is guarded by mu.
+	syncExt        extension
+	syncServerTime uint32
+	alarms         map[uint32]chan time.Time
+
+	// errors is the channel returned by Errors.
+	errors chan error
+
+	// rawEventHandler is the handler set by SetRawEventHandler. It is
+	// guarded by mu.
+	rawEventHandler func(ev interface{}) bool
+
+	// hasPDFOps is whether the X11 server supports X Render 0.11 or later,
+	// which adds the PDF separable blend operators such as PictOpMultiply.
+	hasPDFOps bool
 
 	pixelsPerPt  float32
 	pictformat24 render.Pictformat
 	pictformat32 render.Pictformat
 
+	// pictformat32BGR is like pictformat32 but with the red and blue channels
+	// swapped. It is zero if the X11 server does not provide such a format.
+	pictformat32BGR render.Pictformat
+
+	// defaultCursorSize and cursorSize are the default and current size, in
+	// pixels, of cursors set by Window.SetCursorImage. cursorSize is guarded
+	// by mu.
+	defaultCursorSize int
+	cursorSize        int
+
 	// window32 and its related X11 resources is an unmapped window so that we
 	// have a depth-32 window to create depth-32 pixmaps from, i.e. pixmaps
 	// with an alpha channel. The root window isn't guaranteed to be depth-32.
@@ -58,10 +199,35 @@ type screenImpl struct {
 	uniformC  render.Color
 	uniformP  render.Picture
 
+	// alphaP is a solid fill picture of alpha alphaA, used as the mask for
+	// DrawOptions.Transparency. They are guarded by alphaMu.
+	alphaMu sync.Mutex
+	alphaA  uint16
+	alphaP  render.Picture
+
+	// scratchBuf and scratchTex are reused by Window.DrawImage calls, which
+	// hold scratchMu while using them.
+	scratchMu  sync.Mutex
+	scratchBuf screen.Buffer
+	scratchTex screen.Texture
+
+	// selectionMu serializes convertSelection calls.
+	selectionMu sync.Mutex
+
 	mu              sync.Mutex
 	buffers         map[shm.Seg]*bufferImpl
 	uploads         map[uint16]chan struct{}
 	windows         map[xproto.Window]*windowImpl
+	embedded        map[xproto.Window]*windowImpl
+	displays        []*displayImpl
+	pickColor       chan image.Point
+	drag            *dragState
+	selectionReply  chan xproto.SelectionNotifyEvent
+	clipboards      map[xproto.Atom]*clipboardData
+	incrs           map[incrKey]*incrTransfer
+	origModes       map[randr.Crtc]randr.Mode
+	sessionID       string
+	idleThreshold   chan time.Duration
 	nPendingUploads int
 	completionKeys  []uint16
 }
@@ -73,6 +239,9 @@ func newScreenImpl(xc *xgb.Conn) (*screenImpl, error) {
 		buffers: map[shm.Seg]*bufferImpl{},
 		uploads: map[uint16]chan struct{}{},
 		windows: map[xproto.Window]*windowImpl{},
+		errors:  make(chan error, 64),
+
+		origModes: map[randr.Crtc]randr.Mode{},
 	}
 	if err := s.initAtoms(); err != nil {
 		return nil, err
@@ -83,18 +252,37 @@ func newScreenImpl(xc *xgb.Conn) (*screenImpl, error) {
 	if err := s.initKeyboardMapping(); err != nil {
 		return nil, err
 	}
+	if err := s.initModifierMapping(); err != nil {
+		return nil, err
+	}
+	if err := s.initPointerMapping(); err != nil {
+		return nil, err
+	}
+	if err := s.initRandR(); err != nil {
+		return nil, err
+	}
+	s.initXFixes()
+	s.initXInput()
+	s.initXKB()
+	s.initSync()
+	s.hasScreenSaver = screensaver.Init(xc) == nil
+	s.hasShape = shape.Init(xc) == nil
+	s.hasXinerama = xinerama.Init(xc) == nil
 	const (
 		mmPerInch = 25.4
 		ptPerInch = 72
 	)
 	pixelsPerMM := float32(s.xsi.WidthInPixels) / float32(s.xsi.WidthInMillimeters)
 	s.pixelsPerPt = pixelsPerMM * mmPerInch / ptPerInch
+	s.defaultCursorSize = s.queryCursorSize()
+	s.cursorSize = s.defaultCursorSize
 	if err := s.initPictformats(); err != nil {
 		return nil, err
 	}
 	if err := s.initWindow32(); err != nil {
 		return nil, err
 	}
+	s.initCompositor()
 
 	var err error
 	s.opaqueP, err = render.NewPictureId(xc)
@@ -112,6 +300,11 @@ func newScreenImpl(xc *xgb.Conn) (*screenImpl, error) {
 		Alpha: 0xffff,
 	})
 	render.CreateSolidFill(s.xc, s.uniformP, render.Color{})
+	s.alphaP, err = render.NewPictureId(xc)
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.NewPictureId failed: %v", err)
+	}
+	render.CreateSolidFill(s.xc, s.alphaP, render.Color{})
 
 	go s.run()
 	return s, nil
@@ -121,7 +314,14 @@ func (s *screenImpl) run() {
 	for {
 		ev, err := s.xc.WaitForEvent()
 		if err != nil {
-			log.Printf("x11driver: xproto.WaitForEvent: %v", err)
+			s.reportError(err)
+			continue
+		}
+
+		s.mu.Lock()
+		handler := s.rawEventHandler
+		s.mu.Unlock()
+		if handler != nil && handler(ev) {
 			continue
 		}
 
@@ -131,6 +331,12 @@ func (s *screenImpl) run() {
 			s.mu.Lock()
 			delete(s.windows, ev.Window)
 			s.mu.Unlock()
+			s.handleEmbeddedGone(ev.Window)
+
+		case xproto.ReparentNotifyEvent:
+			if w := s.embedder(ev.Window); w != nil && ev.Parent != w.xw {
+				s.handleEmbeddedGone(ev.Window)
+			}
 
 		case shm.CompletionEvent:
 			s.mu.Lock()
@@ -138,23 +344,66 @@ func (s *screenImpl) run() {
 			s.handleCompletions()
 			s.mu.Unlock()
 
+		case randr.ScreenChangeNotifyEvent, randr.NotifyEvent:
+			s.handleDisplayChange()
+
 		case xproto.ClientMessageEvent:
+			if s.handleXdnd(ev) {
+				break
+			}
 			if ev.Type != s.atomWMProtocols || ev.Format != 32 {
 				break
 			}
 			switch xproto.Atom(ev.Data.Data32[0]) {
 			case s.atomWMDeleteWindow:
 				if w := s.findWindow(ev.Window); w != nil {
+					if w.preventClose {
+						break
+					}
 					w.lifecycler.SetDead(true)
-					w.lifecycler.SendEvent(w, nil)
+					w.lifecycler.SendEvent(poster{w}, nil)
 				} else {
 					noWindowFound = true
 				}
 			case s.atomWMTakeFocus:
+				if w := s.findWindow(ev.Window); w != nil && w.noFocus {
+					break
+				}
 				xproto.SetInputFocus(s.xc, xproto.InputFocusParent, ev.Window, xproto.Timestamp(ev.Data.Data32[1]))
+			case s.atomWMSaveYourself:
+				if w := s.findWindow(ev.Window); w != nil {
+					s.handleSaveYourself(w)
+				} else {
+					noWindowFound = true
+				}
+			default:
+				if w := s.findWindow(ev.Window); w != nil {
+					w.handleProtocol(ev)
+				} else {
+					noWindowFound = true
+				}
+			}
+
+		case xproto.SelectionRequestEvent:
+			if s.handleDragSelectionRequest(ev) {
+				break
+			}
+			s.handleClipboardRequest(ev)
+
+		case xproto.SelectionNotifyEvent:
+			s.handleSelectionReply(ev)
+
+		case xproto.SelectionClearEvent:
+			if w := s.findWindow(ev.Owner); w != nil {
+				w.handleSelectionClear(ev.Selection)
+			} else {
+				noWindowFound = true
 			}
 
 		case xproto.ConfigureNotifyEvent:
+			if s.embedder(ev.Window) != nil {
+				break
+			}
 			if w := s.findWindow(ev.Window); w != nil {
 				w.handleConfigureNotify(ev)
 			} else {
@@ -176,24 +425,63 @@ func (s *screenImpl) run() {
 				noWindowFound = true
 			}
 
+		case xproto.VisibilityNotifyEvent:
+			if w := s.findWindow(ev.Window); w != nil {
+				w.handleVisibilityNotify(ev)
+			} else {
+				noWindowFound = true
+			}
+
+		case xproto.PropertyNotifyEvent:
+			if s.handleEmbeddedProperty(ev) || s.handleIncrProperty(ev) {
+				break
+			}
+			if w := s.findWindow(ev.Window); w != nil {
+				w.handlePropertyNotify(ev)
+			} else {
+				noWindowFound = true
+			}
+
 		case xproto.FocusInEvent:
 			if w := s.findWindow(ev.Event); w != nil {
+				w.handleFocus(true)
+				w.activateEmbedded(true)
 				w.lifecycler.SetFocused(true)
-				w.lifecycler.SendEvent(w, nil)
+				w.lifecycler.SendEvent(poster{w}, nil)
 			} else {
 				noWindowFound = true
 			}
 
 		case xproto.FocusOutEvent:
 			if w := s.findWindow(ev.Event); w != nil {
+				w.handleFocus(false)
+				w.activateEmbedded(false)
 				w.lifecycler.SetFocused(false)
-				w.lifecycler.SendEvent(w, nil)
+				w.lifecycler.SendEvent(poster{w}, nil)
 			} else {
 				noWindowFound = true
 			}
 
+		case xfixes.SelectionNotifyEvent:
+			s.handleSelectionNotify(ev)
+
+		case syncAlarmNotifyEvent:
+			s.handleAlarmNotify(ev)
+
+		case xgeEvent:
+			if s.xinput.major != 0 && ev.extension == s.xinput.major {
+				s.handleXIEvent(ev)
+			}
+
+		case xproto.MappingNotifyEvent:
+			s.handleMappingNotify(ev)
+
 		case xproto.KeyPressEvent:
 			if w := s.findWindow(ev.Event); w != nil {
+				w.setLastInputTime(ev.Time)
+				if w.forwardKey(ev, false) {
+					break
+				}
 				w.handleKey(ev.Detail, ev.State, key.DirPress)
 			} else {
 				noWindowFound = true
@@ -201,27 +489,44 @@ func (s *screenImpl) run() {
 
 		case xproto.KeyReleaseEvent:
 			if w := s.findWindow(ev.Event); w != nil {
+				w.setLastInputTime(ev.Time)
+				if w.forwardKey(xproto.KeyPressEvent(ev), true) {
+					break
+				}
 				w.handleKey(ev.Detail, ev.State, key.DirRelease)
 			} else {
 				noWindowFound = true
 			}
 
 		case xproto.ButtonPressEvent:
+			if s.handlePickColor(ev) {
+				break
+			}
 			if w := s.findWindow(ev.Event); w != nil {
+				w.setLastInputTime(ev.Time)
 				w.handleMouse(ev.EventX, ev.EventY, ev.Detail, ev.State, mouse.DirPress)
 			} else {
 				noWindowFound = true
 			}
 
 		case xproto.ButtonReleaseEvent:
+			if s.handleDragRelease(ev) {
+				break
+			}
 			if w := s.findWindow(ev.Event); w != nil {
+				w.setLastInputTime(ev.Time)
 				w.handleMouse(ev.EventX, ev.EventY, ev.Detail, ev.State, mouse.DirRelease)
+				w.handleButtonGrab(ev.Detail)
 			} else {
 				noWindowFound = true
 			}
 
 		case xproto.MotionNotifyEvent:
+			if s.handleDragMotion(ev) {
+				break
+			}
 			if w := s.findWindow(ev.Event); w != nil {
+				w.setLastInputTime(ev.Time)
 				w.handleMouse(ev.EventX, ev.EventY, 0, ev.State, mouse.DirNone)
 			} else {
 				noWindowFound = true
@@ -234,6 +539,12 @@ func (s *screenImpl) run() {
 	}
 }
 
+func (s *screenImpl) SetRawEventHandler(handler func(ev interface{}) bool) {
+	s.mu.Lock()
+	s.rawEventHandler = handler
+	s.mu.Unlock()
+}
+
 // TODO: is findBuffer and the s.buffers field unused? Delete?
 
 func (s *screenImpl) findBuffer(key shm.Seg) *bufferImpl {
@@ -365,15 +676,67 @@ func (s *screenImpl) NewTexture(size image.Point) (screen.Texture, error) {
 	}, nil
 }
 
+func (s *screenImpl) NewRenderTarget(size image.Point) (screen.RenderTarget, error) {
+	t, err := s.NewTexture(size)
+	if err != nil {
+		return nil, err
+	}
+	return t.(*textureImpl), nil
+}
+
+func (s *screenImpl) NewTextureFromReader(r io.Reader) (screen.Texture, error) {
+	m, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: image.Decode failed: %v", err)
+	}
+	size := m.Bounds().Size()
+
+	b, err := s.NewBuffer(size)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Release()
+	draw.Draw(b.RGBA(), b.Bounds(), m, m.Bounds().Min, draw.Src)
+
+	t, err := s.NewTexture(size)
+	if err != nil {
+		return nil, err
+	}
+	t.Upload(image.Point{}, b, b.Bounds())
+	return t, nil
+}
+
 func (s *screenImpl) NewWindow(opts *screen.NewWindowOptions) (screen.Window, error) {
 	width, height := 1024, 768
+	var (
+		unthrottled, preventClose, reportDIP, noFocus, overlay, tooltip, hidden bool
+		x, y                                                                    int
+		geometry                                                                *screen.GeometrySpec
+		fadeIn                                                                  time.Duration
+	)
 	if opts != nil {
+		unthrottled = opts.Unthrottled
+		preventClose = opts.PreventClose
+		reportDIP = opts.ReportDIP
+		noFocus = opts.NoFocus
+		fadeIn = opts.FadeIn
+		hidden = opts.Hidden
+		if hidden {
+			fadeIn = 0
+		}
+		if opts.Overlay {
+			overlay, tooltip, x, y = true, opts.Tooltip, opts.X, opts.Y
+		}
 		if opts.Width > 0 {
 			width = opts.Width
 		}
 		if opts.Height > 0 {
 			height = opts.Height
 		}
+		if opts.Geometry != nil && !overlay {
+			geometry = opts.Geometry
+			x, y = s.placeGeometry(geometry, width, height)
+		}
 	}
 
 	xw, err := xproto.NewWindowId(s.xc)
@@ -384,57 +747,166 @@ func (s *screenImpl) NewWindow(opts *screen.NewWindowOptions) (screen.Window, er
 	if err != nil {
 		return nil, fmt.Errorf("x11driver: xproto.NewGcontextId failed: %v", err)
 	}
-	xp, err := render.NewPictureId(s.xc)
+	frontG, err := xproto.NewGcontextId(s.xc)
 	if err != nil {
-		return nil, fmt.Errorf("x11driver: render.NewPictureId failed: %v", err)
+		return nil, fmt.Errorf("x11driver: xproto.NewGcontextId failed: %v", err)
+	}
+	visual, depth := s.xsi.RootVisual, s.xsi.RootDepth
+	if opts != nil && opts.VisualID != 0 {
+		visual = xproto.Visualid(opts.VisualID)
+		if depth, err = s.visualDepth(visual); err != nil {
+			return nil, err
+		}
 	}
 	pictformat := render.Pictformat(0)
-	switch s.xsi.RootDepth {
+	switch depth {
 	default:
-		return nil, fmt.Errorf("x11driver: unsupported root depth %d", s.xsi.RootDepth)
+		return nil, fmt.Errorf("x11driver: unsupported root depth %d", depth)
 	case 24:
 		pictformat = s.pictformat24
 	case 32:
 		pictformat = s.pictformat32
 	}
+	// A window whose visual differs from its parent's needs its own
+	// colormap, and a border pixel if its depth differs.
+	var colormap xproto.Colormap
+	if visual != s.xsi.RootVisual {
+		colormap, err = xproto.NewColormapId(s.xc)
+		if err != nil {
+			return nil, fmt.Errorf("x11driver: xproto.NewColormapId failed: %v", err)
+		}
+		if err := xproto.CreateColormapChecked(
+			s.xc, xproto.ColormapAllocNone, colormap, s.xsi.Root, visual).Check(); err != nil {
+			return nil, fmt.Errorf("x11driver: xproto.CreateColormap failed: %v", err)
+		}
+	}
 
 	w := &windowImpl{
 		s:       s,
 		xw:      xw,
 		xg:      xg,
-		xp:      xp,
 		xevents: make(chan xgb.Event),
+
+		frontG:     frontG,
+		pictformat: pictformat,
+		depth:      depth,
+		colormap:   colormap,
+		wantSize:   image.Point{width, height},
+
+		unthrottled:  unthrottled,
+		preventClose: preventClose,
+		reportDIP:    reportDIP,
+		noFocus:      noFocus,
+	}
+	if opts != nil {
+		w.SetLimit(opts.MaxQueuedEvents, opts.QueueOverflow)
+		w.resizeDebounce = opts.ResizeDebounce
 	}
 
 	s.mu.Lock()
 	s.windows[xw] = w
 	s.mu.Unlock()
 
-	w.lifecycler.SendEvent(w, nil)
+	w.lifecycler.SendEvent(poster{w}, nil)
 
-	xproto.CreateWindow(s.xc, s.xsi.RootDepth, xw, s.xsi.Root,
-		0, 0, uint16(width), uint16(height), 0,
-		xproto.WindowClassInputOutput, s.xsi.RootVisual,
-		xproto.CwEventMask,
-		[]uint32{0 |
+	// Overlay windows are override-redirect, so that the window manager
+	// neither decorates nor moves them.
+	overrideRedirect := uint32(0)
+	if overlay {
+		overrideRedirect = 1
+	}
+	mask := uint32(xproto.CwBitGravity | xproto.CwOverrideRedirect | xproto.CwEventMask)
+	values := []uint32{
+		// Keeping the window contents on resize, instead of clearing
+		// them, avoids flicker until the next frame is published.
+		xproto.GravityNorthWest,
+		overrideRedirect,
+		0 |
 			xproto.EventMaskKeyPress |
 			xproto.EventMaskKeyRelease |
 			xproto.EventMaskButtonPress |
 			xproto.EventMaskButtonRelease |
 			xproto.EventMaskPointerMotion |
 			xproto.EventMaskExposure |
+			xproto.EventMaskVisibilityChange |
 			xproto.EventMaskStructureNotify |
+			xproto.EventMaskPropertyChange |
 			xproto.EventMaskFocusChange,
-		},
-	)
-	s.setProperty(xw, s.atomWMProtocols, s.atomWMDeleteWindow, s.atomWMTakeFocus)
+	}
+	if colormap != 0 {
+		// The values are in the order of their mask bits, and CwBorderPixel
+		// comes before CwBitGravity.
+		mask |= xproto.CwBorderPixel | xproto.CwColormap
+		values = append([]uint32{0}, append(values, uint32(colormap))...)
+	}
+	xproto.CreateWindow(s.xc, depth, xw, s.xsi.Root,
+		int16(x), int16(y), uint16(width), uint16(height), 0,
+		xproto.WindowClassInputOutput, visual, mask, values)
+	if s.hasXInput2(4) {
+		if err := s.selectXIEvents(xw, xiAllMasterDevices,
+			xiGesturePinchBegin, xiGesturePinchUpdate, xiGesturePinchEnd,
+			xiGestureSwipeBegin, xiGestureSwipeUpdate, xiGestureSwipeEnd); err != nil {
+			log.Print(err)
+		}
+	}
+	if s.xinput.major != 0 {
+		s.selectStylus(xw, s.tablets())
+	}
+	if overlay {
+		if tooltip {
+			s.setProperty(xw, s.atomNetWMWindowType, s.atomNetWMWindowTypeTooltip)
+		} else {
+			s.setProperty(xw, s.atomNetWMWindowType, s.atomNetWMWindowTypeNotification)
+		}
+		if s.hasShape && !tooltip {
+			// An empty input shape lets input pass through to the windows
+			// below.
+			shape.Rectangles(s.xc, shape.SoSet, shape.SkInput, xproto.ClipOrderingUnsorted, xw, 0, 0, nil)
+		}
+	}
+	if geometry != nil {
+		w.setGeometry(geometry, x, y, width, height)
+	}
+	w.setProtocols()
+	if noFocus && !overlay {
+		w.setNoFocus()
+	}
+	s.setProperty32(xw, s.atomWMClientLeader, xproto.AtomWindow, uint32(s.window32))
+	// Per the EWMH spec, _NET_WM_PID must only be set along with
+	// WM_CLIENT_MACHINE, as a PID is meaningless without the host.
+	if hostname, err := os.Hostname(); err == nil {
+		s.setStringProperty(xw, xproto.AtomWmClientMachine, xproto.AtomString, hostname)
+		s.setProperty32(xw, s.atomNetWMPID, xproto.AtomCardinal, uint32(os.Getpid()))
+	}
 
 	title := []byte(opts.GetTitle())
 	xproto.ChangeProperty(s.xc, xproto.PropModeReplace, xw, s.atomNETWMName, s.atomUTF8String, 8, uint32(len(title)), title)
 
-	xproto.CreateGC(s.xc, xg, xproto.Drawable(xw), 0, nil)
-	render.CreatePicture(s.xc, xp, xproto.Drawable(xw), pictformat, 0, nil)
-	xproto.MapWindow(s.xc, xw)
+	xproto.CreateGC(s.xc, xg, xproto.Drawable(xw), xproto.GcGraphicsExposures, []uint32{0})
+	xproto.CreateGC(s.xc, frontG, xproto.Drawable(xw), xproto.GcGraphicsExposures, []uint32{0})
+	back, err := s.newBackBuffer(xw, depth, pictformat, w.wantSize)
+	if err != nil {
+		xproto.DestroyWindow(s.xc, xw)
+		if colormap != 0 {
+			xproto.FreeColormap(s.xc, colormap)
+		}
+		s.mu.Lock()
+		delete(s.windows, xw)
+		s.mu.Unlock()
+		return nil, err
+	}
+	w.mu.Lock()
+	w.back = back
+	w.mu.Unlock()
+	if fadeIn > 0 {
+		w.setOpacity(0)
+	}
+	if !hidden {
+		xproto.MapWindow(s.xc, xw)
+	}
+	if fadeIn > 0 {
+		go w.fade(0, 1, fadeIn)
+	}
 
 	return w, nil
 }
@@ -464,6 +936,186 @@ func (s *screenImpl) initAtoms() (err error) {
 	if err != nil {
 		return err
 	}
+	s.atomSMClientID, err = s.internAtom("SM_CLIENT_ID")
+	if err != nil {
+		return err
+	}
+	s.atomWMClientLeader, err = s.internAtom("WM_CLIENT_LEADER")
+	if err != nil {
+		return err
+	}
+	s.atomWMCommand, err = s.internAtom("WM_COMMAND")
+	if err != nil {
+		return err
+	}
+	s.atomWMSaveYourself, err = s.internAtom("WM_SAVE_YOURSELF")
+	if err != nil {
+		return err
+	}
+	s.atomGTKFrameExtents, err = s.internAtom("_GTK_FRAME_EXTENTS")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMBypassComp, err = s.internAtom("_NET_WM_BYPASS_COMPOSITOR")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMState, err = s.internAtom("_NET_WM_STATE")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMStateModal, err = s.internAtom("_NET_WM_STATE_MODAL")
+	if err != nil {
+		return err
+	}
+	s.atomXdndAware, err = s.internAtom("XdndAware")
+	if err != nil {
+		return err
+	}
+	s.atomXdndSelection, err = s.internAtom("XdndSelection")
+	if err != nil {
+		return err
+	}
+	s.atomXdndTypeList, err = s.internAtom("XdndTypeList")
+	if err != nil {
+		return err
+	}
+	s.atomXdndEnter, err = s.internAtom("XdndEnter")
+	if err != nil {
+		return err
+	}
+	s.atomXdndPosition, err = s.internAtom("XdndPosition")
+	if err != nil {
+		return err
+	}
+	s.atomXdndStatus, err = s.internAtom("XdndStatus")
+	if err != nil {
+		return err
+	}
+	s.atomXdndLeave, err = s.internAtom("XdndLeave")
+	if err != nil {
+		return err
+	}
+	s.atomXdndDrop, err = s.internAtom("XdndDrop")
+	if err != nil {
+		return err
+	}
+	s.atomXdndFinished, err = s.internAtom("XdndFinished")
+	if err != nil {
+		return err
+	}
+	s.atomXdndActionCopy, err = s.internAtom("XdndActionCopy")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMWindowType, err = s.internAtom("_NET_WM_WINDOW_TYPE")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMWindowTypeNotification, err = s.internAtom("_NET_WM_WINDOW_TYPE_NOTIFICATION")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMWindowTypeTooltip, err = s.internAtom("_NET_WM_WINDOW_TYPE_TOOLTIP")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMUserTime, err = s.internAtom("_NET_WM_USER_TIME")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMWindowTypeUtility, err = s.internAtom("_NET_WM_WINDOW_TYPE_UTILITY")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMStateHidden, err = s.internAtom("_NET_WM_STATE_HIDDEN")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMPID, err = s.internAtom("_NET_WM_PID")
+	if err != nil {
+		return err
+	}
+	s.atomNetFrameExtents, err = s.internAtom("_NET_FRAME_EXTENTS")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMStateMaximizedVert, err = s.internAtom("_NET_WM_STATE_MAXIMIZED_VERT")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMStateMaximizedHorz, err = s.internAtom("_NET_WM_STATE_MAXIMIZED_HORZ")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMWindowOpacity, err = s.internAtom("_NET_WM_WINDOW_OPACITY")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMStateFullscreen, err = s.internAtom("_NET_WM_STATE_FULLSCREEN")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMFullscreenMonitors, err = s.internAtom("_NET_WM_FULLSCREEN_MONITORS")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMDesktop, err = s.internAtom("_NET_WM_DESKTOP")
+	if err != nil {
+		return err
+	}
+	s.atomNetCurrentDesktop, err = s.internAtom("_NET_CURRENT_DESKTOP")
+	if err != nil {
+		return err
+	}
+	s.atomNetWMPing, err = s.internAtom("_NET_WM_PING")
+	if err != nil {
+		return err
+	}
+	s.atomNetWorkarea, err = s.internAtom("_NET_WORKAREA")
+	if err != nil {
+		return err
+	}
+	s.atomNetActiveWindow, err = s.internAtom("_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return err
+	}
+	s.atomNetSupported, err = s.internAtom("_NET_SUPPORTED")
+	if err != nil {
+		return err
+	}
+	s.atomXEmbed, err = s.internAtom("_XEMBED")
+	if err != nil {
+		return err
+	}
+	s.atomXEmbedInfo, err = s.internAtom("_XEMBED_INFO")
+	if err != nil {
+		return err
+	}
+	s.atomTargets, err = s.internAtom("TARGETS")
+	if err != nil {
+		return err
+	}
+	s.atomShinySelection, err = s.internAtom("_SHINY_SELECTION")
+	if err != nil {
+		return err
+	}
+	s.atomIncr, err = s.internAtom("INCR")
+	if err != nil {
+		return err
+	}
+	s.atomAbsPressure, err = s.internAtom("Abs Pressure")
+	if err != nil {
+		return err
+	}
+	s.atomAbsTiltX, err = s.internAtom("Abs Tilt X")
+	if err != nil {
+		return err
+	}
+	s.atomAbsTiltY, err = s.internAtom("Abs Tilt Y")
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -478,6 +1130,30 @@ func (s *screenImpl) internAtom(name string) (xproto.Atom, error) {
 	return r.Atom, nil
 }
 
+func (s *screenImpl) atomName(atom xproto.Atom) (string, error) {
+	r, err := xproto.GetAtomName(s.xc, atom).Reply()
+	if err != nil {
+		return "", fmt.Errorf("x11driver: xproto.GetAtomName failed: %v", err)
+	}
+	if r == nil {
+		return "", fmt.Errorf("x11driver: xproto.GetAtomName failed")
+	}
+	return r.Name, nil
+}
+
+func (s *screenImpl) initXFixes() {
+	if err := xfixes.Init(s.xc); err != nil {
+		return
+	}
+	// The XFixes protocol requires that QueryVersion is the first request.
+	r, err := xfixes.QueryVersion(s.xc, 5, 0).Reply()
+	if err != nil {
+		return
+	}
+	s.hasXFixes = true
+	s.hasXFixes5 = r.MajorVersion >= 5
+}
+
 func (s *screenImpl) initCursors() error {
 	xc := s.xc
 	s.cursorCache = make(map[screen.Cursor]xproto.Cursor)
@@ -542,14 +1218,31 @@ func (s *screenImpl) initKeyboardMapping() error {
 	if n < 2 {
 		return fmt.Errorf("x11driver: too few keysyms per keycode: %d", n)
 	}
+	var keysyms x11key.KeysymTable
+	mapping := make([][]uint32, keyHi+1)
 	for i := keyLo; i <= keyHi; i++ {
-		s.keysyms[i][0] = uint32(km.Keysyms[(i-keyLo)*n+0])
-		s.keysyms[i][1] = uint32(km.Keysyms[(i-keyLo)*n+1])
+		keysyms.Table[i][0] = uint32(km.Keysyms[(i-keyLo)*n+0])
+		keysyms.Table[i][1] = uint32(km.Keysyms[(i-keyLo)*n+1])
+		mapping[i] = make([]uint32, n)
+		for j := range mapping[i] {
+			mapping[i][j] = uint32(km.Keysyms[(i-keyLo)*n+j])
+		}
 	}
+	// The keysyms field is only modified here and in initModifierMapping,
+	// in the screenImpl.run goroutine after initialization, so that
+	// goroutine may read it without holding mu.
+	s.mu.Lock()
+	keysyms.SetModifiers(s.modifierMapping)
+	s.keysyms = keysyms
+	s.keyboardMapping = mapping
+	s.mu.Unlock()
 	return nil
 }
 
 func (s *screenImpl) initPictformats() error {
+	if r, err := render.QueryVersion(s.xc, 0, 11).Reply(); err == nil {
+		s.hasPDFOps = r.MajorVersion > 0 || r.MinorVersion >= 11
+	}
 	pformats, err := render.QueryPictFormats(s.xc).Reply()
 	if err != nil {
 		return fmt.Errorf("x11driver: render.QueryPictFormats failed: %v", err)
@@ -562,6 +1255,16 @@ func (s *screenImpl) initPictformats() error {
 	if err != nil {
 		return err
 	}
+	s.pictformat32BGR, _ = findPictformatDirect(pformats.Formats, 32, render.Directformat{
+		RedShift:   0,
+		RedMask:    0xff,
+		GreenShift: 8,
+		GreenMask:  0xff,
+		BlueShift:  16,
+		BlueMask:   0xff,
+		AlphaShift: 24,
+		AlphaMask:  0xff,
+	})
 	return nil
 }
 
@@ -581,6 +1284,10 @@ func findPictformat(fs []render.Pictforminfo, depth byte) (render.Pictformat, er
 		want.AlphaShift = 0
 		want.AlphaMask = 0x00
 	}
+	return findPictformatDirect(fs, depth, want)
+}
+
+func findPictformatDirect(fs []render.Pictforminfo, depth byte, want render.Directformat) (render.Pictformat, error) {
 	for _, f := range fs {
 		if f.Type == render.PictTypeDirect && f.Depth == depth && f.Direct == want {
 			return f.Id, nil
@@ -619,7 +1326,11 @@ func (s *screenImpl) initWindow32() error {
 		xproto.CwBorderPixel|xproto.CwColormap,
 		[]uint32{0, uint32(colormap)},
 	)
-	xproto.CreateGC(s.xc, s.gcontext32, xproto.Drawable(s.window32), 0, nil)
+	// Graphics exposures are disabled so that copyArea doesn't generate a
+	// NoExposure event for every copy.
+	xproto.CreateGC(s.xc, s.gcontext32, xproto.Drawable(s.window32), xproto.GcGraphicsExposures, []uint32{0})
+	// window32 is also the ICCCM client leader for all of our windows.
+	s.setProperty32(s.window32, s.atomWMClientLeader, xproto.AtomWindow, uint32(s.window32))
 	return nil
 }
 
@@ -637,7 +1348,39 @@ func findVisual(xsi *xproto.ScreenInfo, depth byte) (xproto.Visualid, error) {
 	return 0, fmt.Errorf("x11driver: no matching Visualid")
 }
 
+// visualDepth returns the depth of the visual with the given ID. It returns
+// an error unless the visual is one that NewWindow supports: a TrueColor
+// visual of depth 24 or 32 with 8 bits each of red, green and blue.
+func (s *screenImpl) visualDepth(id xproto.Visualid) (byte, error) {
+	for _, d := range s.xsi.AllowedDepths {
+		for _, v := range d.Visuals {
+			if v.VisualId != id {
+				continue
+			}
+			if (d.Depth != 24 && d.Depth != 32) || v.Class != xproto.VisualClassTrueColor ||
+				v.RedMask != 0xff0000 || v.GreenMask != 0xff00 || v.BlueMask != 0xff {
+				return 0, fmt.Errorf("x11driver: unsupported visual %#x", id)
+			}
+			return d.Depth, nil
+		}
+	}
+	return 0, fmt.Errorf("x11driver: no visual %#x on the screen", id)
+}
+
+// The setProperty, setProperty32, setStringProperty and deleteProperty
+// methods do not wait for the X11 server to reply, so that changing several
+// properties in a row does not take several round trips. Errors are reported
+// asynchronously, via the Errors channel.
+
 func (s *screenImpl) setProperty(xw xproto.Window, prop xproto.Atom, values ...xproto.Atom) {
+	u := make([]uint32, len(values))
+	for i, v := range values {
+		u[i] = uint32(v)
+	}
+	s.setProperty32(xw, prop, xproto.AtomAtom, u...)
+}
+
+func (s *screenImpl) setProperty32(xw xproto.Window, prop, typ xproto.Atom, values ...uint32) {
 	b := make([]byte, len(values)*4)
 	for i, v := range values {
 		b[4*i+0] = uint8(v >> 0)
@@ -645,7 +1388,55 @@ func (s *screenImpl) setProperty(xw xproto.Window, prop xproto.Atom, values ...x
 		b[4*i+2] = uint8(v >> 16)
 		b[4*i+3] = uint8(v >> 24)
 	}
-	xproto.ChangeProperty(s.xc, xproto.PropModeReplace, xw, prop, xproto.AtomAtom, 32, uint32(len(values)), b)
+	xproto.ChangeProperty(s.xc, xproto.PropModeReplace, xw, prop, typ, 32, uint32(len(values)), b)
+}
+
+// getProperty32 returns the value of the 32-bit property prop of type typ on
+// xw.
+func (s *screenImpl) getProperty32(xw xproto.Window, prop, typ xproto.Atom) ([]uint32, error) {
+	r, err := xproto.GetProperty(s.xc, false, xw, prop, typ, 0, 1024).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("x11driver: xproto.GetProperty failed: %v", err)
+	}
+	if r.Format != 32 {
+		return nil, nil
+	}
+	values := make([]uint32, len(r.Value)/4)
+	for i := range values {
+		values[i] = xgb.Get32(r.Value[4*i:])
+	}
+	return values, nil
+}
+
+// getStringProperty returns the value of the prop property of xw, which must
+// be of type typ. It returns false if there is no such property. A typ of
+// xproto.AtomString means that the value is Latin-1, which is converted to
+// UTF-8.
+func (s *screenImpl) getStringProperty(xw xproto.Window, prop, typ xproto.Atom) (string, bool, error) {
+	r, err := xproto.GetProperty(s.xc, false, xw, prop, typ, 0, 1<<16).Reply()
+	if err != nil {
+		return "", false, fmt.Errorf("x11driver: xproto.GetProperty failed: %v", err)
+	}
+	if r.Type != typ || r.Format != 8 {
+		return "", false, nil
+	}
+	if typ != xproto.AtomString {
+		// Other programs may have set invalid UTF-8.
+		return strings.ToValidUTF8(string(r.Value), "\uFFFD"), true, nil
+	}
+	runes := make([]rune, len(r.Value))
+	for i, c := range r.Value {
+		runes[i] = rune(c)
+	}
+	return string(runes), true, nil
+}
+
+func (s *screenImpl) setStringProperty(xw xproto.Window, prop, typ xproto.Atom, value string) {
+	xproto.ChangeProperty(s.xc, xproto.PropModeReplace, xw, prop, typ, 8, uint32(len(value)), []byte(value))
+}
+
+func (s *screenImpl) deleteProperty(xw xproto.Window, prop xproto.Atom) {
+	xproto.DeleteProperty(s.xc, xw, prop)
 }
 
 func (s *screenImpl) drawUniform(xp render.Picture, src2dst *f64.Aff3, src color.Color, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
@@ -653,8 +1444,25 @@ func (s *screenImpl) drawUniform(xp render.Picture, src2dst *f64.Aff3, src color
 		return
 	}
 
-	if opts == nil && *src2dst == (f64.Aff3{1, 0, 0, 0, 1, 0}) {
-		fill(s.xc, xp, sr, src, op)
+	if a, ok := globalAlpha(opts); ok {
+		// Scaling a uniform color, whose components are premultiplied by
+		// its alpha, is the same as masking it with a uniform alpha.
+		r, g, b, sa := src.RGBA()
+		src = color.RGBA64{
+			R: uint16(r * uint32(a) / 0xffff),
+			G: uint16(g * uint32(a) / 0xffff),
+			B: uint16(b * uint32(a) / 0xffff),
+			A: uint16(sa * uint32(a) / 0xffff),
+		}
+	}
+
+	mask := opts != nil && opts.Mask != nil
+	if !mask && *src2dst == (f64.Aff3{1, 0, 0, 0, 1, 0}) {
+		if pictOp, ok := s.blendOp(opts); ok {
+			fillOp(s.xc, xp, sr, src, pictOp)
+		} else {
+			fill(s.xc, xp, sr, src, op)
+		}
 		return
 	}
 
@@ -676,6 +1484,19 @@ func (s *screenImpl) drawUniform(xp render.Picture, src2dst *f64.Aff3, src color
 		render.CreateSolidFill(s.xc, s.uniformP, c)
 	}
 
+	if mask {
+		// A mask from another screen.Screen implementation cannot be used,
+		// and drawing without it would draw too much, so draw nothing.
+		if m, ok := opts.Mask.(*textureImpl); ok {
+			m.drawMask(s.uniformP, xp, src2dst, sr, op, opts)
+		}
+		return
+	}
+	if pictOp, ok := s.blendOp(opts); ok {
+		render.TriFan(s.xc, pictOp, s.uniformP, xp, 0, 0, 0, points[:])
+		return
+	}
+
 	if op == draw.Src {
 		// We implement draw.Src as render.PictOpOutReverse followed by
 		// render.PictOpOver, for the same reason as in textureImpl.draw.
@@ -0,0 +1,48 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// TODO: implement the X Session Management Protocol (XSMP), which runs over
+// ICE instead of the X11 connection. For now, we implement the ICCCM
+// WM_SAVE_YOURSELF protocol and the properties that an XSMP session manager
+// uses to match windows to clients.
+
+func (s *screenImpl) SetSessionID(id string) {
+	s.mu.Lock()
+	s.sessionID = id
+	windows := make([]*windowImpl, 0, len(s.windows))
+	for _, w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mu.Unlock()
+
+	// The SM_CLIENT_ID property belongs on the client leader window.
+	if id != "" {
+		s.setStringProperty(s.window32, s.atomSMClientID, xproto.AtomString, id)
+	} else {
+		xproto.DeleteProperty(s.xc, s.window32, s.atomSMClientID)
+	}
+	for _, w := range windows {
+		w.setProtocols()
+	}
+}
+
+func (s *screenImpl) handleSaveYourself(w *windowImpl) {
+	w.Post(screen.SaveSessionEvent{})
+
+	// The ICCCM requires that a client respond to WM_SAVE_YOURSELF by setting
+	// the WM_COMMAND property, even if its value does not change. Its value
+	// is the command line, as NUL-terminated strings.
+	s.setStringProperty(s.window32, s.atomWMCommand, xproto.AtomString, strings.Join(os.Args, "\x00")+"\x00")
+}
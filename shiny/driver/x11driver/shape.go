@@ -0,0 +1,157 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/BurntSushi/xgb/shape"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func (w *windowImpl) SetShape(region image.Image) error {
+	if !w.s.hasShape {
+		return errors.New("x11driver: window shapes require the SHAPE extension")
+	}
+	if region == nil {
+		err := shape.MaskChecked(w.s.xc, shape.SoSet, shape.SkBounding, w.xw, 0, 0, xproto.PixmapNone).Check()
+		if err != nil {
+			return fmt.Errorf("x11driver: shape.Mask failed: %v", err)
+		}
+		return nil
+	}
+	rects, ok := shapeRectangles(region)
+	if !ok {
+		return fmt.Errorf("x11driver: window shape bounds %v are out of range", region.Bounds())
+	}
+	err := shape.RectanglesChecked(w.s.xc, shape.SoSet, shape.SkBounding, xproto.ClipOrderingYXBanded,
+		w.xw, 0, 0, rects).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: shape.Rectangles failed: %v", err)
+	}
+	return nil
+}
+
+func (w *windowImpl) SetDecorationGeometry(visible, input image.Rectangle, shadow [4]int) error {
+	if !w.s.hasShape {
+		return errors.New("x11driver: window shapes require the SHAPE extension")
+	}
+	for _, v := range shadow {
+		if v < 0 {
+			return fmt.Errorf("x11driver: invalid frame extents %v", shadow)
+		}
+	}
+	var visibleRects, inputRects []xproto.Rectangle
+	for _, x := range []struct {
+		r     image.Rectangle
+		rects *[]xproto.Rectangle
+	}{
+		{visible, &visibleRects},
+		{input, &inputRects},
+	} {
+		if x.r.Empty() {
+			continue
+		}
+		xr, ok := xRectangle(x.r)
+		if !ok {
+			return fmt.Errorf("x11driver: window shape bounds %v are out of range", x.r)
+		}
+		*x.rects = []xproto.Rectangle{xr}
+	}
+
+	// Grab the server so that the compositor and window manager never see
+	// only some of the changes.
+	xproto.GrabServer(w.s.xc)
+	defer xproto.UngrabServer(w.s.xc)
+
+	if err := setShapeRectangles(w, shape.SkBounding, visibleRects); err != nil {
+		return err
+	}
+	if err := setShapeRectangles(w, shape.SkInput, inputRects); err != nil {
+		return err
+	}
+	return w.SetFrameExtents(shadow[0], shadow[1], shadow[2], shadow[3])
+}
+
+// setShapeRectangles sets the kind region of w to rects, or resets it to the
+// whole window if rects is empty.
+func setShapeRectangles(w *windowImpl, kind shape.Kind, rects []xproto.Rectangle) error {
+	if len(rects) == 0 {
+		if err := shape.MaskChecked(w.s.xc, shape.SoSet, kind, w.xw, 0, 0, xproto.PixmapNone).Check(); err != nil {
+			return fmt.Errorf("x11driver: shape.Mask failed: %v", err)
+		}
+		return nil
+	}
+	err := shape.RectanglesChecked(w.s.xc, shape.SoSet, kind, xproto.ClipOrderingUnsorted,
+		w.xw, 0, 0, rects).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: shape.Rectangles failed: %v", err)
+	}
+	return nil
+}
+
+// shapeRectangles returns the YX-banded rectangles covering the pixels of m
+// whose alpha is at least half opaque. Consecutive rows with the same spans
+// share a band.
+func shapeRectangles(m image.Image) (rects []xproto.Rectangle, ok bool) {
+	b := m.Bounds()
+	if _, ok := xRectangle(b); !ok {
+		return nil, false
+	}
+
+	var band, prev []xproto.Rectangle
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		// Find this row's spans.
+		var row []xproto.Rectangle
+		for x := b.Min.X; x < b.Max.X; {
+			if !opaque(m, x, y) {
+				x++
+				continue
+			}
+			x0 := x
+			for x < b.Max.X && opaque(m, x, y) {
+				x++
+			}
+			row = append(row, xproto.Rectangle{
+				X:      int16(x0),
+				Y:      int16(y),
+				Width:  uint16(x - x0),
+				Height: 1,
+			})
+		}
+
+		// Extend the current band if the spans match, otherwise start a new
+		// one.
+		if len(band) > 0 && sameSpans(row, prev) {
+			for i := range band {
+				band[i].Height++
+			}
+		} else {
+			rects = append(rects, band...)
+			band = row
+		}
+		prev = row
+	}
+	return append(rects, band...), true
+}
+
+func opaque(m image.Image, x, y int) bool {
+	_, _, _, a := m.At(x, y).RGBA()
+	return a >= 0x8000
+}
+
+func sameSpans(a, b []xproto.Rectangle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].X != b[i].X || a[i].Width != b[i].Width {
+			return false
+		}
+	}
+	return true
+}
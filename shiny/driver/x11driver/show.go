@@ -0,0 +1,47 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import "github.com/BurntSushi/xgb/xproto"
+
+func (w *windowImpl) Show() error {
+	// Undo any ShowInactive. Windows created with NewWindowOptions.NoFocus
+	// always keep a zero _NET_WM_USER_TIME.
+	if !w.noFocus {
+		w.mu.Lock()
+		t := w.lastInputTime
+		w.mu.Unlock()
+		if t != 0 {
+			w.s.setProperty32(w.xw, w.s.atomNetWMUserTime, xproto.AtomCardinal, uint32(t))
+		} else {
+			w.s.deleteProperty(w.xw, w.s.atomNetWMUserTime)
+		}
+	}
+	xproto.MapWindow(w.s.xc, w.xw)
+	return nil
+}
+
+func (w *windowImpl) ShowInactive() error {
+	// Per the EWMH spec, a zero _NET_WM_USER_TIME asks the window manager
+	// not to activate the window when it is mapped.
+	w.s.setProperty32(w.xw, w.s.atomNetWMUserTime, xproto.AtomCardinal, 0)
+	xproto.MapWindow(w.s.xc, w.xw)
+	return nil
+}
+
+func (w *windowImpl) Hide() error {
+	// Per the ICCCM, withdrawing a window takes both unmapping it and a
+	// synthetic UnmapNotify event sent to the root window, in case it is
+	// already unmapped, such as when it is minimized.
+	xproto.UnmapWindow(w.s.xc, w.xw)
+	ev := xproto.UnmapNotifyEvent{
+		Event:  w.s.xsi.Root,
+		Window: w.xw,
+	}
+	xproto.SendEvent(w.s.xc, false, w.s.xsi.Root,
+		xproto.EventMaskSubstructureNotify|xproto.EventMaskSubstructureRedirect,
+		string(ev.Bytes()))
+	return nil
+}
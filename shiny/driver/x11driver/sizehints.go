@@ -0,0 +1,69 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// These are the ICCCM's WM_SIZE_HINTS flags and the property's length, in
+// 32-bit words.
+const (
+	pMinSize     = 1 << 4
+	pMaxSize     = 1 << 5
+	pAspect      = 1 << 7
+	sizeHintsLen = 18
+
+	// noMaxSize is the maximum width or height used for a zero component of
+	// SizeHints.Max, as WM_SIZE_HINTS cannot leave just one unconstrained.
+	noMaxSize = 0x7fff
+)
+
+func (w *windowImpl) SetSizeHints(h screen.SizeHints) error {
+	if h.Min.X < 0 || h.Min.Y < 0 || h.Max.X < 0 || h.Max.Y < 0 || h.Aspect.X < 0 || h.Aspect.Y < 0 {
+		return fmt.Errorf("x11driver: invalid size hints %+v", h)
+	}
+	// Keep the position hints set by setGeometry.
+	old, err := w.s.getProperty32(w.xw, xproto.AtomWmNormalHints, xproto.AtomWmSizeHints)
+	if err != nil {
+		return err
+	}
+	w.s.setProperty32(w.xw, xproto.AtomWmNormalHints, xproto.AtomWmSizeHints, sizeHints(old, h)...)
+	return nil
+}
+
+// sizeHints returns the WM_NORMAL_HINTS property value old, which may be
+// empty, updated with h.
+func sizeHints(old []uint32, h screen.SizeHints) []uint32 {
+	hints := make([]uint32, sizeHintsLen)
+	copy(hints, old)
+	hints[0] &^= pMinSize | pMaxSize | pAspect
+	if h.Min != (image.Point{}) {
+		hints[0] |= pMinSize
+		hints[5], hints[6] = uint32(h.Min.X), uint32(h.Min.Y)
+	}
+	if h.Max != (image.Point{}) {
+		hints[0] |= pMaxSize
+		hints[7], hints[8] = uint32(h.Max.X), uint32(h.Max.Y)
+		if h.Max.X == 0 {
+			hints[7] = noMaxSize
+		}
+		if h.Max.Y == 0 {
+			hints[8] = noMaxSize
+		}
+	}
+	if h.Aspect.X > 0 && h.Aspect.Y > 0 {
+		// Equal minimum and maximum aspect ratios fix the aspect ratio.
+		hints[0] |= pAspect
+		hints[11], hints[12] = uint32(h.Aspect.X), uint32(h.Aspect.Y)
+		hints[13], hints[14] = uint32(h.Aspect.X), uint32(h.Aspect.Y)
+	}
+	return hints
+}
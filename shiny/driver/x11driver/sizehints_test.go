@@ -0,0 +1,50 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func TestSizeHints(t *testing.T) {
+	const usPosition = 1 << 0
+	old := make([]uint32, sizeHintsLen)
+	old[0] = usPosition
+	old[1], old[2] = 100, 200
+
+	got := sizeHints(old, screen.SizeHints{
+		Min:    image.Point{160, 90},
+		Aspect: image.Point{16, 9},
+	})
+	want := make([]uint32, sizeHintsLen)
+	want[0] = usPosition | pMinSize | pAspect
+	want[1], want[2] = 100, 200
+	want[5], want[6] = 160, 90
+	want[11], want[12] = 16, 9
+	want[13], want[14] = 16, 9
+	if len(got) != len(want) {
+		t.Fatalf("got %d words, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("word %d: got %d, want %d", i, got[i], want[i])
+		}
+	}
+
+	// A zero maximum height leaves the height unconstrained.
+	got = sizeHints(old, screen.SizeHints{Max: image.Point{800, 0}})
+	if got[0] != usPosition|pMaxSize || got[7] != 800 || got[8] != noMaxSize {
+		t.Errorf("partial maximum: got flags %#x, maximum (%d, %d)", got[0], got[7], got[8])
+	}
+
+	// Clearing the hints keeps the position.
+	got = sizeHints(got, screen.SizeHints{})
+	if got[0] != usPosition || got[1] != 100 || got[2] != 200 {
+		t.Errorf("after clearing: got flags %#x, position (%d, %d)", got[0], got[1], got[2])
+	}
+}
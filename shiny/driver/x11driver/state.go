@@ -0,0 +1,84 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// windowState converts the atoms of a _NET_WM_STATE property value.
+func (s *screenImpl) windowState(atoms []uint32) screen.WindowState {
+	var st screen.WindowState
+	var vert, horz bool
+	for _, a := range atoms {
+		switch xproto.Atom(a) {
+		case s.atomNetWMStateFullscreen:
+			st.Fullscreen = true
+		case s.atomNetWMStateHidden:
+			st.Minimized = true
+		case s.atomNetWMStateMaximizedVert:
+			vert = true
+		case s.atomNetWMStateMaximizedHorz:
+			horz = true
+		}
+	}
+	st.Maximized = vert && horz
+	return st
+}
+
+// setState records the window's _NET_WM_STATE and wakes any WaitForState
+// calls.
+func (w *windowImpl) setState(st screen.WindowState) {
+	w.mu.Lock()
+	w.state = st
+	if w.stateChanged != nil {
+		close(w.stateChanged)
+		w.stateChanged = nil
+	}
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) WaitForState(predicate func(screen.WindowState) bool, timeout time.Duration) error {
+	// The window manager may have changed the property before the run loop
+	// saw the PropertyNotify event for it, so check its current value too.
+	// That value is not recorded in w.state, which only the run loop
+	// updates, so that it never goes back to an older value.
+	atoms, err := w.s.getProperty32(w.xw, w.s.atomNetWMState, xproto.AtomAtom)
+	if err != nil {
+		return err
+	}
+	if predicate(w.s.windowState(atoms)) {
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		w.mu.Lock()
+		st, released := w.state, w.released
+		if w.stateChanged == nil {
+			w.stateChanged = make(chan struct{})
+		}
+		changed := w.stateChanged
+		w.mu.Unlock()
+
+		if predicate(st) {
+			return nil
+		}
+		if released {
+			return errors.New("x11driver: window released while waiting for its state")
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return errors.New("x11driver: timed out waiting for window state")
+		}
+	}
+}
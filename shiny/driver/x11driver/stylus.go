@@ -0,0 +1,157 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// isTablet returns whether d is a tablet's stylus, whose XInput2 events are
+// decoded as screen.StylusEvents.
+//
+// Only slave devices are selected. Selecting a master device's XInput2
+// events would stop the X11 server from sending its core events, which the
+// mouse.Events are decoded from.
+func (s *screenImpl) isTablet(d *xiDevice) bool {
+	return d.use == xiSlavePointer && s.deviceInfo(d).Type == screen.DeviceTablet
+}
+
+// tablets returns the device IDs of the tablets.
+func (s *screenImpl) tablets() []uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []uint16
+	for id, d := range s.devices {
+		if s.isTablet(d) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// selectStylus selects the XInput2 events of the given tablets on xw.
+func (s *screenImpl) selectStylus(xw xproto.Window, tablets []uint16) {
+	for _, id := range tablets {
+		if err := s.selectXIEvents(xw, id, xiMotion, xiButtonPress, xiButtonRelease); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// handleStylus handles an XInput2 motion, button press or button release
+// event, which is sent for the tablets selected by selectStylus.
+func (s *screenImpl) handleStylus(evtype uint16, buf []byte) {
+	if len(buf) < 80 {
+		return
+	}
+	s.mu.Lock()
+	d := s.devices[xgb.Get16(buf[10:])]
+	s.mu.Unlock()
+	if d == nil || !s.isTablet(d) {
+		return
+	}
+	w := s.findWindow(xproto.Window(xgb.Get32(buf[24:])))
+	if w == nil || w.blockedByModal() {
+		return
+	}
+
+	buttonsLen, valuatorsLen := 4*int(xgb.Get16(buf[48:])), 4*int(xgb.Get16(buf[50:]))
+	if len(buf) < 80+buttonsLen+valuatorsLen {
+		return
+	}
+	buttons := buf[80 : 80+buttonsLen]
+	mask := buf[80+buttonsLen : 80+buttonsLen+valuatorsLen]
+	values := buf[80+buttonsLen+valuatorsLen:]
+
+	// Only the valuators that have changed are sent, so the others keep
+	// their values from the previous event. d.stylus is only accessed by the
+	// event loop.
+	e := d.stylus
+	e.Device = int(d.id)
+	e.X, e.Y = fp1616(buf[40:]), fp1616(buf[44:])
+	if w.reportDIP {
+		e.X /= s.pixelsPerPt
+		e.Y /= s.pixelsPerPt
+	}
+	pressure, _ := d.valuator(s.atomAbsPressure)
+	tiltX, hasTiltX := d.valuator(s.atomAbsTiltX)
+	tiltY, hasTiltY := d.valuator(s.atomAbsTiltY)
+	for i, n := 0, 0; i < 8*len(mask) && 8*n+8 <= len(values); i++ {
+		if mask[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		v := fp3232(values[8*n:])
+		n++
+		switch uint16(i) {
+		case pressure.number:
+			e.Pressure = pressure.unit(v)
+		case tiltX.number:
+			if hasTiltX {
+				e.TiltX = tiltX.signedUnit(v)
+			}
+		case tiltY.number:
+			if hasTiltY {
+				e.TiltY = tiltY.signedUnit(v)
+			}
+		}
+	}
+
+	// The buttons are the state before this event, as for core events.
+	// Buttons 2 and 3 are the stylus's barrel buttons.
+	var barrel [4]bool
+	for b := 2; b <= 3; b++ {
+		barrel[b] = b/8 < len(buttons) && buttons[b/8]&(1<<uint(b%8)) != 0
+	}
+	if b := xgb.Get32(buf[16:]); b == 2 || b == 3 {
+		switch evtype {
+		case xiButtonPress:
+			barrel[b] = true
+		case xiButtonRelease:
+			barrel[b] = false
+		}
+	}
+	e.BarrelButton = barrel[2] || barrel[3]
+
+	d.stylus = e
+	w.Post(e)
+}
+
+// unit maps x from the valuator's range to [0, 1].
+func (v xiValuator) unit(x float64) float32 {
+	if v.max <= v.min {
+		return 0
+	}
+	return float32(clamp((x-v.min)/(v.max-v.min), 0, 1))
+}
+
+// signedUnit maps x from the valuator's range to [-1, 1]. If the range
+// includes 0, then 0 is mapped to 0.
+func (v xiValuator) signedUnit(x float64) float32 {
+	switch {
+	case v.max <= v.min:
+		return 0
+	case v.min < 0 && x < 0:
+		return float32(clamp(x/-v.min, -1, 0))
+	case v.min < 0 && v.max > 0:
+		return float32(clamp(x/v.max, 0, 1))
+	}
+	return 2*v.unit(x) - 1
+}
+
+func clamp(x, min, max float64) float64 {
+	if x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// xiDeviceEvent returns an XInput2 device event from the device, with the
+// given buttons held and valuator values, by valuator number.
+func xiDeviceEvent(evtype uint16, device uint16, detail uint32, buttons []int, valuators map[int]float64) []byte {
+	maxValuator := -1
+	for n := range valuators {
+		if n > maxValuator {
+			maxValuator = n
+		}
+	}
+	buttonsLen, valuatorsLen := 1, (maxValuator+32)/32
+	buf := xiEvent(evtype, 80+4*buttonsLen+4*valuatorsLen+8*len(valuators))
+	xgb.Put16(buf[10:], device)
+	xgb.Put32(buf[16:], detail)
+	xgb.Put32(buf[24:], 1)
+	putFP1616(buf[40:], 10.25)
+	putFP1616(buf[44:], 20.5)
+	xgb.Put16(buf[48:], uint16(buttonsLen))
+	xgb.Put16(buf[50:], uint16(valuatorsLen))
+	for _, b := range buttons {
+		buf[80+b/8] |= 1 << uint(b%8)
+	}
+	mask := buf[80+4*buttonsLen:]
+	values := buf[80+4*buttonsLen+4*valuatorsLen:]
+	for n := 0; n <= maxValuator; n++ {
+		if v, ok := valuators[n]; ok {
+			mask[n/8] |= 1 << uint(n%8)
+			putFP3232(values, v)
+			values = values[8:]
+		}
+	}
+	return buf
+}
+
+func TestHandleStylus(t *testing.T) {
+	const (
+		absPressure = xproto.Atom(100) + iota
+		absTiltX
+		absTiltY
+	)
+	pen := &xiDevice{
+		id:      11,
+		use:     xiSlavePointer,
+		enabled: true,
+		name:    "Pen",
+		buttons: true,
+		valuators: []xiValuator{
+			{number: 0, min: 0, max: 44704},
+			{number: 1, min: 0, max: 27940},
+			{number: 2, label: absPressure, min: 0, max: 2048},
+			{number: 3, label: absTiltX, min: -64, max: 63},
+			{number: 4, label: absTiltY, min: -64, max: 63},
+		},
+	}
+	mouse := &xiDevice{id: 12, use: xiSlavePointer, enabled: true, name: "Mouse", buttons: true}
+	w := &windowImpl{}
+	s := &screenImpl{
+		atomAbsPressure: absPressure,
+		atomAbsTiltX:    absTiltX,
+		atomAbsTiltY:    absTiltY,
+		devices:         map[uint16]*xiDevice{11: pen, 12: mouse},
+		windows:         map[xproto.Window]*windowImpl{1: w},
+	}
+	if got := s.tablets(); len(got) != 1 || got[0] != 11 {
+		t.Errorf("tablets: got %v, want [11]", got)
+	}
+
+	s.handleStylus(xiMotion, xiDeviceEvent(xiMotion, 11, 0, nil, map[int]float64{
+		0: 100, 1: 200, 2: 512, 3: -32, 4: 63,
+	}))
+	// The tilt is unchanged, so it is not sent.
+	s.handleStylus(xiButtonPress, xiDeviceEvent(xiButtonPress, 11, 2, []int{1}, map[int]float64{
+		2: 1024,
+	}))
+	s.handleStylus(xiMotion, xiDeviceEvent(xiMotion, 11, 0, []int{1, 2}, map[int]float64{
+		3: 0,
+	}))
+	s.handleStylus(xiButtonRelease, xiDeviceEvent(xiButtonRelease, 11, 2, []int{1, 2}, nil))
+	s.handleStylus(xiMotion, xiDeviceEvent(xiMotion, 12, 0, nil, map[int]float64{0: 1}))
+
+	stylusEvent := func(pressure, tiltX, tiltY float32, barrel bool) screen.StylusEvent {
+		return screen.StylusEvent{
+			Device:       11,
+			X:            10.25,
+			Y:            20.5,
+			Pressure:     pressure,
+			TiltX:        tiltX,
+			TiltY:        tiltY,
+			BarrelButton: barrel,
+		}
+	}
+	want := []screen.StylusEvent{
+		stylusEvent(0.25, -0.5, 1, false),
+		stylusEvent(0.5, -0.5, 1, true),
+		stylusEvent(0.5, 0, 1, true),
+		stylusEvent(0.5, 0, 1, false),
+	}
+	for i, want := range want {
+		if got := w.NextEvent(); got != want {
+			t.Errorf("event %d: got %+v, want %+v", i, got, want)
+		}
+	}
+	if e, ok := w.TryNextEvent(); ok {
+		t.Errorf("event from the mouse: %+v", e)
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"log"
 	"math"
 	"sync"
 
 	"github.com/BurntSushi/xgb/render"
 	"github.com/BurntSushi/xgb/xproto"
 
+	"golang.org/x/exp/shiny/driver/internal/drawer"
 	"golang.org/x/exp/shiny/screen"
 	"golang.org/x/image/math/f64"
 )
@@ -34,6 +36,16 @@ type textureImpl struct {
 	// inconsistencies.
 	renderMu sync.Mutex
 
+	// maskRGB and maskBGR are lazily created component-alpha Pictures of the
+	// same pixmap as xp, used when t is a DrawOptions.Mask for subpixel
+	// antialiasing. They are guarded by renderMu.
+	maskRGB render.Picture
+	maskBGR render.Picture
+
+	// filter is the sampling filter last set on xp. It is guarded by
+	// renderMu.
+	filter screen.Filter
+
 	releasedMu sync.Mutex
 	released   bool
 }
@@ -51,6 +63,13 @@ func (t *textureImpl) Release() {
 	if released || t.degenerate() {
 		return
 	}
+	t.renderMu.Lock()
+	for _, p := range [...]render.Picture{t.maskRGB, t.maskBGR} {
+		if p != 0 {
+			render.FreePicture(t.s.xc, p)
+		}
+	}
+	t.renderMu.Unlock()
 	render.FreePicture(t.s.xc, t.xp)
 	xproto.FreePixmap(t.s.xc, t.xm)
 }
@@ -59,7 +78,29 @@ func (t *textureImpl) Upload(dp image.Point, src screen.Buffer, sr image.Rectang
 	if t.degenerate() {
 		return
 	}
-	src.(*bufferImpl).upload(xproto.Drawable(t.xm), t.s.gcontext32, textureDepth, dp, sr)
+	uploadBuffer(src).upload(xproto.Drawable(t.xm), t.s.gcontext32, textureDepth, dp, sr)
+}
+
+func (t *textureImpl) UploadBatch(parts []screen.UploadPart) {
+	if t.degenerate() {
+		return
+	}
+	// Send all of the requests before waiting for any of them, so that the
+	// batch takes a single round trip.
+	pending := make([]*pendingUpload, 0, len(parts))
+	for _, p := range parts {
+		b := uploadBuffer(p.Src)
+		if u := b.sendUpload(xproto.Drawable(t.xm), t.s.gcontext32, textureDepth, p.DP, p.SR); u != nil {
+			pending = append(pending, u)
+		}
+	}
+	completions := make([]chan struct{}, len(pending))
+	for i, u := range pending {
+		completions[i] = u.check()
+	}
+	for i, u := range pending {
+		u.wait(completions[i])
+	}
 }
 
 func (t *textureImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
@@ -69,6 +110,102 @@ func (t *textureImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
 	fill(t.s.xc, t.xp, dr, src, op)
 }
 
+func (t *textureImpl) DrawUniform(src2dst f64.Aff3, src color.Color, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	if t.degenerate() {
+		return
+	}
+	defer t.clip(opts)()
+	t.s.drawUniform(t.xp, &src2dst, src, sr, op, opts)
+}
+
+func (t *textureImpl) Draw(src2dst f64.Aff3, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	if t.degenerate() {
+		return
+	}
+	defer t.clip(opts)()
+	src.(*textureImpl).draw(t.xp, &src2dst, sr, op, opts)
+}
+
+func (t *textureImpl) Copy(dp image.Point, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	if t.degenerate() {
+		return
+	}
+	// s.gcontext32 has no clip rectangles, so clipped copies take the
+	// render path.
+	if opts == nil || opts.Clip.Empty() {
+		if src.(*textureImpl).copyArea(xproto.Drawable(t.xm), t.s.pictformat32, t.s.gcontext32, dp, sr, op, opts) {
+			return
+		}
+	}
+	drawer.Copy(t, dp, src, sr, op, opts)
+}
+
+func (t *textureImpl) Scale(dr image.Rectangle, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	drawer.Scale(t, dr, src, sr, op, opts)
+}
+
+// copyArea copies the sr part of t to dp in dst, a drawable whose pictures
+// have format dstFormat, with xproto.CopyArea, which is much cheaper than
+// render.Composite. It returns false, having done nothing, if the copy needs
+// render, in which case the caller should use the draw method.
+//
+// With the Src operator and no mask or blend mode, render.Composite from a
+// picture to another of the same format, translated by whole pixels, copies
+// the pixel values verbatim, which is exactly what CopyArea does, so the
+// result is identical.
+func (t *textureImpl) copyArea(dst xproto.Drawable, dstFormat render.Pictformat, xg xproto.Gcontext,
+	dp image.Point, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) bool {
+
+	if !canCopyArea(t.s.pictformat32, dstFormat, op, opts) {
+		return false
+	}
+	originalSRMin := sr.Min
+	sr = sr.Intersect(t.Bounds())
+	if sr.Empty() {
+		return true
+	}
+	dp = dp.Add(sr.Min.Sub(originalSRMin))
+	if _, ok := xRectangle(image.Rectangle{Min: dp, Max: dp.Add(sr.Size())}); !ok {
+		return false
+	}
+	xproto.CopyArea(t.s.xc, xproto.Drawable(t.xm), dst, xg,
+		int16(sr.Min.X), int16(sr.Min.Y), int16(dp.X), int16(dp.Y), uint16(sr.Dx()), uint16(sr.Dy()))
+	return true
+}
+
+// canCopyArea reports whether drawing a picture of format srcFormat, translated
+// by whole pixels, to one of format dstFormat, with op and opts, copies the
+// pixel values verbatim. The source's alpha is ignored, as if it were
+// opaque, only by the Src operator, and any mask, blend mode or transparency
+// changes the pixel values, as does converting between formats.
+func canCopyArea(srcFormat, dstFormat render.Pictformat, op draw.Op, opts *screen.DrawOptions) bool {
+	if op != draw.Src || dstFormat != srcFormat {
+		return false
+	}
+	if opts != nil && (opts.Mask != nil || opts.Blend != screen.BlendNone) {
+		return false
+	}
+	_, ok := globalAlpha(opts)
+	return !ok
+}
+
+// clip restricts drawing to t to opts.Clip, if set, and returns a function
+// that undoes that restriction.
+func (t *textureImpl) clip(opts *screen.DrawOptions) func() {
+	if opts == nil || opts.Clip.Empty() {
+		return func() {}
+	}
+	// An empty list of rectangles means that nothing is drawn.
+	var rects []xproto.Rectangle
+	if xr, ok := xRectangle(opts.Clip); ok {
+		rects = append(rects, xr)
+	}
+	render.SetPictureClipRectangles(t.s.xc, t.xp, 0, 0, rects)
+	return func() {
+		render.ChangePicture(t.s.xc, t.xp, render.CpClipMask, []uint32{0})
+	}
+}
+
 // f64ToFixed converts from float64 to X11/Render's 16.16 fixed point.
 func f64ToFixed(x float64) render.Fixed {
 	return render.Fixed(x * 65536)
@@ -95,35 +232,41 @@ func (t *textureImpl) draw(xp render.Picture, src2dst *f64.Aff3, sr image.Rectan
 	t.renderMu.Lock()
 	defer t.renderMu.Unlock()
 
+	filter := screen.FilterNearest
+	if opts != nil {
+		filter = opts.Filter
+	}
+	if t.filter != filter {
+		t.filter = filter
+		name := "nearest"
+		if filter == screen.FilterLinear {
+			name = "bilinear"
+		}
+		render.SetPictureFilter(t.s.xc, t.xp, uint16(len(name)), name, nil)
+	}
+
 	// For simple copies and scales, the inverse matrix is trivial to compute,
 	// and we do not need the "Src becomes OutReverse plus Over" dance (see
 	// below). Thus, draw can be one render.SetPictureTransform call and then
 	// one render.Composite call, regardless of whether or not op is Src.
 	if src2dst[1] == 0 && src2dst[3] == 0 {
-		dstXMin := float64(sr.Min.X)*src2dst[0] + src2dst[2]
-		dstXMax := float64(sr.Max.X)*src2dst[0] + src2dst[2]
-		if dstXMin > dstXMax {
-			// TODO: check if this (and below) works when src2dst[0] < 0.
-			dstXMin, dstXMax = dstXMax, dstXMin
-		}
-		dXMin := int(math.Floor(dstXMin))
-		dXMax := int(math.Ceil(dstXMax))
-
-		dstYMin := float64(sr.Min.Y)*src2dst[4] + src2dst[5]
-		dstYMax := float64(sr.Max.Y)*src2dst[4] + src2dst[5]
-		if dstYMin > dstYMax {
-			// TODO: check if this (and below) works when src2dst[4] < 0.
-			dstYMin, dstYMax = dstYMax, dstYMin
-		}
-		dYMin := int(math.Floor(dstYMin))
-		dYMax := int(math.Ceil(dstYMax))
-
+		dXMin, dYMin, dXMax, dYMax := scaledBounds(src2dst, sr)
 		render.SetPictureTransform(t.s.xc, t.xp, render.Transform{
 			f64ToFixed(1 / src2dst[0]), 0, 0,
 			0, f64ToFixed(1 / src2dst[4]), 0,
 			0, 0, 1 << 16,
 		})
-		render.Composite(t.s.xc, renderOp(op), t.xp, 0, xp,
+		pictOp, ok := t.s.blendOp(opts)
+		if !ok {
+			pictOp = renderOp(op)
+		}
+		mp := render.Picture(0)
+		if a, ok := globalAlpha(opts); ok {
+			t.s.alphaMu.Lock()
+			defer t.s.alphaMu.Unlock()
+			mp = t.s.alphaMask(a)
+		}
+		render.Composite(t.s.xc, pictOp, t.xp, mp, xp,
 			int16(sr.Min.X), int16(sr.Min.Y), // SrcX, SrcY,
 			0, 0, // MaskX, MaskY,
 			int16(dXMin), int16(dYMin), // DstX, DstY,
@@ -142,6 +285,16 @@ func (t *textureImpl) draw(xp render.Picture, src2dst *f64.Aff3, sr image.Rectan
 	})
 
 	points := trifanPoints(src2dst, sr)
+	if a, ok := globalAlpha(opts); ok {
+		t.drawAlpha(xp, points, op, opts, a)
+		return
+	}
+	if pictOp, ok := t.s.blendOp(opts); ok {
+		// The blend modes leave the destination unchanged where the source is
+		// transparent, so they do not need the dance below.
+		render.TriFan(t.s.xc, pictOp, t.xp, xp, 0, 0, 0, points[:])
+		return
+	}
 	if op == draw.Src {
 		// render.TriFan visits every dst-space pixel in the axis-aligned
 		// bounding box (AABB) containing the transformation of the sr
@@ -172,6 +325,170 @@ func (t *textureImpl) draw(xp render.Picture, src2dst *f64.Aff3, sr image.Rectan
 	render.TriFan(t.s.xc, render.PictOpOver, t.xp, xp, 0, 0, 0, points[:])
 }
 
+// drawAlpha draws t, transformed to the dst-space quad given by points, with
+// the global alpha a. render.TriFan does not take a mask Picture, so t is
+// first drawn to a temporary picture the size of the quad's bounding box,
+// which is transparent outside the quad, and that picture is then composited
+// with a mask. It must only be called while holding t.renderMu, after t's
+// transform has been set.
+func (t *textureImpl) drawAlpha(xp render.Picture, points [4]render.Pointfix, op draw.Op, opts *screen.DrawOptions, a uint16) {
+	b := pointfixBounds(points)
+	xr, ok := xRectangle(b)
+	if !ok || b.Empty() {
+		return
+	}
+	tmp, err := t.s.newBackBuffer(t.s.window32, textureDepth, t.s.pictformat32, b.Size())
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	defer t.s.releaseBackBuffer(tmp)
+
+	fillOp(t.s.xc, tmp.xp, image.Rectangle{Max: b.Size()}, color.Transparent, render.PictOpSrc)
+	var tmpPoints [4]render.Pointfix
+	for i, p := range points {
+		tmpPoints[i] = render.Pointfix{
+			X: p.X - render.Fixed(b.Min.X<<16),
+			Y: p.Y - render.Fixed(b.Min.Y<<16),
+		}
+	}
+	render.TriFan(t.s.xc, render.PictOpOver, t.xp, tmp.xp, 0, 0, 0, tmpPoints[:])
+
+	pictOp, ok := t.s.blendOp(opts)
+	if !ok {
+		pictOp = render.PictOpOver
+		if op == draw.Src {
+			// As in draw, clear the quad but not the rest of its bounding
+			// box, which Composite with PictOpSrc would also clear.
+			render.TriFan(t.s.xc, render.PictOpOutReverse, t.s.opaqueP, xp, 0, 0, 0, points[:])
+		}
+	}
+	t.s.alphaMu.Lock()
+	defer t.s.alphaMu.Unlock()
+	render.Composite(t.s.xc, pictOp, tmp.xp, t.s.alphaMask(a), xp,
+		0, 0, // SrcX, SrcY,
+		0, 0, // MaskX, MaskY,
+		xr.X, xr.Y, // DstX, DstY,
+		xr.Width, xr.Height, // Width, Height,
+	)
+}
+
+// pointfixBounds returns the smallest rectangle, in whole pixels, that
+// contains points.
+func pointfixBounds(points [4]render.Pointfix) image.Rectangle {
+	minX, minY := points[0].X, points[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range points[1:] {
+		if minX > p.X {
+			minX = p.X
+		}
+		if maxX < p.X {
+			maxX = p.X
+		}
+		if minY > p.Y {
+			minY = p.Y
+		}
+		if maxY < p.Y {
+			maxY = p.Y
+		}
+	}
+	return image.Rect(
+		int(minX>>16), int(minY>>16),
+		int((maxX+0xffff)>>16), int((maxY+0xffff)>>16),
+	)
+}
+
+// maskPicture returns the Picture to use when t is a DrawOptions.Mask. It must
+// only be called while holding t.renderMu.
+func (t *textureImpl) maskPicture(order screen.SubpixelOrder) render.Picture {
+	var (
+		p          *render.Picture
+		pictformat render.Pictformat
+	)
+	switch order {
+	default:
+		return t.xp
+	case screen.SubpixelRGB:
+		p, pictformat = &t.maskRGB, t.s.pictformat32
+	case screen.SubpixelBGR:
+		// Viewing the same pixmap through a Pictformat whose red and blue
+		// channels are swapped maps the left third of each pixel to the blue
+		// subpixel, without converting any pixels.
+		p, pictformat = &t.maskBGR, t.s.pictformat32BGR
+		if pictformat == 0 {
+			p, pictformat = &t.maskRGB, t.s.pictformat32
+		}
+	}
+	if *p == 0 {
+		xp, err := render.NewPictureId(t.s.xc)
+		if err != nil {
+			return t.xp
+		}
+		render.CreatePicture(t.s.xc, xp, xproto.Drawable(t.xm), pictformat,
+			render.CpRepeat|render.CpComponentAlpha, []uint32{render.RepeatPad, 1})
+		*p = xp
+	}
+	return *p
+}
+
+// drawMask implements DrawUniform with a DrawOptions.Mask. srcP is a solid
+// fill Picture of the uniform color.
+func (t *textureImpl) drawMask(srcP, xp render.Picture, src2dst *f64.Aff3, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	sr = sr.Intersect(t.Bounds())
+	if sr.Empty() || t.degenerate() {
+		return
+	}
+	// TODO: support masks for arbitrary affine transformations. render.TriFan
+	// does not take a mask Picture.
+	if src2dst[1] != 0 || src2dst[3] != 0 {
+		return
+	}
+
+	t.renderMu.Lock()
+	defer t.renderMu.Unlock()
+
+	mp := t.maskPicture(opts.SubpixelOrder)
+	dXMin, dYMin, dXMax, dYMax := scaledBounds(src2dst, sr)
+	render.SetPictureTransform(t.s.xc, mp, render.Transform{
+		f64ToFixed(1 / src2dst[0]), 0, 0,
+		0, f64ToFixed(1 / src2dst[4]), 0,
+		0, 0, 1 << 16,
+	})
+	pictOp, ok := t.s.blendOp(opts)
+	if !ok {
+		pictOp = renderOp(op)
+	}
+	render.Composite(t.s.xc, pictOp, srcP, mp, xp,
+		0, 0, // SrcX, SrcY,
+		int16(sr.Min.X), int16(sr.Min.Y), // MaskX, MaskY,
+		int16(dXMin), int16(dYMin), // DstX, DstY,
+		uint16(dXMax-dXMin), uint16(dYMax-dYMin), // Width, Height,
+	)
+}
+
+// scaledBounds returns the dst-space bounds of sr, for a src2dst that is a
+// scale and translation.
+func scaledBounds(src2dst *f64.Aff3, sr image.Rectangle) (dXMin, dYMin, dXMax, dYMax int) {
+	dstXMin := float64(sr.Min.X)*src2dst[0] + src2dst[2]
+	dstXMax := float64(sr.Max.X)*src2dst[0] + src2dst[2]
+	if dstXMin > dstXMax {
+		// TODO: check if this (and below) works when src2dst[0] < 0.
+		dstXMin, dstXMax = dstXMax, dstXMin
+	}
+	dXMin = int(math.Floor(dstXMin))
+	dXMax = int(math.Ceil(dstXMax))
+
+	dstYMin := float64(sr.Min.Y)*src2dst[4] + src2dst[5]
+	dstYMax := float64(sr.Max.Y)*src2dst[4] + src2dst[5]
+	if dstYMin > dstYMax {
+		// TODO: check if this (and below) works when src2dst[4] < 0.
+		dstYMin, dstYMax = dstYMax, dstYMin
+	}
+	dYMin = int(math.Floor(dstYMin))
+	dYMax = int(math.Ceil(dstYMax))
+	return dXMin, dYMin, dXMax, dYMax
+}
+
 func trifanPoints(src2dst *f64.Aff3, sr image.Rectangle) [4]render.Pointfix {
 	minX := float64(sr.Min.X)
 	maxX := float64(sr.Max.X)
@@ -198,3 +515,53 @@ func renderOp(op draw.Op) byte {
 	}
 	return render.PictOpOver
 }
+
+// blendOp returns the X11/Render operator for opts.Blend, and whether the
+// Blend field is in effect. If not, the caller should use its draw.Op.
+func (s *screenImpl) blendOp(opts *screen.DrawOptions) (byte, bool) {
+	if opts == nil {
+		return 0, false
+	}
+	switch opts.Blend {
+	case screen.BlendAdd:
+		return render.PictOpAdd, true
+	case screen.BlendMultiply, screen.BlendScreen, screen.BlendDarken, screen.BlendLighten:
+		if !s.hasPDFOps {
+			return render.PictOpOver, true
+		}
+		switch opts.Blend {
+		case screen.BlendMultiply:
+			return render.PictOpMultiply, true
+		case screen.BlendScreen:
+			return render.PictOpScreen, true
+		case screen.BlendDarken:
+			return render.PictOpDarken, true
+		default:
+			return render.PictOpLighten, true
+		}
+	}
+	return 0, false
+}
+
+// globalAlpha returns 1 - opts.Transparency as a 16-bit alpha value, and
+// whether it changes what is drawn.
+func globalAlpha(opts *screen.DrawOptions) (uint16, bool) {
+	if opts == nil || opts.Transparency <= 0 {
+		return 0xffff, false
+	}
+	if opts.Transparency >= 1 {
+		return 0, true
+	}
+	return uint16((1-opts.Transparency)*0xffff + 0.5), true
+}
+
+// alphaMask returns a solid fill Picture of alpha a, for use as a mask. It
+// must only be called while holding s.alphaMu.
+func (s *screenImpl) alphaMask(a uint16) render.Picture {
+	if s.alphaA != a {
+		s.alphaA = a
+		render.FreePicture(s.xc, s.alphaP)
+		render.CreateSolidFill(s.xc, s.alphaP, render.Color{Alpha: a})
+	}
+	return s.alphaP
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/BurntSushi/xgb/render"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func TestCanCopyArea(t *testing.T) {
+	const (
+		argb32 = render.Pictformat(1)
+		rgb24  = render.Pictformat(2)
+	)
+	testCases := []struct {
+		desc      string
+		dstFormat render.Pictformat
+		op        draw.Op
+		opts      *screen.DrawOptions
+		want      bool
+	}{
+		{"src", argb32, draw.Src, nil, true},
+		{"empty options", argb32, draw.Src, &screen.DrawOptions{}, true},
+		{"clip", argb32, draw.Src, &screen.DrawOptions{Clip: image.Rect(0, 0, 1, 1)}, true},
+		{"filter", argb32, draw.Src, &screen.DrawOptions{Filter: screen.FilterLinear}, true},
+		{"over", argb32, draw.Over, nil, false},
+		{"other depth", rgb24, draw.Src, nil, false},
+		{"mask", argb32, draw.Src, &screen.DrawOptions{Mask: &textureImpl{}}, false},
+		{"blend", argb32, draw.Src, &screen.DrawOptions{Blend: screen.BlendMultiply}, false},
+		{"transparency", argb32, draw.Src, &screen.DrawOptions{Transparency: 0.5}, false},
+		{"fully transparent", argb32, draw.Src, &screen.DrawOptions{Transparency: 1}, false},
+	}
+	for _, tc := range testCases {
+		if got := canCopyArea(argb32, tc.dstFormat, tc.op, tc.opts); got != tc.want {
+			t.Errorf("%s: got %t, want %t", tc.desc, got, tc.want)
+		}
+	}
+}
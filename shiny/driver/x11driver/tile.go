@@ -0,0 +1,63 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/BurntSushi/xgb/render"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func (w *windowImpl) FillTexture(dr image.Rectangle, src screen.Texture, offset image.Point, op draw.Op) {
+	src.(*textureImpl).fillTiled(w.target().xp, dr, offset, op)
+}
+
+// fillTiled fills dr in xp with copies of t, tiled so that t's top-left
+// corner is at offset.
+func (t *textureImpl) fillTiled(xp render.Picture, dr image.Rectangle, offset image.Point, op draw.Op) {
+	if t.degenerate() {
+		return
+	}
+	xr, ok := xRectangle(dr)
+	if !ok {
+		return
+	}
+
+	t.renderMu.Lock()
+	defer t.renderMu.Unlock()
+
+	// t's Picture is created with RepeatPad, for the benefit of scaled draws,
+	// so we switch it to RepeatNormal for the duration of the tiling. The
+	// source coordinates wrap around, so any point congruent to dr.Min minus
+	// offset works, but they must fit in an int16.
+	sp := dr.Min.Sub(offset)
+	sp.X = mod(sp.X, t.size.X)
+	sp.Y = mod(sp.Y, t.size.Y)
+	render.SetPictureTransform(t.s.xc, t.xp, render.Transform{
+		Matrix11: 1 << 16,
+		Matrix22: 1 << 16,
+		Matrix33: 1 << 16,
+	})
+	render.ChangePicture(t.s.xc, t.xp, render.CpRepeat, []uint32{render.RepeatNormal})
+	render.Composite(t.s.xc, renderOp(op), t.xp, 0, xp,
+		int16(sp.X), int16(sp.Y), // SrcX, SrcY,
+		0, 0, // MaskX, MaskY,
+		xr.X, xr.Y, // DstX, DstY,
+		xr.Width, xr.Height, // Width, Height,
+	)
+	render.ChangePicture(t.s.xc, t.xp, render.CpRepeat, []uint32{render.RepeatPad})
+}
+
+// mod returns x modulo n, in the range [0, n).
+func mod(x, n int) int {
+	x %= n
+	if x < 0 {
+		x += n
+	}
+	return x
+}
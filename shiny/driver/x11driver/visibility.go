@@ -0,0 +1,61 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"log"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+func (w *windowImpl) handleVisibilityNotify(ev xproto.VisibilityNotifyEvent) {
+	w.xVisibility = ev.State
+	w.updateVisibility()
+}
+
+func (w *windowImpl) handlePropertyNotify(ev xproto.PropertyNotifyEvent) {
+	if ev.Atom != w.s.atomNetWMState {
+		return
+	}
+	// Compositing window managers redirect windows off-screen, so the X
+	// server reports them as unobscured even when they are covered. Such
+	// window managers set _NET_WM_STATE_HIDDEN on windows that are not
+	// visible at all, such as minimized windows.
+	var st screen.WindowState
+	if ev.State == xproto.PropertyNewValue {
+		atoms, err := w.s.getProperty32(w.xw, w.s.atomNetWMState, xproto.AtomAtom)
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		st = w.s.windowState(atoms)
+	}
+	w.setState(st)
+	if st != w.sentState {
+		w.sentState = st
+		w.Post(screen.WindowStateEvent{State: st})
+	}
+	w.hidden = st.Minimized
+	w.updateVisibility()
+}
+
+// updateVisibility sends a VisibilityEvent if the window's visibility has
+// changed. It must only be called from the screenImpl.run goroutine.
+func (w *windowImpl) updateVisibility() {
+	v := screen.Visible
+	switch {
+	case w.hidden || w.xVisibility == xproto.VisibilityFullyObscured:
+		v = screen.FullyObscured
+	case w.xVisibility == xproto.VisibilityPartiallyObscured:
+		v = screen.PartiallyObscured
+	}
+	if v == w.visibility {
+		return
+	}
+	w.visibility = v
+	w.Post(screen.VisibilityEvent{Visibility: v})
+}
@@ -4,13 +4,16 @@
 
 package x11driver
 
-// TODO: implement a back buffer.
-
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"log"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/BurntSushi/xgb"
 	"github.com/BurntSushi/xgb/render"
@@ -34,7 +37,17 @@ type windowImpl struct {
 
 	xw xproto.Window
 	xg xproto.Gcontext
-	xp render.Picture
+
+	// frontG is a GC without the clip rectangles set by PushClip, for copying
+	// back buffers to the window.
+	frontG     xproto.Gcontext
+	pictformat render.Pictformat
+
+	// depth is the depth of the window's visual, and colormap is the
+	// colormap created for the window if its visual is not the root
+	// window's, or zero.
+	depth    byte
+	colormap xproto.Colormap
 
 	event.Deque
 	xevents chan xgb.Event
@@ -45,44 +58,207 @@ type windowImpl struct {
 
 	lifecycler lifecycler.State
 
-	mu       sync.Mutex
-	released bool
+	// xVisibility is the state of the last VisibilityNotify event, hidden is
+	// whether _NET_WM_STATE contains _NET_WM_STATE_HIDDEN, and visibility is
+	// the Visibility last sent to the window.
+	xVisibility byte
+	hidden      bool
+	visibility  screen.Visibility
+
+	// sentState is the state last sent in a WindowStateEvent. It is only
+	// accessed by the screenImpl.run goroutine.
+	sentState screen.WindowState
+
+	// unthrottled is whether Publish skips its flow control, preventClose is
+	// whether the user is prevented from closing the window, reportDIP is
+	// whether mouse coordinates are in points instead of pixels, and noFocus
+	// is whether the window never takes the keyboard focus. They are set when
+	// the window is created and not modified afterwards.
+	unthrottled  bool
+	preventClose bool
+	reportDIP    bool
+	noFocus      bool
+
+	// resizeDebounce is NewWindowOptions.ResizeDebounce. It is set when the
+	// window is created and not modified afterwards.
+	resizeDebounce time.Duration
+
+	mu              sync.Mutex
+	released        bool
+	keyboardGrabbed bool
+	nPublished      uint64
+
+	// lastInputTime is the time of the latest key or mouse event.
+	lastInputTime xproto.Timestamp
+
+	// grabbedButton is the button passed to GrabButton, or mouse.ButtonNone
+	// if the pointer is not grabbed.
+	grabbedButton mouse.Button
+
+	// pendingSize is the size.Event for the window's latest size, and
+	// resizeTimer, if non-nil, sends it when the window has not been resized
+	// for resizeDebounce.
+	pendingSize size.Event
+	resizeTimer *time.Timer
+
+	// fadeGen is incremented by every fade and SetOpacity call, so that an
+	// earlier fade can see that it has been superseded.
+	fadeGen uint32
+
+	// protocols holds the handlers set by OnProtocol.
+	protocols map[xproto.Atom]func(data [5]uint32)
+
+	// state is the window's _NET_WM_STATE. stateChanged, if non-nil, is
+	// closed when state changes.
+	state        screen.WindowState
+	stateChanged chan struct{}
+
+	// focused is whether the window has the keyboard focus, and
+	// noPointerAccel is whether SetPointerAcceleration(false) was called.
+	focused        bool
+	noPointerAccel bool
+
+	// xembedFocus is the window embedded in this one, by Embed, that has
+	// the keyboard focus within it, or zero.
+	xembedFocus xproto.Window
+
+	// frameInterval is the minimum time between the paint.Events sent by
+	// RequestPaint, or zero for no minimum. lastPaint is when RequestPaint
+	// last sent one, and paintTimer, if non-nil, sends the next one.
+	frameInterval time.Duration
+	lastPaint     time.Time
+	paintTimer    *time.Timer
+
+	// imageCursor is the cursor set by SetCursorImage, or zero.
+	imageCursor xproto.Cursor
+
+	// transientFor is the window set by SetTransientFor, and modal is whether
+	// SetModal(true) was called. nModal is the number of modal windows open
+	// that are transient for this window.
+	transientFor *windowImpl
+	modal        bool
+	nModal       int
+
+	// back is the back buffer that drawing operations use. front, if
+	// non-nil, holds a copy of the last published frame, which is what
+	// expose events show. wantSize is the size of the window, which back is
+	// resized to on the next drawing operation.
+	back     *backBuffer
+	front    *backBuffer
+	wantSize image.Point
+
+	// clips is the stack of clip rectangles pushed by PushClip. Each element
+	// is already intersected with the one below it.
+	clips []image.Rectangle
+
+	// gestureRotation is the rotation, in degrees, of the pinch gesture in
+	// progress. It is only accessed by the screen's event loop.
+	gestureRotation float64
 }
 
 func (w *windowImpl) Release() {
 	w.mu.Lock()
 	released := w.released
 	w.released = true
+	keyboardGrabbed := w.keyboardGrabbed
+	w.keyboardGrabbed = false
+	grabbedButton := w.grabbedButton
+	w.grabbedButton = mouse.ButtonNone
+	imageCursor := w.imageCursor
+	w.imageCursor = 0
+	if w.stateChanged != nil {
+		close(w.stateChanged)
+		w.stateChanged = nil
+	}
+	if w.paintTimer != nil {
+		w.paintTimer.Stop()
+		w.paintTimer = nil
+	}
+	if w.resizeTimer != nil {
+		w.resizeTimer.Stop()
+		w.resizeTimer = nil
+	}
+	var modalParent *windowImpl
+	if !released && w.modal {
+		modalParent = w.transientFor
+	}
+	back, front := w.back, w.front
+	w.front = nil
+	restoreAccel := !released && w.focused && w.noPointerAccel
 	w.mu.Unlock()
 
+	if restoreAccel {
+		if err := w.s.restorePointerAccel(); err != nil {
+			log.Print(err)
+		}
+	}
+
+	if modalParent != nil {
+		modalParent.addModal(-1)
+	}
+
 	// TODO: call w.lifecycler.SetDead and w.lifecycler.SendEvent, a la
 	// handling atomWMDeleteWindow?
 
 	if released {
 		return
 	}
-	render.FreePicture(w.s.xc, w.xp)
+	w.unembedAll()
+	w.s.clearClipboards(w)
+	if keyboardGrabbed {
+		xproto.UngrabKeyboard(w.s.xc, xproto.TimeCurrentTime)
+	}
+	if grabbedButton != mouse.ButtonNone {
+		xproto.UngrabPointer(w.s.xc, xproto.TimeCurrentTime)
+	}
+	if imageCursor != 0 {
+		xproto.FreeCursor(w.s.xc, imageCursor)
+	}
+	w.s.releaseBackBuffer(back)
+	if front != nil {
+		w.s.releaseBackBuffer(front)
+	}
+	xproto.FreeGC(w.s.xc, w.frontG)
 	xproto.FreeGC(w.s.xc, w.xg)
 	xproto.DestroyWindow(w.s.xc, w.xw)
+	if w.colormap != 0 {
+		xproto.FreeColormap(w.s.xc, w.colormap)
+	}
 }
 
 func (w *windowImpl) Upload(dp image.Point, src screen.Buffer, sr image.Rectangle) {
-	src.(*bufferImpl).upload(xproto.Drawable(w.xw), w.xg, w.s.xsi.RootDepth, dp, sr)
+	uploadBuffer(src).upload(xproto.Drawable(w.target().xm), w.xg, w.depth, dp, sr)
 }
 
 func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
-	fill(w.s.xc, w.xp, dr, src, op)
+	fill(w.s.xc, w.target().xp, dr, src, op)
 }
 
 func (w *windowImpl) DrawUniform(src2dst f64.Aff3, src color.Color, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
-	w.s.drawUniform(w.xp, &src2dst, src, sr, op, opts)
+	if opts != nil && !opts.Clip.Empty() {
+		w.PushClip(opts.Clip)
+		defer w.PopClip()
+	}
+	w.s.drawUniform(w.target().xp, &src2dst, src, sr, op, opts)
 }
 
 func (w *windowImpl) Draw(src2dst f64.Aff3, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
-	src.(*textureImpl).draw(w.xp, &src2dst, sr, op, opts)
+	if opts != nil && !opts.Clip.Empty() {
+		w.PushClip(opts.Clip)
+		defer w.PopClip()
+	}
+	src.(*textureImpl).draw(w.target().xp, &src2dst, sr, op, opts)
 }
 
 func (w *windowImpl) Copy(dp image.Point, src screen.Texture, sr image.Rectangle, op draw.Op, opts *screen.DrawOptions) {
+	if opts != nil && !opts.Clip.Empty() {
+		w.PushClip(opts.Clip)
+		defer w.PopClip()
+	}
+	// w.xg has the same clip rectangles as the back buffer's picture.
+	if src.(*textureImpl).copyArea(xproto.Drawable(w.target().xm), w.pictformat, w.xg, dp, sr, op, opts) {
+		return
+	}
 	drawer.Copy(w, dp, src, sr, op, opts)
 }
 
@@ -90,9 +266,32 @@ func (w *windowImpl) Scale(dr image.Rectangle, src screen.Texture, sr image.Rect
 	drawer.Scale(w, dr, src, sr, op, opts)
 }
 
+func (w *windowImpl) ScaleAspect(dr image.Rectangle, src screen.Texture, sr image.Rectangle, fit screen.ScaleFit, op draw.Op) {
+	drawer.ScaleAspect(w, dr, src, sr, fit, op)
+}
+
 func (w *windowImpl) Publish() screen.PublishResult {
-	// TODO: implement a back buffer, and copy or flip that here to the front
-	// buffer.
+	w.target()
+
+	w.mu.Lock()
+	if !w.released {
+		w.publish()
+	}
+	w.nPublished++
+	lastInputTime := uint32(w.lastInputTime)
+	w.mu.Unlock()
+
+	// Publishing copies the back buffer to the window, leaving the back
+	// buffer's contents as they were.
+	//
+	// The xgb package writes each request to the connection as it is made,
+	// so there is nothing to flush when skipping the sync below.
+	if w.unthrottled {
+		return screen.PublishResult{
+			BackBufferPreserved: true,
+			LastInputTime:       lastInputTime,
+		}
+	}
 
 	// This sync isn't needed to flush the outgoing X11 requests. Instead, it
 	// acts as a form of flow control. Outgoing requests can be quite small on
@@ -101,19 +300,56 @@ func (w *windowImpl) Publish() screen.PublishResult {
 	// million source and destination pixels). Without this sync, the Go X11
 	// client could easily end up sending work at a faster rate than the X11
 	// server can serve.
+	//
+	// As the server processes requests in order, the time spent waiting is
+	// roughly how long the server took to do the work for this frame.
+	start := time.Now()
 	w.s.xc.Sync()
 
-	return screen.PublishResult{}
+	return screen.PublishResult{
+		BackBufferPreserved: true,
+		FrameTime:           time.Since(start),
+		LastInputTime:       lastInputTime,
+	}
+}
+
+func (w *windowImpl) setLastInputTime(t xproto.Timestamp) {
+	w.mu.Lock()
+	w.lastInputTime = t
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) FramesPublished() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nPublished
 }
 
 func (w *windowImpl) SetTitle(title string) error {
-	buf := []byte(title)
-	return xproto.ChangePropertyChecked(w.s.xc, xproto.PropModeReplace, w.xw, w.s.atomNetWMName, w.s.atomUTF8String, 8, uint32(len(buf)), buf).Check()
+	w.s.setStringProperty(w.xw, w.s.atomNetWMName, w.s.atomUTF8String, title)
+	return nil
+}
+
+func (w *windowImpl) Title() (string, error) {
+	title, ok, err := w.s.getStringProperty(w.xw, w.s.atomNetWMName, w.s.atomUTF8String)
+	if err != nil || ok {
+		return title, err
+	}
+	// Fall back to the ICCCM WM_NAME property, which is usually Latin-1.
+	title, _, err = w.s.getStringProperty(w.xw, xproto.AtomWmName, xproto.AtomString)
+	return title, err
 }
 
 func (w *windowImpl) SetCursor(cursor screen.Cursor) error {
 	if cursorId, ok := w.s.cursorCache[cursor]; ok {
 		xproto.ChangeWindowAttributes(w.s.xc, w.xw, xproto.CwCursor, []uint32{uint32(cursorId)})
+		w.mu.Lock()
+		old := w.imageCursor
+		w.imageCursor = 0
+		w.mu.Unlock()
+		if old != 0 {
+			xproto.FreeCursor(w.s.xc, old)
+		}
 	}
 	return nil
 }
@@ -128,6 +364,13 @@ func (w *windowImpl) WarpMouse(p image.Point) error {
 		return nil
 	}
 
+	if w.reportDIP {
+		p = image.Point{
+			X: int(math.Round(float64(float32(p.X) * w.s.pixelsPerPt))),
+			Y: int(math.Round(float64(float32(p.Y) * w.s.pixelsPerPt))),
+		}
+	}
+
 	screen := xproto.Setup(w.s.xc).DefaultScreen(w.s.xc)
 	tp, err := w.translateToScreen(screen, p)
 	if err != nil {
@@ -137,6 +380,277 @@ func (w *windowImpl) WarpMouse(p image.Point) error {
 	return wpc.Check()
 }
 
+func (w *windowImpl) PushClip(r image.Rectangle) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := len(w.clips); n > 0 {
+		r = r.Intersect(w.clips[n-1])
+	}
+	w.clips = append(w.clips, r)
+	w.setClip(r, true)
+}
+
+func (w *windowImpl) PopClip() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(w.clips)
+	if n == 0 {
+		return
+	}
+	w.clips = w.clips[:n-1]
+	if n == 1 {
+		w.setClip(image.Rectangle{}, false)
+	} else {
+		w.setClip(w.clips[n-2], true)
+	}
+}
+
+// setClip must only be called while holding w.mu.
+func (w *windowImpl) setClip(r image.Rectangle, enabled bool) {
+	if !enabled {
+		render.ChangePicture(w.s.xc, w.back.xp, render.CpClipMask, []uint32{0})
+		xproto.ChangeGC(w.s.xc, w.xg, xproto.GcClipMask, []uint32{0})
+		return
+	}
+	// An empty list of rectangles means that nothing is drawn.
+	var rects []xproto.Rectangle
+	if xr, ok := xRectangle(r); ok {
+		rects = append(rects, xr)
+	}
+	render.SetPictureClipRectangles(w.s.xc, w.back.xp, 0, 0, rects)
+	xproto.SetClipRectangles(w.s.xc, xproto.ClipOrderingUnsorted, w.xg, 0, 0, rects)
+}
+
+func (w *windowImpl) SetFrameExtents(left, top, right, bottom int) error {
+	if left < 0 || top < 0 || right < 0 || bottom < 0 {
+		return fmt.Errorf("x11driver: invalid frame extents (%d, %d, %d, %d)", left, top, right, bottom)
+	}
+	if left == 0 && top == 0 && right == 0 && bottom == 0 {
+		w.s.deleteProperty(w.xw, w.s.atomGTKFrameExtents)
+		return nil
+	}
+	w.s.setProperty32(w.xw, w.s.atomGTKFrameExtents, xproto.AtomCardinal,
+		uint32(left), uint32(right), uint32(top), uint32(bottom))
+	return nil
+}
+
+func (w *windowImpl) SetCompositorBypass(on bool) {
+	// Per the EWMH spec, 1 requests that the compositor unredirect the
+	// window and 2 requests that it keep compositing the window.
+	v := uint32(2)
+	if on {
+		v = 1
+	}
+	w.s.setProperty32(w.xw, w.s.atomNetWMBypassComp, xproto.AtomCardinal, v)
+}
+
+func (w *windowImpl) SetBackgroundColor(c color.Color) {
+	// NewWindow only accepts visuals of depth 24 and 32 with 8 bits each of
+	// red, green and blue in xRGB order.
+	r, g, b, _ := c.RGBA()
+	pixel := (r>>8)<<16 | (g>>8)<<8 | (b >> 8)
+	if w.depth == 32 {
+		pixel |= 0xff << 24
+	}
+	xproto.ChangeWindowAttributes(w.s.xc, w.xw, xproto.CwBackPixel, []uint32{pixel})
+}
+
+func (w *windowImpl) Lower() error {
+	err := xproto.ConfigureWindowChecked(w.s.xc, w.xw, xproto.ConfigWindowStackMode, []uint32{
+		xproto.StackModeBelow,
+	}).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.ConfigureWindow failed: %v", err)
+	}
+	return nil
+}
+
+func (w *windowImpl) StackBelow(other screen.Window) error {
+	o, ok := other.(*windowImpl)
+	if !ok || o.s != w.s {
+		return errors.New("x11driver: StackBelow sibling was not created by this screen")
+	}
+	err := xproto.ConfigureWindowChecked(w.s.xc, w.xw, xproto.ConfigWindowSibling|xproto.ConfigWindowStackMode, []uint32{
+		uint32(o.xw),
+		xproto.StackModeBelow,
+	}).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.ConfigureWindow failed: %v", err)
+	}
+	return nil
+}
+
+func (w *windowImpl) SetTransientFor(parent screen.Window) error {
+	var p *windowImpl
+	if parent != nil {
+		var ok bool
+		p, ok = parent.(*windowImpl)
+		if !ok || p.s != w.s {
+			return errors.New("x11driver: SetTransientFor parent was not created by this screen")
+		}
+	}
+
+	w.mu.Lock()
+	old := w.transientFor
+	w.transientFor = p
+	modal := w.modal
+	w.mu.Unlock()
+
+	if modal {
+		if old != nil {
+			old.addModal(-1)
+		}
+		if p != nil {
+			p.addModal(+1)
+		}
+	}
+
+	if p == nil {
+		w.s.deleteProperty(w.xw, xproto.AtomWmTransientFor)
+		return nil
+	}
+	w.s.setProperty32(w.xw, xproto.AtomWmTransientFor, xproto.AtomWindow, uint32(p.xw))
+	return nil
+}
+
+func (w *windowImpl) SetModal(on bool) {
+	w.mu.Lock()
+	changed := w.modal != on
+	w.modal = on
+	parent := w.transientFor
+	w.mu.Unlock()
+
+	if changed && parent != nil {
+		if on {
+			parent.addModal(+1)
+		} else {
+			parent.addModal(-1)
+		}
+	}
+
+	w.changeWMState(on, w.s.atomNetWMStateModal)
+}
+
+// sourceNormalApp is the EWMH source indication for requests from normal
+// applications, as opposed to pagers and taskbars.
+const sourceNormalApp = 1
+
+// changeWMState adds or removes state from the window's _NET_WM_STATE. The
+// window is already mapped, so per the EWMH spec, this asks the window manager
+// to change the state instead of setting the property.
+func (w *windowImpl) changeWMState(add bool, state xproto.Atom) {
+	const (
+		netWMStateRemove = 0
+		netWMStateAdd    = 1
+	)
+	action := uint32(netWMStateRemove)
+	if add {
+		action = netWMStateAdd
+	}
+	w.sendWMMessage(w.s.atomNetWMState, action, uint32(state), 0, sourceNormalApp, 0)
+}
+
+// sendWMMessage sends a client message about the window to the window
+// manager.
+func (w *windowImpl) sendWMMessage(typ xproto.Atom, data ...uint32) {
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: w.xw,
+		Type:   typ,
+		Data:   xproto.ClientMessageDataUnionData32New(data),
+	}
+	xproto.SendEvent(w.s.xc, false, w.s.xsi.Root,
+		xproto.EventMaskSubstructureNotify|xproto.EventMaskSubstructureRedirect,
+		string(ev.Bytes()))
+}
+
+func (w *windowImpl) addModal(delta int) {
+	w.mu.Lock()
+	w.nModal += delta
+	w.mu.Unlock()
+}
+
+// blockedByModal returns whether a modal window that is transient for w is
+// open, in which case w should not be sent key or mouse events.
+func (w *windowImpl) blockedByModal() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nModal > 0
+}
+
+func (w *windowImpl) GrabKeyboard() error {
+	r, err := xproto.GrabKeyboard(w.s.xc, false, w.xw, xproto.TimeCurrentTime, xproto.GrabModeAsync, xproto.GrabModeAsync).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.GrabKeyboard failed: %v", err)
+	}
+	if r.Status != xproto.GrabStatusSuccess {
+		return fmt.Errorf("x11driver: xproto.GrabKeyboard failed: status %d", r.Status)
+	}
+	w.mu.Lock()
+	w.keyboardGrabbed = true
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *windowImpl) UngrabKeyboard() error {
+	w.mu.Lock()
+	keyboardGrabbed := w.keyboardGrabbed
+	w.keyboardGrabbed = false
+	w.mu.Unlock()
+
+	if !keyboardGrabbed {
+		return nil
+	}
+	return xproto.UngrabKeyboardChecked(w.s.xc, xproto.TimeCurrentTime).Check()
+}
+
+func (w *windowImpl) GrabButton(btn mouse.Button) error {
+	if btn <= mouse.ButtonNone {
+		return fmt.Errorf("x11driver: invalid button %d", btn)
+	}
+	r, err := xproto.GrabPointer(w.s.xc, false, w.xw,
+		xproto.EventMaskButtonPress|xproto.EventMaskButtonRelease|xproto.EventMaskPointerMotion,
+		xproto.GrabModeAsync, xproto.GrabModeAsync, 0, 0, xproto.TimeCurrentTime).Reply()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.GrabPointer failed: %v", err)
+	}
+	if r.Status != xproto.GrabStatusSuccess {
+		return fmt.Errorf("x11driver: xproto.GrabPointer failed: status %d", r.Status)
+	}
+	w.mu.Lock()
+	w.grabbedButton = btn
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *windowImpl) UngrabButton() error {
+	w.mu.Lock()
+	grabbedButton := w.grabbedButton
+	w.grabbedButton = mouse.ButtonNone
+	w.mu.Unlock()
+
+	if grabbedButton == mouse.ButtonNone {
+		return nil
+	}
+	return xproto.UngrabPointerChecked(w.s.xc, xproto.TimeCurrentTime).Check()
+}
+
+// handleButtonGrab ends a grab obtained by GrabButton if b, which has just
+// been released, is the grabbed button.
+func (w *windowImpl) handleButtonGrab(b xproto.Button) {
+	w.mu.Lock()
+	grabbed := w.grabbedButton != mouse.ButtonNone && w.grabbedButton == mouse.Button(b)
+	w.mu.Unlock()
+
+	if grabbed {
+		if err := w.UngrabButton(); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
 func (w *windowImpl) translateToScreen(screen *xproto.ScreenInfo, p image.Point) (r image.Point, err error) {
 	tcc := xproto.TranslateCoordinates(w.s.xc, w.xw, screen.Root, int16(p.X), int16(p.Y))
 	tcr, err := tcc.Reply()
@@ -148,33 +662,133 @@ func (w *windowImpl) translateToScreen(screen *xproto.ScreenInfo, p image.Point)
 	return
 }
 
+// setProtocols sets the window's WM_PROTOCOLS property.
+func (w *windowImpl) setProtocols() {
+	protocols := []xproto.Atom{w.s.atomNetWMPing}
+	if !w.noFocus {
+		protocols = append(protocols, w.s.atomWMTakeFocus)
+	}
+	if !w.preventClose {
+		protocols = append(protocols, w.s.atomWMDeleteWindow)
+	}
+	w.s.mu.Lock()
+	if w.s.sessionID != "" {
+		protocols = append(protocols, w.s.atomWMSaveYourself)
+	}
+	w.s.mu.Unlock()
+	w.mu.Lock()
+	for atom := range w.protocols {
+		protocols = append(protocols, atom)
+	}
+	w.mu.Unlock()
+	w.s.setProperty(w.xw, w.s.atomWMProtocols, protocols...)
+}
+
+// setNoFocus tells the window manager to never give the window the keyboard
+// focus, per the ICCCM's "No Input" focus model.
+func (w *windowImpl) setNoFocus() {
+	const (
+		inputHint    = 1 << 0
+		wmHintsWords = 9
+	)
+	hints := make([]uint32, wmHintsWords)
+	hints[0] = inputHint
+	hints[1] = 0 // False: the window does not accept input.
+	w.s.setProperty32(w.xw, xproto.AtomWmHints, xproto.AtomWmHints, hints...)
+	// A zero _NET_WM_USER_TIME asks the window manager not to focus the
+	// window when it is mapped.
+	w.s.setProperty32(w.xw, w.s.atomNetWMUserTime, xproto.AtomCardinal, 0)
+	w.s.setProperty(w.xw, w.s.atomNetWMWindowType, w.s.atomNetWMWindowTypeUtility)
+}
+
+// poster is a lifecycler.Sender that posts, instead of sends, lifecycle
+// events to a window, so that the event loop never blocks on a full queue.
+type poster struct {
+	*windowImpl
+}
+
+func (p poster) Send(event interface{}) { p.Post(event) }
+
 func (w *windowImpl) handleConfigureNotify(ev xproto.ConfigureNotifyEvent) {
 	// TODO: does the order of these lifecycle and size events matter? Should
 	// they really be a single, atomic event?
 	w.lifecycler.SetVisible((int(ev.X)+int(ev.Width)) > 0 && (int(ev.Y)+int(ev.Height)) > 0)
-	w.lifecycler.SendEvent(w, nil)
+	w.lifecycler.SendEvent(poster{w}, nil)
 
 	newWidth, newHeight := int(ev.Width), int(ev.Height)
 	if w.width == newWidth && w.height == newHeight {
 		return
 	}
+	firstSize := w.width == 0 && w.height == 0
 	w.width, w.height = newWidth, newHeight
-	w.Send(size.Event{
+	e := size.Event{
 		WidthPx:     newWidth,
 		HeightPx:    newHeight,
 		WidthPt:     geom.Pt(newWidth),
 		HeightPt:    geom.Pt(newHeight),
 		PixelsPerPt: w.s.pixelsPerPt,
-	})
+	}
+	if w.reportDIP {
+		// The mouse event coordinates are in points, so the window's size
+		// must be too.
+		e.WidthPt = geom.Pt(float32(newWidth) / w.s.pixelsPerPt)
+		e.HeightPt = geom.Pt(float32(newHeight) / w.s.pixelsPerPt)
+	}
+	// The first size.Event is never delayed, as programs typically wait for
+	// it before painting at all.
+	debounce := w.resizeDebounce > 0 && !firstSize
+
+	w.mu.Lock()
+	w.wantSize = image.Point{newWidth, newHeight}
+	w.pendingSize = e
+	if debounce && !w.released {
+		if w.resizeTimer == nil {
+			w.resizeTimer = time.AfterFunc(w.resizeDebounce, w.sendPendingSize)
+		} else {
+			w.resizeTimer.Reset(w.resizeDebounce)
+		}
+	}
+	w.mu.Unlock()
+
+	if !debounce {
+		w.Post(e)
+	}
+}
+
+// sendPendingSize sends a size.Event for the latest size, once the window has
+// not been resized for w.resizeDebounce.
+func (w *windowImpl) sendPendingSize() {
+	w.mu.Lock()
+	e, released := w.pendingSize, w.released
+	w.mu.Unlock()
+	if !released {
+		w.Post(e)
+	}
 }
 
 func (w *windowImpl) handleExpose() {
-	w.Send(paint.Event{})
+	w.present()
+	w.Post(paint.Event{})
+}
+
+func (w *windowImpl) handleSelectionClear(selection xproto.Atom) {
+	w.s.clearClipboard(selection, w)
+	name, err := w.s.atomName(selection)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	w.Post(screen.ClipboardLostEvent{
+		Selection: name,
+	})
 }
 
 func (w *windowImpl) handleKey(detail xproto.Keycode, state uint16, dir key.Direction) {
+	if w.blockedByModal() {
+		return
+	}
 	r, c := w.s.keysyms.Lookup(uint8(detail), state)
-	w.Send(key.Event{
+	w.Post(key.Event{
 		Rune:      r,
 		Code:      c,
 		Modifiers: x11key.KeyModifiers(state),
@@ -183,8 +797,15 @@ func (w *windowImpl) handleKey(detail xproto.Keycode, state uint16, dir key.Dire
 }
 
 func (w *windowImpl) handleMouse(x, y int16, b xproto.Button, state uint16, dir mouse.Direction) {
+	if w.blockedByModal() {
+		return
+	}
 	// TODO: should a mouse.Event have a separate MouseModifiers field, for
 	// which buttons are pressed during a mouse move?
+	//
+	// The X server has already applied the pointer mapping, so b is a
+	// logical button. Logical buttons 4 to 7 are the scroll wheel, however
+	// the physical buttons are mapped to them.
 	btn := mouse.Button(b)
 	switch btn {
 	case 4:
@@ -202,9 +823,14 @@ func (w *windowImpl) handleMouse(x, y int16, b xproto.Button, state uint16, dir
 		}
 		dir = mouse.DirStep
 	}
-	w.Send(mouse.Event{
-		X:         float32(x),
-		Y:         float32(y),
+	fx, fy := float32(x), float32(y)
+	if w.reportDIP {
+		fx /= w.s.pixelsPerPt
+		fy /= w.s.pixelsPerPt
+	}
+	w.Post(mouse.Event{
+		X:         fx,
+		Y:         fy,
 		Button:    btn,
 		Modifiers: x11key.KeyModifiers(state),
 		Direction: dir,
@@ -0,0 +1,49 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/mouse"
+)
+
+func TestUploadWhileQueueFull(t *testing.T) {
+	s := &screenImpl{
+		uploads: map[uint16]chan struct{}{},
+	}
+	w := &windowImpl{s: s}
+	s.windows = map[xproto.Window]*windowImpl{1: w}
+	w.SetLimit(1, screen.OverflowBlock)
+	w.Send(key.Event{Rune: 'a', Direction: key.DirPress})
+
+	// An upload is waiting for its shm.CompletionEvent, which the event loop
+	// handles after sending a mouse event to the full queue.
+	completion := make(chan struct{})
+	s.uploads[1] = completion
+	go func() {
+		w.handleMouse(1, 2, xproto.ButtonIndex1, 0, mouse.DirPress)
+		s.mu.Lock()
+		s.completionKeys = append(s.completionKeys, 1)
+		s.handleCompletions()
+		s.mu.Unlock()
+	}()
+	select {
+	case <-completion:
+	case <-time.After(10 * time.Second):
+		t.Fatal("upload did not complete")
+	}
+
+	w.NextEvent()
+	want := mouse.Event{X: 1, Y: 2, Button: mouse.ButtonLeft, Direction: mouse.DirPress}
+	if got := w.NextEvent(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// allWorkspaces is the _NET_WM_DESKTOP value for a window that appears on
+// every workspace.
+const allWorkspaces = 0xffffffff
+
+func (w *windowImpl) SetWorkspace(n int) error {
+	if n < 0 {
+		return fmt.Errorf("x11driver: invalid workspace %d", n)
+	}
+	w.setWorkspace(uint32(n))
+	return nil
+}
+
+func (w *windowImpl) SetAllWorkspaces(all bool) error {
+	if all {
+		w.setWorkspace(allWorkspaces)
+		return nil
+	}
+	// Move the window back to just the workspace that is showing.
+	s := w.s
+	v, err := s.getProperty32(s.xsi.Root, s.atomNetCurrentDesktop, xproto.AtomCardinal)
+	if err != nil {
+		return err
+	}
+	if len(v) != 1 {
+		return errors.New("x11driver: window manager does not support _NET_CURRENT_DESKTOP")
+	}
+	w.setWorkspace(v[0])
+	return nil
+}
+
+// setWorkspace sets the window's _NET_WM_DESKTOP property, which the window
+// manager reads when the window is mapped, and asks the window manager to
+// move the window, in case it is already mapped.
+func (w *windowImpl) setWorkspace(desktop uint32) {
+	w.s.setProperty32(w.xw, w.s.atomNetWMDesktop, xproto.AtomCardinal, desktop)
+	w.sendWMMessage(w.s.atomNetWMDesktop, desktop, sourceNormalApp)
+}
+
+func (w *windowImpl) Workspace() (int, error) {
+	v, err := w.s.getProperty32(w.xw, w.s.atomNetWMDesktop, xproto.AtomCardinal)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != 1 {
+		return 0, errors.New("x11driver: window has no workspace")
+	}
+	if v[0] == allWorkspaces {
+		return -1, nil
+	}
+	return int(v[0]), nil
+}
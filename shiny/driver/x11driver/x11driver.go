@@ -12,8 +12,8 @@ package x11driver // import "golang.org/x/exp/shiny/driver/x11driver"
 
 import (
 	"fmt"
+	"log"
 
-	"github.com/BurntSushi/xgb"
 	"github.com/BurntSushi/xgb/render"
 	"github.com/BurntSushi/xgb/shm"
 
@@ -28,15 +28,22 @@ import (
 // specific libraries require being on 'the main thread'. It returns when f
 // returns.
 func Main(f func(screen.Screen)) {
-	if err := main(f); err != nil {
+	MainDisplay("", f)
+}
+
+// MainDisplay is like Main, but connects to the named X11 display, such as
+// "host:0.1", instead of the one named by the DISPLAY environment variable.
+// An empty display means to use DISPLAY.
+func MainDisplay(display string, f func(screen.Screen)) {
+	if err := main(display, f); err != nil {
 		f(errscreen.Stub(err))
 	}
 }
 
-func main(f func(screen.Screen)) (retErr error) {
-	xc, err := xgb.NewConn()
+func main(display string, f func(screen.Screen)) (retErr error) {
+	xc, err := dial(display)
 	if err != nil {
-		return fmt.Errorf("x11driver: xgb.NewConn failed: %v", err)
+		return fmt.Errorf("x11driver: dial failed: %v", err)
 	}
 	defer func() {
 		if retErr != nil {
@@ -56,6 +63,10 @@ func main(f func(screen.Screen)) (retErr error) {
 		return err
 	}
 	f(s)
+	s.restoreModes()
+	if err := s.restorePointerAccel(); err != nil {
+		log.Print(err)
+	}
 	// TODO: tear down the s.run goroutine? It's probably not worth the
 	// complexity of doing it cleanly, if the app is about to exit anyway.
 	return nil
@@ -0,0 +1,322 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// An X Generic Event, which extensions such as XInput2 use, can be longer
+// than the 32 bytes that github.com/BurntSushi/xgb reads for every event.
+// xgb would then read the rest of the event as the start of the next
+// message, and lose track of the connection. To avoid that, the driver
+// connects to the X11 server itself, and gives xgb an xgeConn, which passes
+// xgb only the first 32 bytes of each generic event, and keeps the whole
+// event for the decoder that xgb calls for it.
+
+// xgeEvent is an X Generic Event.
+type xgeEvent struct {
+	// buf is the whole event, including the 32 byte header.
+	buf       []byte
+	extension byte
+	evtype    uint16
+}
+
+func (ev xgeEvent) Bytes() []byte { return ev.buf }
+func (ev xgeEvent) String() string {
+	return fmt.Sprintf("GenericEvent {Extension: %d, EventType: %d, Length: %d}", ev.extension, ev.evtype, len(ev.buf))
+}
+
+// xgeConn is a connection to the X11 server that frames the messages that
+// the server sends, so that xgb reads every generic event as 32 bytes.
+type xgeConn struct {
+	net.Conn
+	r *bufio.Reader
+
+	// authName and authData are the authorization that the connection
+	// setup request sent by xgb is replaced with, if authName is not empty.
+	// wroteSetup is whether that request has been written.
+	authName   string
+	authData   []byte
+	wroteSetup bool
+
+	// readSetup is whether the connection setup reply has been read, and
+	// pending is what remains to be read of the current message.
+	readSetup bool
+	pending   []byte
+
+	// events holds the generic events that xgb has been given the first
+	// 32 bytes of, but not yet decoded, oldest first.
+	mu     sync.Mutex
+	events [][]byte
+}
+
+func newXGEConn(c net.Conn) *xgeConn {
+	return &xgeConn{
+		Conn: c,
+		r:    bufio.NewReader(c),
+	}
+}
+
+// dial connects to the named X11 display, like xgb.NewConnDisplay, but
+// through an xgeConn.
+func dial(display string) (*xgb.Conn, error) {
+	d, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+	nc, err := net.Dial(d.network, d.address)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %v", display, err)
+	}
+	c := newXGEConn(nc)
+	c.authName, c.authData, _ = readAuthority(d.host, d.number)
+
+	// The decoder is registered before the connection starts reading
+	// messages, as xgb.NewEventFuncs is not safe for concurrent use.
+	xgb.NewEventFuncs[xproto.GeGeneric] = c.newEvent
+
+	// xgb looks for the authorization of the wrong display, as it does not
+	// know which display a net.Conn is for, and logs its failure to find
+	// it. The authorization that it sends is replaced with the right one.
+	w := xgb.Logger.Writer()
+	xgb.Logger.SetOutput(ioutil.Discard)
+	xc, err := xgb.NewConnNet(c)
+	xgb.Logger.SetOutput(w)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	xc.DisplayNumber, _ = strconv.Atoi(d.number)
+	xc.DefaultScreen = d.screen
+	return xc, nil
+}
+
+// xDisplay is a parsed X11 display name, such as ":0" or "host:1.0".
+type xDisplay struct {
+	network, address string
+	host, number     string
+	screen           int
+}
+
+// parseDisplay parses an X11 display name in the same way as xgb. An empty
+// name means the DISPLAY environment variable.
+func parseDisplay(display string) (xDisplay, error) {
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+	if display == "" {
+		return xDisplay{}, errors.New("empty display string")
+	}
+	bad := errors.New("bad display string: " + display)
+
+	colon := strings.LastIndex(display, ":")
+	if colon < 0 {
+		return xDisplay{}, bad
+	}
+	var d xDisplay
+	var protocol, socket string
+	if display[0] == '/' {
+		socket = display[:colon]
+	} else if slash := strings.LastIndex(display, "/"); slash >= 0 {
+		protocol = display[:slash]
+		d.host = display[slash+1 : colon]
+	} else {
+		d.host = display[:colon]
+	}
+
+	d.number = display[colon+1:]
+	if dot := strings.LastIndex(d.number, "."); dot >= 0 {
+		n, err := strconv.Atoi(d.number[dot+1:])
+		if err != nil {
+			return xDisplay{}, bad
+		}
+		d.number, d.screen = d.number[:dot], n
+	}
+	n, err := strconv.Atoi(d.number)
+	if err != nil || n < 0 {
+		return xDisplay{}, bad
+	}
+
+	switch {
+	case socket != "":
+		d.network, d.address = "unix", socket+":"+d.number
+	case d.host != "" && d.host != "unix":
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		d.network, d.address = protocol, d.host+":"+strconv.Itoa(6000+n)
+	default:
+		d.host = ""
+		d.network, d.address = "unix", "/tmp/.X11-unix/X"+d.number
+	}
+	return d, nil
+}
+
+// readAuthority returns the MIT-MAGIC-COOKIE-1 authorization for the given
+// host and display number from the Xauthority file.
+func readAuthority(host, display string) (name string, data []byte, err error) {
+	const (
+		familyLocal = 256
+		familyWild  = 65535
+	)
+	if host == "" || host == "localhost" {
+		if host, err = os.Hostname(); err != nil {
+			return "", nil, err
+		}
+	}
+	fname := os.Getenv("XAUTHORITY")
+	if fname == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			return "", nil, errors.New("Xauthority not found: $XAUTHORITY, $HOME not set")
+		}
+		fname = home + "/.Xauthority"
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	// Each entry is a big-endian family, followed by the address, display
+	// number, authorization name and data, each prefixed by its length.
+	readBytes := func() ([]byte, error) {
+		var n uint16
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		_, err := io.ReadFull(r, b)
+		return b, err
+	}
+	for {
+		var family uint16
+		if err := binary.Read(r, binary.BigEndian, &family); err != nil {
+			return "", nil, err
+		}
+		var fields [4][]byte
+		for i := range fields {
+			if fields[i], err = readBytes(); err != nil {
+				return "", nil, err
+			}
+		}
+		addr, disp := string(fields[0]), string(fields[1])
+		if (family == familyWild || (family == familyLocal && addr == host)) &&
+			(disp == "" || disp == display) && string(fields[2]) == "MIT-MAGIC-COOKIE-1" {
+			return string(fields[2]), fields[3], nil
+		}
+	}
+}
+
+func (c *xgeConn) Write(b []byte) (int, error) {
+	// The first request is the connection setup request, written by
+	// xgb.NewConnNet before it starts its goroutines, so this needs no lock.
+	if c.wroteSetup || c.authName == "" {
+		c.wroteSetup = true
+		return c.Conn.Write(b)
+	}
+	c.wroteSetup = true
+
+	pad := func(n int) int { return (n + 3) &^ 3 }
+	buf := make([]byte, 12+pad(len(c.authName))+pad(len(c.authData)))
+	buf[0] = 0x6c // Little-endian, as xgb uses.
+	xgb.Put16(buf[2:], 11)
+	xgb.Put16(buf[6:], uint16(len(c.authName)))
+	xgb.Put16(buf[8:], uint16(len(c.authData)))
+	copy(buf[12:], c.authName)
+	copy(buf[12+pad(len(c.authName)):], c.authData)
+	if _, err := c.Conn.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Read is only called by xgb's goroutine that reads messages, one at a
+// time, and decodes them in turn.
+func (c *xgeConn) Read(b []byte) (int, error) {
+	if len(c.pending) == 0 {
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// next reads the next message from the X11 server into c.pending.
+func (c *xgeConn) next() error {
+	if !c.readSetup {
+		// The connection setup reply has an 8 byte header, followed by
+		// its length, in 4 byte units, of data.
+		head := make([]byte, 8)
+		if _, err := io.ReadFull(c.r, head); err != nil {
+			return err
+		}
+		buf := make([]byte, 8+4*int(xgb.Get16(head[6:])))
+		copy(buf, head)
+		if _, err := io.ReadFull(c.r, buf[8:]); err != nil {
+			return err
+		}
+		c.readSetup = true
+		c.pending = buf
+		return nil
+	}
+
+	// Errors and events are 32 bytes. Replies and generic events are 32
+	// bytes followed by their length, in 4 byte units, of data.
+	buf := make([]byte, 32)
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		return err
+	}
+	if generic := buf[0]&0x7f == xproto.GeGeneric; generic || buf[0] == 1 {
+		if n := int(xgb.Get32(buf[4:])); n > 0 {
+			buf = append(buf, make([]byte, 4*n)...)
+			if _, err := io.ReadFull(c.r, buf[32:]); err != nil {
+				return err
+			}
+		}
+		if generic {
+			c.mu.Lock()
+			c.events = append(c.events, buf)
+			c.mu.Unlock()
+			buf = buf[:32]
+		}
+	}
+	c.pending = buf
+	return nil
+}
+
+// newEvent decodes a generic event, for xgb.NewEventFuncs. buf is the 32
+// bytes of the event that xgb read, and the whole event is the oldest one
+// in c.events.
+func (c *xgeConn) newEvent(buf []byte) xgb.Event {
+	c.mu.Lock()
+	if len(c.events) > 0 {
+		buf = c.events[0]
+		c.events = c.events[1:]
+	}
+	c.mu.Unlock()
+	return xgeEvent{
+		buf:       buf,
+		extension: buf[1],
+		evtype:    xgb.Get16(buf[8:]),
+	}
+}
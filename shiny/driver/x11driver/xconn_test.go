@@ -0,0 +1,139 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/BurntSushi/xgb"
+)
+
+// fakeConn is a net.Conn that reads from r and writes to w.
+type fakeConn struct {
+	net.Conn
+	r io.Reader
+	w bytes.Buffer
+}
+
+func (c *fakeConn) Read(b []byte) (int, error)  { return c.r.Read(b) }
+func (c *fakeConn) Write(b []byte) (int, error) { return c.w.Write(b) }
+
+// message returns an n byte message, starting with typ, and whose bytes 4 to
+// 8 are length, as for replies and generic events.
+func message(typ byte, n int, length uint32) []byte {
+	b := make([]byte, n)
+	b[0] = typ
+	xgb.Put32(b[4:], length)
+	for i := 8; i < n; i++ {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestXGEConn(t *testing.T) {
+	setup := make([]byte, 16)
+	setup[0] = 1 // Success.
+	xgb.Put16(setup[6:], 2)
+	event := message(12, 32, 0)            // Expose.
+	reply := message(1, 36, 1)             // A reply with 4 bytes of data.
+	generic := message(35, 44, 3)          // A generic event with 12 bytes of data.
+	short := message(35, 32, 0)            // A generic event with no data.
+	sent := message(0x80|35, 32, 0)        // A generic event from SendEvent.
+	errorMsg := message(0, 32, 0x12345678) // An error, whose length is not a length.
+	var stream []byte
+	for _, b := range [][]byte{setup, event, reply, generic, short, sent, errorMsg, event} {
+		stream = append(stream, b...)
+	}
+
+	fc := &fakeConn{r: bytes.NewReader(stream)}
+	c := newXGEConn(fc)
+	c.authName, c.authData = "MIT-MAGIC-COOKIE-1", []byte("0123456789abcdef")
+
+	// The connection setup request is replaced.
+	if n, err := c.Write(make([]byte, 12)); n != 12 || err != nil {
+		t.Fatalf("Write: got %d, %v, want 12, nil", n, err)
+	}
+	wantSetup := append([]byte{0x6c, 0, 11, 0, 0, 0, 18, 0, 16, 0, 0, 0}, "MIT-MAGIC-COOKIE-1\x00\x000123456789abcdef"...)
+	if got := fc.w.Bytes(); !bytes.Equal(got, wantSetup) {
+		t.Errorf("setup request: got %q, want %q", got, wantSetup)
+	}
+	fc.w.Reset()
+	c.Write([]byte{1, 2, 3, 4})
+	if got := fc.w.Bytes(); !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Errorf("request: got %v, want [1 2 3 4]", got)
+	}
+
+	// Read as xgb does.
+	read := func(n int) []byte {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(c, b); err != nil {
+			t.Fatalf("read %d bytes: %v", n, err)
+		}
+		return b
+	}
+	if got := append(read(8), read(8)...); !bytes.Equal(got, setup) {
+		t.Errorf("setup: got %v, want %v", got, setup)
+	}
+	if got := read(32); !bytes.Equal(got, event) {
+		t.Errorf("event: got %v, want %v", got, event)
+	}
+	if got := append(read(32), read(4)...); !bytes.Equal(got, reply) {
+		t.Errorf("reply: got %v, want %v", got, reply)
+	}
+	for _, want := range [][]byte{generic, short, sent} {
+		got := read(32)
+		if !bytes.Equal(got, want[:32]) {
+			t.Errorf("generic event header: got %v, want %v", got, want[:32])
+		}
+		ev := c.newEvent(got).(xgeEvent)
+		if !bytes.Equal(ev.buf, want) {
+			t.Errorf("generic event: got %v, want %v", ev.buf, want)
+		}
+		if ev.evtype != xgb.Get16(want[8:]) {
+			t.Errorf("generic event type: got %d, want %d", ev.evtype, xgb.Get16(want[8:]))
+		}
+	}
+	if got := read(32); !bytes.Equal(got, errorMsg) {
+		t.Errorf("error: got %v, want %v", got, errorMsg)
+	}
+	if got := read(32); !bytes.Equal(got, event) {
+		t.Errorf("event: got %v, want %v", got, event)
+	}
+	if _, err := c.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("at end: got %v, want EOF", err)
+	}
+}
+
+func TestParseDisplay(t *testing.T) {
+	testCases := []struct {
+		display string
+		want    xDisplay
+	}{
+		{":0", xDisplay{"unix", "/tmp/.X11-unix/X0", "", "0", 0}},
+		{":1.2", xDisplay{"unix", "/tmp/.X11-unix/X1", "", "1", 2}},
+		{"unix:3", xDisplay{"unix", "/tmp/.X11-unix/X3", "", "3", 0}},
+		{"host:2.1", xDisplay{"tcp", "host:6002", "host", "2", 1}},
+		{"tcp/host:1.0", xDisplay{"tcp", "host:6001", "host", "1", 0}},
+		{"/tmp/launch-12/:0", xDisplay{"unix", "/tmp/launch-12/:0", "", "0", 0}},
+	}
+	for _, tc := range testCases {
+		got, err := parseDisplay(tc.display)
+		if err != nil {
+			t.Errorf("%q: %v", tc.display, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %+v, want %+v", tc.display, got, tc.want)
+		}
+	}
+	for _, display := range []string{"host", ":", ":x", ":0.x", ":-1"} {
+		if _, err := parseDisplay(display); err == nil {
+			t.Errorf("%q: no error", display)
+		}
+	}
+}
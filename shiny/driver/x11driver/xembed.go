@@ -0,0 +1,267 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// These are the XEmbed protocol's messages and flags, from
+// https://specifications.freedesktop.org/xembed-spec/.
+const (
+	xembedEmbeddedNotify   = 0
+	xembedWindowActivate   = 1
+	xembedWindowDeactivate = 2
+	xembedFocusIn          = 4
+	xembedFocusOut         = 5
+
+	xembedFocusCurrent = 0
+
+	xembedVersion = 0
+	xembedMapped  = 1 << 0
+)
+
+// Per the XEmbed spec, the embedder keeps the X11 keyboard focus and
+// forwards key events to the embedded window that has the logical focus,
+// which is the one most recently embedded.
+
+func (w *windowImpl) Embed(foreign uint32) error {
+	s, xf := w.s, xproto.Window(foreign)
+	mapped, err := s.xembedMapped(xf)
+	if err != nil {
+		return err
+	}
+	// Listen for the foreign window being destroyed or reparented
+	// elsewhere, and for changes to its _XEMBED_INFO.
+	err = xproto.ChangeWindowAttributesChecked(s.xc, xf, xproto.CwEventMask,
+		[]uint32{xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange}).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.ChangeWindowAttributes failed: %v", err)
+	}
+
+	s.mu.Lock()
+	if s.embedded[xf] != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("x11driver: window %#x is already embedded", foreign)
+	}
+	if s.embedded == nil {
+		s.embedded = map[xproto.Window]*windowImpl{}
+	}
+	s.embedded[xf] = w
+	s.mu.Unlock()
+
+	if err := xproto.ReparentWindowChecked(s.xc, xf, w.xw, 0, 0).Check(); err != nil {
+		s.mu.Lock()
+		delete(s.embedded, xf)
+		s.mu.Unlock()
+		return fmt.Errorf("x11driver: xproto.ReparentWindow failed: %v", err)
+	}
+	// The save-set gives the foreign window back to the root window if this
+	// program exits without unembedding it.
+	xproto.ChangeSaveSet(s.xc, xproto.SetModeInsert, xf)
+	s.sendXEmbed(xf, xembedEmbeddedNotify, 0, uint32(w.xw), xembedVersion)
+	if mapped {
+		xproto.MapWindow(s.xc, xf)
+	}
+	w.mu.Lock()
+	focused := w.focused
+	old := w.xembedFocus
+	w.xembedFocus = xf
+	w.mu.Unlock()
+	if focused {
+		if old != 0 {
+			s.sendXEmbed(old, xembedFocusOut, 0, 0, 0)
+		}
+		s.sendXEmbed(xf, xembedWindowActivate, 0, 0, 0)
+		s.sendXEmbed(xf, xembedFocusIn, xembedFocusCurrent, 0, 0)
+	}
+	w.Post(screen.EmbedEvent{Window: foreign, Embedded: true})
+	return nil
+}
+
+func (w *windowImpl) Unembed(foreign uint32) error {
+	s, xf := w.s, xproto.Window(foreign)
+	s.mu.Lock()
+	embedded := s.embedded[xf] == w
+	delete(s.embedded, xf)
+	s.mu.Unlock()
+
+	if !embedded {
+		return fmt.Errorf("x11driver: window %#x is not embedded", foreign)
+	}
+	w.unembed(xf)
+	w.Post(screen.EmbedEvent{Window: foreign, Embedded: false})
+	return nil
+}
+
+// unembed gives xf back to the root window, per the XEmbed spec.
+func (w *windowImpl) unembed(xf xproto.Window) {
+	w.forgetEmbedded(xf)
+	xproto.UnmapWindow(w.s.xc, xf)
+	xproto.ReparentWindow(w.s.xc, xf, w.s.xsi.Root, 0, 0)
+	xproto.ChangeSaveSet(w.s.xc, xproto.SetModeDelete, xf)
+}
+
+// unembedAll unembeds every window embedded in w, which is being released.
+// Destroying w would otherwise destroy them too.
+func (w *windowImpl) unembedAll() {
+	for _, xf := range w.s.embeddedIn(w, true) {
+		w.unembed(xf)
+	}
+}
+
+func (w *windowImpl) SetEmbeddedBounds(foreign uint32, r image.Rectangle) error {
+	xf := xproto.Window(foreign)
+	if w.s.embedder(xf) != w {
+		return fmt.Errorf("x11driver: window %#x is not embedded", foreign)
+	}
+	if _, ok := xRectangle(r); !ok || r.Empty() {
+		return errors.New("x11driver: invalid embedded window bounds")
+	}
+	err := xproto.ConfigureWindowChecked(w.s.xc, xf,
+		xproto.ConfigWindowX|xproto.ConfigWindowY|xproto.ConfigWindowWidth|xproto.ConfigWindowHeight,
+		[]uint32{uint32(r.Min.X), uint32(r.Min.Y), uint32(r.Dx()), uint32(r.Dy())}).Check()
+	if err != nil {
+		return fmt.Errorf("x11driver: xproto.ConfigureWindow failed: %v", err)
+	}
+	return nil
+}
+
+// forgetEmbedded clears w's embedded keyboard focus if it is xf, which is no
+// longer embedded.
+func (w *windowImpl) forgetEmbedded(xf xproto.Window) {
+	w.mu.Lock()
+	if w.xembedFocus == xf {
+		w.xembedFocus = 0
+	}
+	w.mu.Unlock()
+}
+
+// activateEmbedded tells the windows embedded in w whether w is active,
+// which is when it has the keyboard focus, and tells the one with the
+// embedded keyboard focus that it gained or lost the focus.
+func (w *windowImpl) activateEmbedded(active bool) {
+	msg, focusMsg := uint32(xembedWindowDeactivate), uint32(xembedFocusOut)
+	if active {
+		msg, focusMsg = xembedWindowActivate, xembedFocusIn
+	}
+	for _, xf := range w.s.embeddedIn(w, false) {
+		w.s.sendXEmbed(xf, msg, 0, 0, 0)
+	}
+	w.mu.Lock()
+	xf := w.xembedFocus
+	w.mu.Unlock()
+	if xf != 0 {
+		w.s.sendXEmbed(xf, focusMsg, xembedFocusCurrent, 0, 0)
+	}
+}
+
+// forwardKey reports whether w has an embedded window with the keyboard
+// focus, forwarding ev to it if so. ev is a KeyPress event, or a KeyRelease
+// event if release is true.
+func (w *windowImpl) forwardKey(ev xproto.KeyPressEvent, release bool) bool {
+	w.mu.Lock()
+	xf := w.xembedFocus
+	w.mu.Unlock()
+	if xf == 0 {
+		return false
+	}
+	ev.Event, ev.Child = xf, 0
+	b := ev.Bytes()
+	if release {
+		b = xproto.KeyReleaseEvent(ev).Bytes()
+	}
+	xproto.SendEvent(w.s.xc, false, xf, xproto.EventMaskNoEvent, string(b))
+	return true
+}
+
+// embeddedIn returns the windows embedded in w, forgetting them if remove is
+// true.
+func (s *screenImpl) embeddedIn(w *windowImpl, remove bool) []xproto.Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var xfs []xproto.Window
+	for xf, e := range s.embedded {
+		if e == w {
+			xfs = append(xfs, xf)
+			if remove {
+				delete(s.embedded, xf)
+			}
+		}
+	}
+	return xfs
+}
+
+// embedder returns the window that xf is embedded in, if any.
+func (s *screenImpl) embedder(xf xproto.Window) *windowImpl {
+	s.mu.Lock()
+	w := s.embedded[xf]
+	s.mu.Unlock()
+	return w
+}
+
+// xembedMapped returns whether xf's _XEMBED_INFO asks for it to be mapped.
+// Windows without _XEMBED_INFO are mapped.
+func (s *screenImpl) xembedMapped(xf xproto.Window) (bool, error) {
+	info, err := s.getProperty32(xf, s.atomXEmbedInfo, s.atomXEmbedInfo)
+	if err != nil {
+		return false, err
+	}
+	return len(info) < 2 || info[1]&xembedMapped != 0, nil
+}
+
+func (s *screenImpl) sendXEmbed(xf xproto.Window, msg, detail, data1, data2 uint32) {
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: xf,
+		Type:   s.atomXEmbed,
+		Data: xproto.ClientMessageDataUnionData32New([]uint32{
+			uint32(xproto.TimeCurrentTime), msg, detail, data1, data2,
+		}),
+	}
+	xproto.SendEvent(s.xc, false, xf, xproto.EventMaskNoEvent, string(ev.Bytes()))
+}
+
+// handleEmbeddedGone tells the window that xf was embedded in, if any, that
+// xf is no longer embedded, because it was destroyed or reparented away.
+func (s *screenImpl) handleEmbeddedGone(xf xproto.Window) {
+	s.mu.Lock()
+	w := s.embedded[xf]
+	delete(s.embedded, xf)
+	s.mu.Unlock()
+
+	if w != nil {
+		w.forgetEmbedded(xf)
+		w.Post(screen.EmbedEvent{Window: uint32(xf), Embedded: false})
+	}
+}
+
+// handleEmbeddedProperty reports whether ev is about an embedded window,
+// mapping or unmapping the window if its _XEMBED_INFO changed.
+func (s *screenImpl) handleEmbeddedProperty(ev xproto.PropertyNotifyEvent) bool {
+	if s.embedder(ev.Window) == nil {
+		return false
+	}
+	if ev.Atom != s.atomXEmbedInfo {
+		return true
+	}
+	mapped, err := s.xembedMapped(ev.Window)
+	if err != nil {
+		return true
+	}
+	if mapped {
+		xproto.MapWindow(s.xc, ev.Window)
+	} else {
+		xproto.UnmapWindow(s.xc, ev.Window)
+	}
+	return true
+}
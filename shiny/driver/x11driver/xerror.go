@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/BurntSushi/xgb"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// coreRequests are the names of the core X11 requests, indexed by major
+// opcode.
+var coreRequests = [...]string{
+	1: "CreateWindow", "ChangeWindowAttributes", "GetWindowAttributes",
+	"DestroyWindow", "DestroySubwindows", "ChangeSaveSet", "ReparentWindow",
+	"MapWindow", "MapSubwindows", "UnmapWindow", "UnmapSubwindows",
+	"ConfigureWindow", "CirculateWindow", "GetGeometry", "QueryTree",
+	"InternAtom", "GetAtomName", "ChangeProperty", "DeleteProperty",
+	"GetProperty", "ListProperties", "SetSelectionOwner", "GetSelectionOwner",
+	"ConvertSelection", "SendEvent", "GrabPointer", "UngrabPointer",
+	"GrabButton", "UngrabButton", "ChangeActivePointerGrab", "GrabKeyboard",
+	"UngrabKeyboard", "GrabKey", "UngrabKey", "AllowEvents", "GrabServer",
+	"UngrabServer", "QueryPointer", "GetMotionEvents", "TranslateCoordinates",
+	"WarpPointer", "SetInputFocus", "GetInputFocus", "QueryKeymap", "OpenFont",
+	"CloseFont", "QueryFont", "QueryTextExtents", "ListFonts",
+	"ListFontsWithInfo", "SetFontPath", "GetFontPath", "CreatePixmap",
+	"FreePixmap", "CreateGC", "ChangeGC", "CopyGC", "SetDashes",
+	"SetClipRectangles", "FreeGC", "ClearArea", "CopyArea", "CopyPlane",
+	"PolyPoint", "PolyLine", "PolySegment", "PolyRectangle", "PolyArc",
+	"FillPoly", "PolyFillRectangle", "PolyFillArc", "PutImage", "GetImage",
+	"PolyText8", "PolyText16", "ImageText8", "ImageText16", "CreateColormap",
+	"FreeColormap", "CopyColormapAndFree", "InstallColormap",
+	"UninstallColormap", "ListInstalledColormaps", "AllocColor",
+	"AllocNamedColor", "AllocColorCells", "AllocColorPlanes", "FreeColors",
+	"StoreColors", "StoreNamedColor", "QueryColors", "LookupColor",
+	"CreateCursor", "CreateGlyphCursor", "FreeCursor", "RecolorCursor",
+	"QueryBestSize", "QueryExtension", "ListExtensions",
+	"ChangeKeyboardMapping", "GetKeyboardMapping", "ChangeKeyboardControl",
+	"GetKeyboardControl", "Bell", "ChangePointerControl", "GetPointerControl",
+	"SetScreenSaver", "GetScreenSaver", "ChangeHosts", "ListHosts",
+	"SetAccessControl", "SetCloseDownMode", "KillClient", "RotateProperties",
+	"ForceScreenSaver", "SetPointerMapping", "GetPointerMapping",
+	"SetModifierMapping", "GetModifierMapping",
+	127: "NoOperation",
+}
+
+// requestName returns the name of the request with the given opcodes. Core
+// requests have names like "CopyArea" and extension requests have names like
+// "RENDER:8", with the extension's minor opcode.
+func requestName(major byte, minor uint16, extensions map[string]byte) string {
+	if int(major) < len(coreRequests) && coreRequests[major] != "" {
+		return coreRequests[major]
+	}
+	for name, opcode := range extensions {
+		if opcode == major {
+			return fmt.Sprintf("%s:%d", name, minor)
+		}
+	}
+	return fmt.Sprintf("%d:%d", major, minor)
+}
+
+// protocolError decodes an error that the X11 server sent for a request
+// whose reply or error the driver did not wait for.
+func (s *screenImpl) protocolError(err xgb.Error) *screen.ProtocolError {
+	e := &screen.ProtocolError{
+		Resource: err.BadId(),
+		Err:      err,
+	}
+	// The xgb package has a separate type for each kind of error, but they
+	// all have the same fields.
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		major, minor := v.FieldByName("MajorOpcode"), v.FieldByName("MinorOpcode")
+		if major.Kind() == reflect.Uint8 && minor.Kind() == reflect.Uint16 {
+			s.xc.ExtLock.RLock()
+			e.Request = requestName(byte(major.Uint()), uint16(minor.Uint()), s.xc.Extensions)
+			s.xc.ExtLock.RUnlock()
+		}
+	}
+	return e
+}
+
+func (s *screenImpl) Errors() <-chan error {
+	return s.errors
+}
+
+// reportError sends err to the Errors channel, dropping it if the channel is
+// full, so that a program that doesn't read the channel doesn't stall the
+// event loop.
+func (s *screenImpl) reportError(err xgb.Error) {
+	e := s.protocolError(err)
+	log.Print(e)
+	select {
+	case s.errors <- e:
+	default:
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"testing"
+)
+
+func TestRequestName(t *testing.T) {
+	extensions := map[string]byte{
+		"RENDER":  139,
+		"MIT-SHM": 130,
+	}
+	testCases := []struct {
+		major byte
+		minor uint16
+		want  string
+	}{
+		{1, 0, "CreateWindow"},
+		{53, 0, "CreatePixmap"},
+		{62, 0, "CopyArea"},
+		{72, 0, "PutImage"},
+		{119, 0, "GetModifierMapping"},
+		{127, 0, "NoOperation"},
+		{139, 8, "RENDER:8"},
+		{130, 3, "MIT-SHM:3"},
+		{200, 1, "200:1"},
+	}
+	for _, tc := range testCases {
+		if got := requestName(tc.major, tc.minor, extensions); got != tc.want {
+			t.Errorf("requestName(%d, %d): got %q, want %q", tc.major, tc.minor, got, tc.want)
+		}
+	}
+}
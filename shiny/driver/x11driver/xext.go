@@ -0,0 +1,89 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// extension is an X11 extension that github.com/BurntSushi/xgb has no
+// package for, and whose requests are built by hand.
+type extension struct {
+	name       string
+	major      byte
+	firstEvent byte
+	firstError byte
+}
+
+// queryExtension returns the named extension. It returns false if the X11
+// server does not support it.
+//
+// It registers a decoder for the extension's first nErrors errors, so that
+// they are delivered to the cookie of the failed request instead of being
+// dropped by xgb.
+func (s *screenImpl) queryExtension(name string, nErrors int) (extension, bool, error) {
+	r, err := xproto.QueryExtension(s.xc, uint16(len(name)), name).Reply()
+	if err != nil {
+		return extension{}, false, fmt.Errorf("x11driver: xproto.QueryExtension failed: %v", err)
+	}
+	if !r.Present {
+		return extension{}, false, nil
+	}
+	e := extension{
+		name:       name,
+		major:      r.MajorOpcode,
+		firstEvent: r.FirstEvent,
+		firstError: r.FirstError,
+	}
+	for i := 0; i < nErrors; i++ {
+		xgb.NewErrorFuncs[int(e.firstError)+i] = e.newError
+	}
+	return e, true, nil
+}
+
+// newRequest returns a request for the given minor opcode, n bytes long
+// including the 4 byte header. The header is filled in, and the rest is zero.
+// n must be a multiple of 4.
+func (e extension) newRequest(minor byte, n int) []byte {
+	buf := make([]byte, n)
+	buf[0] = e.major
+	buf[1] = minor
+	xgb.Put16(buf[2:], uint16(n/4))
+	return buf
+}
+
+// send sends a request built by newRequest.
+func (e extension) send(xc *xgb.Conn, buf []byte, checked, reply bool) *xgb.Cookie {
+	c := xc.NewCookie(checked, reply)
+	xc.NewRequest(buf, c)
+	return c
+}
+
+// extensionError is an error reported by an extension.
+type extensionError struct {
+	name     string
+	code     byte
+	sequence uint16
+	badValue uint32
+}
+
+func (e extension) newError(buf []byte) xgb.Error {
+	return extensionError{
+		name:     e.name,
+		code:     buf[1] - e.firstError,
+		sequence: xgb.Get16(buf[2:]),
+		badValue: xgb.Get32(buf[4:]),
+	}
+}
+
+func (e extensionError) SequenceId() uint16 { return e.sequence }
+func (e extensionError) BadId() uint32      { return e.badValue }
+
+func (e extensionError) Error() string {
+	return fmt.Sprintf("%s error %d {Sequence: %d, BadValue: %d}", e.name, e.code, e.sequence, e.badValue)
+}
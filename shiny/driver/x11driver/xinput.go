@@ -0,0 +1,198 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// The github.com/BurntSushi/xgb package does not support the XInput2
+// extension, so its requests are built, and its events decoded, by hand.
+// XInput2 events are X Generic Events, which xgeConn reads.
+const (
+	xiSelectEvents = 46
+	xiQueryVersion = 47
+	xiQueryDevice  = 48
+
+	// xiNumErrors is the number of errors that the XInput extension
+	// defines: Device, Event, Mode, DeviceBusy and Class.
+	xiNumErrors = 5
+
+	xiAllDevices       = 0
+	xiAllMasterDevices = 1
+
+	// XInput2 event types.
+	xiButtonPress        = 4
+	xiButtonRelease      = 5
+	xiMotion             = 6
+	xiHierarchyChanged   = 11
+	xiBarrierHit         = 25
+	xiGesturePinchBegin  = 27
+	xiGesturePinchUpdate = 28
+	xiGesturePinchEnd    = 29
+	xiGestureSwipeBegin  = 30
+	xiGestureSwipeUpdate = 31
+	xiGestureSwipeEnd    = 32
+
+	// xiGestureCancelled is the flag of a gesture end event for a gesture
+	// that was cancelled.
+	xiGestureCancelled = 1 << 0
+)
+
+// initXInput finds and initializes the XInput extension, if the X11 server
+// supports XInput2.
+func (s *screenImpl) initXInput() {
+	e, ok, err := s.queryExtension("XInputExtension", xiNumErrors)
+	if err != nil || !ok {
+		return
+	}
+	// The server only sends the XInput2 events of the version that the
+	// client asks for, or earlier.
+	buf := e.newRequest(xiQueryVersion, 8)
+	xgb.Put16(buf[4:], 2) // major_version.
+	xgb.Put16(buf[6:], 4) // minor_version.
+	r, err := e.send(s.xc, buf, true, true).Reply()
+	if err != nil || len(r) < 12 || xgb.Get16(r[8:]) < 2 {
+		return
+	}
+	s.xinput = e
+	s.xiMinor = xgb.Get16(r[10:])
+
+	if s.xiMinor >= 3 && s.hasXFixes5 {
+		// Pointer barriers are created on the root window, which their
+		// events are sent to.
+		if err := s.selectXIEvents(s.xsi.Root, xiAllMasterDevices, xiBarrierHit); err != nil {
+			s.xiMinor = 2
+		}
+	}
+	s.initDevices()
+}
+
+// hasXInput2 reports whether the X11 server supports XInput 2.minor or
+// later.
+func (s *screenImpl) hasXInput2(minor uint16) bool {
+	return s.xinput.major != 0 && s.xiMinor >= minor
+}
+
+// selectXIEvents selects the given XInput2 event types for the device on
+// xw, replacing any that were previously selected for that device.
+func (s *screenImpl) selectXIEvents(xw xproto.Window, device uint16, evtypes ...int) error {
+	// The event mask is a bit vector, in 4 byte units, indexed by event
+	// type.
+	words := 1
+	for _, t := range evtypes {
+		if t/32+1 > words {
+			words = t/32 + 1
+		}
+	}
+	buf := s.xinput.newRequest(xiSelectEvents, 16+4*words)
+	xgb.Put32(buf[4:], uint32(xw))
+	xgb.Put16(buf[8:], 1) // num_mask.
+	xgb.Put16(buf[12:], device)
+	xgb.Put16(buf[14:], uint16(words))
+	for _, t := range evtypes {
+		buf[16+t/8] |= 1 << uint(t%8)
+	}
+	if err := s.xinput.send(s.xc, buf, true, false).Check(); err != nil {
+		return fmt.Errorf("x11driver: xinput.XISelectEvents failed: %v", err)
+	}
+	return nil
+}
+
+// handleXIEvent handles an XInput2 event.
+func (s *screenImpl) handleXIEvent(ev xgeEvent) {
+	switch ev.evtype {
+	case xiMotion, xiButtonPress, xiButtonRelease:
+		s.handleStylus(ev.evtype, ev.buf)
+	case xiHierarchyChanged:
+		s.handleHierarchyChanged(ev.buf)
+	case xiBarrierHit:
+		s.handleBarrierHit(ev.buf)
+	case xiGesturePinchBegin, xiGesturePinchUpdate, xiGesturePinchEnd,
+		xiGestureSwipeBegin, xiGestureSwipeUpdate, xiGestureSwipeEnd:
+		s.handleGesture(ev.evtype, ev.buf)
+	}
+}
+
+// handleGesture handles an XInput2 gesture event, which is 92 bytes for a
+// swipe and 100 bytes for a pinch.
+func (s *screenImpl) handleGesture(evtype uint16, buf []byte) {
+	pinch := evtype <= xiGesturePinchEnd
+	if (pinch && len(buf) < 100) || len(buf) < 92 {
+		return
+	}
+	w := s.findWindow(xproto.Window(xgb.Get32(buf[24:])))
+	if w == nil {
+		return
+	}
+
+	e := screen.GestureEvent{
+		Kind:    screen.GestureSwipe,
+		Fingers: int(xgb.Get32(buf[16:])),
+		X:       fp1616(buf[40:]),
+		Y:       fp1616(buf[44:]),
+		DeltaX:  fp1616(buf[48:]),
+		DeltaY:  fp1616(buf[52:]),
+		Scale:   1,
+	}
+	first, flags := uint16(xiGestureSwipeBegin), xgb.Get32(buf[88:])
+	if pinch {
+		e.Kind = screen.GesturePinch
+		first, flags = xiGesturePinchBegin, xgb.Get32(buf[96:])
+		e.Scale = float64(fp1616(buf[64:]))
+
+		// The server sends the change in angle since the previous event.
+		if evtype == first {
+			w.gestureRotation = 0
+		}
+		w.gestureRotation += float64(fp1616(buf[68:]))
+		e.Rotation = w.gestureRotation
+	}
+	switch evtype - first {
+	case 0:
+		e.Phase = screen.GestureBegin
+	case 1:
+		e.Phase = screen.GestureUpdate
+	default:
+		e.Phase = screen.GestureEnd
+		if flags&xiGestureCancelled != 0 {
+			e.Phase = screen.GestureCancel
+		}
+	}
+	if w.reportDIP {
+		e.X /= s.pixelsPerPt
+		e.Y /= s.pixelsPerPt
+	}
+	w.Post(e)
+}
+
+// sendAll sends e to every window.
+func (s *screenImpl) sendAll(e interface{}) {
+	s.mu.Lock()
+	windows := make([]*windowImpl, 0, len(s.windows))
+	for _, w := range s.windows {
+		windows = append(windows, w)
+	}
+	s.mu.Unlock()
+
+	for _, w := range windows {
+		w.Post(e)
+	}
+}
+
+// fp1616 decodes an XInput2 16.16 fixed point number.
+func fp1616(b []byte) float32 {
+	return float32(int32(xgb.Get32(b))) / 0x10000
+}
+
+// fp3232 decodes an XInput2 32.32 fixed point number.
+func fp3232(b []byte) float64 {
+	return float64(int32(xgb.Get32(b))) + float64(xgb.Get32(b[4:]))/0x100000000
+}
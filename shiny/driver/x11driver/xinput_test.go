@@ -0,0 +1,132 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xfixes"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"golang.org/x/exp/shiny/screen"
+)
+
+// xiEvent returns an n byte XInput2 event of the given type.
+func xiEvent(evtype uint16, n int) []byte {
+	buf := make([]byte, n)
+	buf[0] = xproto.GeGeneric
+	xgb.Put32(buf[4:], uint32(n-32)/4)
+	xgb.Put16(buf[8:], evtype)
+	return buf
+}
+
+// putFP1616 and putFP3232 encode XInput2 fixed point numbers.
+func putFP1616(b []byte, v float64) { xgb.Put32(b, uint32(int32(v*0x10000))) }
+func putFP3232(b []byte, v float64) {
+	i := int32(v)
+	if float64(i) > v {
+		i--
+	}
+	xgb.Put32(b, uint32(i))
+	xgb.Put32(b[4:], uint32((v-float64(i))*0x100000000))
+}
+
+func TestHandleBarrierHit(t *testing.T) {
+	w := &windowImpl{}
+	b := &barrierImpl{xb: 7}
+	s := &screenImpl{
+		windows:  map[xproto.Window]*windowImpl{1: w},
+		barriers: map[xfixes.Barrier]*barrierImpl{7: b},
+	}
+
+	buf := xiEvent(xiBarrierHit, 68)
+	xgb.Put32(buf[28:], 8) // An unknown barrier.
+	s.handleBarrierHit(buf)
+
+	xgb.Put32(buf[28:], 7)
+	putFP1616(buf[44:], 1919.5)
+	putFP1616(buf[48:], 20)
+	putFP3232(buf[52:], 3.25)
+	putFP3232(buf[60:], -0.75)
+	s.handleBarrierHit(buf)
+
+	want := screen.BarrierHitEvent{
+		Barrier: b,
+		X:       1919.5,
+		Y:       20,
+		DX:      3.25,
+		DY:      -0.75,
+	}
+	if got := w.NextEvent(); got != want {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestHandleGesture(t *testing.T) {
+	w := &windowImpl{}
+	s := &screenImpl{
+		windows: map[xproto.Window]*windowImpl{1: w},
+	}
+
+	pinch := func(evtype uint16, scale, angle float64, flags uint32) {
+		buf := xiEvent(evtype, 100)
+		xgb.Put32(buf[16:], 2)
+		xgb.Put32(buf[24:], 1)
+		putFP1616(buf[40:], 10.5)
+		putFP1616(buf[44:], 20)
+		putFP1616(buf[48:], 1)
+		putFP1616(buf[52:], -2)
+		putFP1616(buf[64:], scale)
+		putFP1616(buf[68:], angle)
+		xgb.Put32(buf[96:], flags)
+		s.handleGesture(evtype, buf)
+	}
+	pinch(xiGesturePinchBegin, 1, 0, 0)
+	pinch(xiGesturePinchUpdate, 1.5, 10, 0)
+	pinch(xiGesturePinchUpdate, 2, -2.5, 0)
+	pinch(xiGesturePinchEnd, 2, 0, xiGestureCancelled)
+
+	swipe := xiEvent(xiGestureSwipeUpdate, 92)
+	xgb.Put32(swipe[16:], 3)
+	xgb.Put32(swipe[24:], 1)
+	putFP1616(swipe[48:], 4)
+	s.handleGesture(xiGestureSwipeUpdate, swipe)
+
+	xgb.Put32(swipe[24:], 2) // An unknown window.
+	s.handleGesture(xiGestureSwipeUpdate, swipe)
+
+	pinchEvent := func(phase screen.GesturePhase, scale, rotation float64) screen.GestureEvent {
+		return screen.GestureEvent{
+			Kind:     screen.GesturePinch,
+			Phase:    phase,
+			Fingers:  2,
+			X:        10.5,
+			Y:        20,
+			DeltaX:   1,
+			DeltaY:   -2,
+			Scale:    scale,
+			Rotation: rotation,
+		}
+	}
+	want := []screen.GestureEvent{
+		pinchEvent(screen.GestureBegin, 1, 0),
+		pinchEvent(screen.GestureUpdate, 1.5, 10),
+		pinchEvent(screen.GestureUpdate, 2, 7.5),
+		pinchEvent(screen.GestureCancel, 2, 7.5),
+		{
+			Kind:    screen.GestureSwipe,
+			Phase:   screen.GestureUpdate,
+			Fingers: 3,
+			DeltaX:  4,
+			Scale:   1,
+		},
+	}
+	for i, want := range want {
+		if got := w.NextEvent(); got != want {
+			t.Errorf("event %d: got %+v, want %+v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// These are the setting types of the XSETTINGS protocol, described at
+// https://specifications.freedesktop.org/xsettings-spec/
+const (
+	xsettingsInteger = 0
+	xsettingsString  = 1
+	xsettingsColor   = 2
+)
+
+var errBadXSettings = errors.New("x11driver: malformed _XSETTINGS_SETTINGS property")
+
+// xsettingsInt returns the integer XSETTINGS setting with the given name, as
+// set by the XSETTINGS manager, such as a desktop environment's settings
+// daemon. It returns false if there is no manager or the setting is not set.
+//
+// The manager owns the _XSETTINGS_Sn selection, for screen number n, and
+// keeps the settings in the _XSETTINGS_SETTINGS property of its window.
+func (s *screenImpl) xsettingsInt(name string) (int32, bool, error) {
+	selection, err := s.internAtom(fmt.Sprintf("_XSETTINGS_S%d", s.xc.DefaultScreen))
+	if err != nil {
+		return 0, false, err
+	}
+	owner, err := xproto.GetSelectionOwner(s.xc, selection).Reply()
+	if err != nil {
+		return 0, false, fmt.Errorf("x11driver: xproto.GetSelectionOwner failed: %v", err)
+	}
+	if owner.Owner == 0 {
+		return 0, false, nil
+	}
+	property, err := s.internAtom("_XSETTINGS_SETTINGS")
+	if err != nil {
+		return 0, false, err
+	}
+	r, err := xproto.GetProperty(s.xc, false, owner.Owner, property, property, 0, 1<<16).Reply()
+	if err != nil {
+		// The manager may have exited since GetSelectionOwner.
+		return 0, false, fmt.Errorf("x11driver: xproto.GetProperty failed: %v", err)
+	}
+	ints, err := parseXSettings(r.Value)
+	if err != nil {
+		return 0, false, err
+	}
+	v, ok := ints[name]
+	return v, ok, nil
+}
+
+// parseXSettings returns the integer settings of an _XSETTINGS_SETTINGS
+// property value. Settings of other types are skipped.
+func parseXSettings(b []byte) (map[string]int32, error) {
+	if len(b) < 12 {
+		return nil, errBadXSettings
+	}
+	// The value is in the byte order of the manager's machine.
+	var order binary.ByteOrder
+	switch b[0] {
+	case 0:
+		order = binary.LittleEndian
+	case 1:
+		order = binary.BigEndian
+	default:
+		return nil, errBadXSettings
+	}
+	n := order.Uint32(b[8:])
+	b = b[12:]
+
+	// pad4 rounds n up to a multiple of 4.
+	pad4 := func(n int) int { return (n + 3) &^ 3 }
+	ints := map[string]int32{}
+	for ; n > 0; n-- {
+		// Each setting is its type, its name, which is padded to a multiple
+		// of 4 bytes, its last-change serial number and its value.
+		if len(b) < 4 {
+			return nil, errBadXSettings
+		}
+		typ, nameLen := b[0], int(order.Uint16(b[2:]))
+		if len(b) < 4+pad4(nameLen)+4 {
+			return nil, errBadXSettings
+		}
+		name := string(b[4 : 4+nameLen])
+		b = b[4+pad4(nameLen)+4:]
+
+		switch typ {
+		case xsettingsInteger:
+			if len(b) < 4 {
+				return nil, errBadXSettings
+			}
+			ints[name] = int32(order.Uint32(b))
+			b = b[4:]
+		case xsettingsString:
+			if len(b) < 4 {
+				return nil, errBadXSettings
+			}
+			// The string is padded to a multiple of 4 bytes.
+			valueLen := (uint64(order.Uint32(b)) + 3) &^ 3
+			if uint64(len(b)-4) < valueLen {
+				return nil, errBadXSettings
+			}
+			b = b[4+valueLen:]
+		case xsettingsColor:
+			// Red, green, blue and alpha, 16 bits each.
+			if len(b) < 8 {
+				return nil, errBadXSettings
+			}
+			b = b[8:]
+		default:
+			return nil, errBadXSettings
+		}
+	}
+	return ints, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x11driver
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// xsettingsBuilder builds an _XSETTINGS_SETTINGS property value.
+type xsettingsBuilder struct {
+	order binary.ByteOrder
+	n     uint32
+	b     []byte
+}
+
+func (x *xsettingsBuilder) name(typ byte, name string) {
+	x.n++
+	x.b = append(x.b, typ, 0, 0, 0)
+	x.order.PutUint16(x.b[len(x.b)-2:], uint16(len(name)))
+	x.b = append(x.b, name...)
+	x.pad()
+	x.b = append(x.b, 0, 0, 0, 0) // last-change-serial.
+}
+
+func (x *xsettingsBuilder) uint32(v uint32) {
+	x.b = append(x.b, 0, 0, 0, 0)
+	x.order.PutUint32(x.b[len(x.b)-4:], v)
+}
+
+func (x *xsettingsBuilder) pad() {
+	for len(x.b)%4 != 0 {
+		x.b = append(x.b, 0)
+	}
+}
+
+func (x *xsettingsBuilder) bytes() []byte {
+	header := make([]byte, 12)
+	if x.order == binary.BigEndian {
+		header[0] = 1
+	}
+	x.order.PutUint32(header[8:], x.n)
+	return append(header, x.b...)
+}
+
+func TestParseXSettings(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		x := &xsettingsBuilder{order: order}
+		x.name(xsettingsString, "Net/ThemeName")
+		x.uint32(7)
+		x.b = append(x.b, "Adwaita"...)
+		x.pad()
+		x.name(xsettingsInteger, "Gtk/CursorThemeSize")
+		x.uint32(48)
+		x.name(xsettingsColor, "Gtk/Color")
+		x.b = append(x.b, 1, 2, 3, 4, 5, 6, 7, 8)
+		x.name(xsettingsInteger, "Net/DoubleClickTime")
+		x.uint32(0xffffffff)
+		b := x.bytes()
+
+		got, err := parseXSettings(b)
+		if err != nil {
+			t.Errorf("%v: %v", order, err)
+			continue
+		}
+		want := map[string]int32{
+			"Gtk/CursorThemeSize": 48,
+			"Net/DoubleClickTime": -1,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: got %v, want %v", order, got, want)
+		}
+
+		// Truncated values are errors, not panics.
+		for i := 0; i < len(b); i++ {
+			if _, err := parseXSettings(b[:i]); err == nil {
+				t.Errorf("%v: truncated to %d bytes: no error", order, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package screen
+
+import (
+	"image"
+)
+
+// DrawOption sets one of the fields of a DrawOptions.
+type DrawOption func(*DrawOptions)
+
+// DefaultDrawOptions returns a new DrawOptions holding the default options.
+// Passing it to the Drawer methods is equivalent to passing nil.
+func DefaultDrawOptions() *DrawOptions {
+	return &DrawOptions{}
+}
+
+// NewDrawOptions returns DefaultDrawOptions modified by opts, in order. For
+// example:
+//
+//	w.Scale(dr, t, sr, screen.Over, screen.NewDrawOptions(
+//		screen.WithFilter(screen.FilterLinear),
+//		screen.WithClip(clip),
+//	))
+func NewDrawOptions(opts ...DrawOption) *DrawOptions {
+	o := DefaultDrawOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMask sets the DrawOptions' Mask and SubpixelOrder.
+func WithMask(mask Texture, order SubpixelOrder) DrawOption {
+	return func(o *DrawOptions) {
+		o.Mask = mask
+		o.SubpixelOrder = order
+	}
+}
+
+// WithBlend sets the DrawOptions' Blend.
+func WithBlend(b BlendMode) DrawOption {
+	return func(o *DrawOptions) { o.Blend = b }
+}
+
+// WithFilter sets the DrawOptions' Filter.
+func WithFilter(f Filter) DrawOption {
+	return func(o *DrawOptions) { o.Filter = f }
+}
+
+// WithClip sets the DrawOptions' Clip.
+func WithClip(r image.Rectangle) DrawOption {
+	return func(o *DrawOptions) { o.Clip = r }
+}
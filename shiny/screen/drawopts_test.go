@@ -0,0 +1,32 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package screen
+
+import (
+	"image"
+	"testing"
+)
+
+func TestNewDrawOptions(t *testing.T) {
+	if got, want := *NewDrawOptions(), (DrawOptions{}); got != want {
+		t.Errorf("NewDrawOptions(): got %+v, want %+v", got, want)
+	}
+
+	clip := image.Rect(1, 2, 3, 4)
+	got := *NewDrawOptions(
+		WithFilter(FilterLinear),
+		WithBlend(BlendAdd),
+		WithClip(image.Rect(5, 6, 7, 8)),
+		WithClip(clip),
+	)
+	want := DrawOptions{
+		Blend:  BlendAdd,
+		Filter: FilterLinear,
+		Clip:   clip,
+	}
+	if got != want {
+		t.Errorf("NewDrawOptions(...): got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package screen
+
+import (
+	"time"
+)
+
+// ClipboardLostEvent is sent to a Window when another client takes ownership
+// of a selection that the Window previously owned. Any data cached to serve
+// that selection is no longer needed.
+type ClipboardLostEvent struct {
+	// Selection is the name of the selection, such as "CLIPBOARD" or
+	// "PRIMARY".
+	Selection string
+}
+
+// DisplayChangeEvent is sent to every Window when a Display is attached,
+// detached or reconfigured.
+type DisplayChangeEvent struct {
+	// Displays is the new set of displays, as would be returned by
+	// Screen.Displays.
+	Displays []Display
+}
+
+// SaveSessionEvent is sent to a Window when the session manager asks the
+// program to save its state, so that it can be restored in a later session.
+type SaveSessionEvent struct{}
+
+// UserActiveEvent is sent to every Window when the user resumes interacting
+// with the screen after being idle for longer than the threshold set by
+// Screen.SetIdleThreshold.
+type UserActiveEvent struct {
+	// IdleTime is approximately how long the user was idle for.
+	IdleTime time.Duration
+}
+
+// BarrierHitEvent is sent to every Window when a pointer barrier, created by
+// Screen.CreatePointerBarrier, stops the pointer.
+type BarrierHitEvent struct {
+	Barrier Barrier
+
+	// X and Y are the pointer position, in screen coordinates.
+	X, Y float32
+
+	// DX and DY are how far, in pixels, the pointer would have moved had the
+	// barrier not stopped it.
+	DX, DY float64
+}
+
+// DragFinishedEvent is sent to a Window when a drag started by
+// Window.StartDrag is finished.
+type DragFinishedEvent struct {
+	// Accepted is whether the data was dropped onto, and accepted by,
+	// another program.
+	Accepted bool
+}
+
+// EmbedEvent is sent to a Window when a foreign window is embedded in it, by
+// Window.Embed, or stops being embedded.
+type EmbedEvent struct {
+	// Window is the foreign window's native ID.
+	Window   uint32
+	Embedded bool
+}
+
+// GestureEvent is sent to a Window for touchpad gestures, such as pinching
+// to zoom or swiping with three fingers. The Scale and Rotation of a pinch
+// are relative to the start of the gesture.
+type GestureEvent struct {
+	Kind  GestureKind
+	Phase GesturePhase
+
+	// Fingers is the number of fingers on the touchpad.
+	Fingers int
+
+	// X and Y are the pointer position, in window coordinates.
+	X, Y float32
+
+	// DeltaX and DeltaY are how far the fingers' center has moved since the
+	// previous GestureEvent, in pixels.
+	DeltaX, DeltaY float32
+
+	// Scale is the ratio of the fingers' current spread to their spread at
+	// the start of a pinch. It is 1 for swipes.
+	Scale float64
+
+	// Rotation is the clockwise rotation, in degrees, of the fingers since
+	// the start of a pinch. It is 0 for swipes.
+	Rotation float64
+}
+
+// GestureKind is the kind of a GestureEvent.
+type GestureKind uint8
+
+const (
+	GesturePinch GestureKind = iota
+	GestureSwipe
+)
+
+// GesturePhase is the phase of a GestureEvent.
+type GesturePhase uint8
+
+const (
+	GestureBegin GesturePhase = iota
+	GestureUpdate
+	GestureEnd
+
+	// GestureCancel is like GestureEnd, except that the gesture should be
+	// undone, for example because the user lifted their fingers before the
+	// gesture was recognized.
+	GestureCancel
+)
+
+// InputDeviceEvent is sent to every Window when input devices, such as
+// tablets or gamepads, are connected or disconnected.
+type InputDeviceEvent struct {
+	Added, Removed []DeviceInfo
+}
+
+// DeviceInfo describes an input device.
+type DeviceInfo struct {
+	// ID identifies the device to the driver. IDs may be reused after a
+	// device is removed.
+	ID   int
+	Name string
+	Type DeviceType
+}
+
+// DeviceType is the type of an input device.
+type DeviceType uint8
+
+const (
+	DeviceOther DeviceType = iota
+	DeviceKeyboard
+	DevicePointer
+	DeviceTablet
+	DeviceTouchscreen
+	DeviceGamepad
+)
+
+// StylusEvent is sent to a Window for the motion, presses and releases of a
+// graphics tablet's stylus. It is sent as well as the mouse.Event for the
+// same input, which carries the button state, but the two may arrive in
+// either order.
+type StylusEvent struct {
+	// Device is the ID of the tablet, as in DeviceInfo.
+	Device int
+
+	// X and Y are the stylus position, in the same units as mouse.Event's,
+	// with sub-pixel precision when the tablet provides it.
+	X, Y float32
+
+	// Pressure is in the range [0, 1], normalized using the range reported
+	// by the device. It is 0 when the stylus is not touching the tablet.
+	Pressure float32
+
+	// TiltX and TiltY are the stylus angle from the vertical, in the range
+	// [-1, 1], towards positive X and Y. They are 0 if the device does not
+	// report tilt.
+	TiltX, TiltY float32
+
+	// BarrelButton is whether a button on the side of the stylus is
+	// pressed.
+	BarrelButton bool
+}
+
+// VisibilityEvent is sent to a Window when how much of it is visible
+// changes, for example when it is covered by another window or minimized.
+// A program can stop painting a window that is fully obscured.
+type VisibilityEvent struct {
+	Visibility Visibility
+}
+
+// Visibility is how much of a Window is visible.
+type Visibility uint8
+
+const (
+	Visible Visibility = iota
+	PartiallyObscured
+	FullyObscured
+)
+
+// WindowStateEvent is sent to a Window when its state changes, whether at
+// the program's request or because the user or window manager changed it,
+// for example by maximizing the window with a key binding.
+type WindowStateEvent struct {
+	State WindowState
+}
+
+// CompositorChangeEvent is sent to every Window when a compositing manager
+// starts or stops. Effects such as window opacity and transparency need a
+// compositing manager. See also Screen.HasCompositor.
+type CompositorChangeEvent struct {
+	// Running is whether a compositing manager is now running.
+	Running bool
+}
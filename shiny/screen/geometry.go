@@ -0,0 +1,42 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package screen
+
+import (
+	"image"
+)
+
+// GeometrySpec is a window's geometry, as saved by SaveGeometry, so that a
+// program can restore its windows' positions and sizes across runs.
+type GeometrySpec struct {
+	// Bounds are the window's outer bounds, including any decorations added
+	// by the window manager, in screen coordinates.
+	Bounds image.Rectangle
+
+	// ClientSize is the size of the window's contents, excluding
+	// decorations, as for NewWindowOptions' Width and Height.
+	ClientSize image.Point
+
+	// Display is the name of the Display that shows most of the window.
+	Display string
+
+	// Maximized is whether the window is maximized.
+	Maximized bool
+}
+
+// SaveGeometry returns w's current geometry. It is equivalent to
+// w.Geometry().
+func SaveGeometry(w Window) (GeometrySpec, error) {
+	return w.Geometry()
+}
+
+// ApplyGeometry modifies opts so that the new window has the geometry g,
+// typically as saved by SaveGeometry in a previous run. If g's Display no
+// longer exists, or no longer shows g's Bounds, the window keeps its size but
+// is centered on the primary display instead.
+func ApplyGeometry(opts *NewWindowOptions, g GeometrySpec) {
+	opts.Width, opts.Height = g.ClientSize.X, g.ClientSize.Y
+	opts.Geometry = &g
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package record records the events that a program receives from a
+// screen.EventDeque, and replays them, for example to reproduce a bug or to
+// drive a test.
+package record // import "golang.org/x/exp/shiny/screen/record"
+
+import (
+	"encoding/gob"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/shiny/screen"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/lifecycle"
+	"golang.org/x/mobile/event/mouse"
+	"golang.org/x/mobile/event/paint"
+	"golang.org/x/mobile/event/size"
+	"golang.org/x/mobile/event/touch"
+)
+
+func init() {
+	gob.Register(key.Event{})
+	gob.Register(lifecycle.Event{})
+	gob.Register(mouse.Event{})
+	gob.Register(paint.Event{})
+	gob.Register(size.Event{})
+	gob.Register(touch.Event{})
+}
+
+// Record is an event returned by a Recorder's NextEvent method, and when it
+// was returned, relative to when the Recorder was created.
+type Record struct {
+	Time  time.Duration
+	Event interface{}
+}
+
+// Recorder wraps a screen.EventDeque, recording every event returned by
+// NextEvent so that the session can later be replayed by a Replayer.
+type Recorder struct {
+	screen.EventDeque
+
+	start time.Time
+
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewRecorder returns a Recorder that wraps q.
+func NewRecorder(q screen.EventDeque) *Recorder {
+	return &Recorder{
+		EventDeque: q,
+		start:      time.Now(),
+	}
+}
+
+// NextEvent implements the screen.EventDeque interface.
+func (r *Recorder) NextEvent() interface{} {
+	e := r.EventDeque.NextEvent()
+	r.record(e)
+	return e
+}
+
+// TryNextEvent implements the screen.EventDeque interface.
+func (r *Recorder) TryNextEvent() (interface{}, bool) {
+	e, ok := r.EventDeque.TryNextEvent()
+	if ok {
+		r.record(e)
+	}
+	return e, ok
+}
+
+func (r *Recorder) record(e interface{}) {
+	r.mu.Lock()
+	r.records = append(r.records, Record{
+		Time:  time.Since(r.start),
+		Event: e,
+	})
+	r.mu.Unlock()
+}
+
+// Records returns the events recorded so far.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.records...)
+}
+
+// Encode writes the events recorded so far to w, in a form that can be read
+// by DecodeRecords.
+//
+// The events are encoded with encoding/gob. The key, lifecycle, mouse, paint,
+// size and touch event types from the golang.org/x/mobile/event/... packages
+// are registered with gob by this package. Any other event types must be
+// registered by the caller, via gob.Register, before calling Encode or
+// DecodeRecords.
+func (r *Recorder) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(r.Records())
+}
+
+// DecodeRecords reads events written by a Recorder's Encode method.
+func DecodeRecords(rd io.Reader) ([]Record, error) {
+	var records []Record
+	if err := gob.NewDecoder(rd).Decode(&records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Replayer sends recorded events to a screen.EventDeque at the cadence they
+// were recorded at.
+type Replayer struct {
+	// Records are the events to replay, in order.
+	Records []Record
+
+	// Speed scales how fast the events are replayed. For example, 2 replays
+	// the events twice as fast as they were recorded. Zero means 1. A
+	// negative value sends the events without any delay between them.
+	Speed float64
+}
+
+// Replay sends p's events to q, in order, each at its recorded time relative
+// to when Replay was called. It returns after the last event has been sent,
+// or early if stop is closed. A nil stop channel is never closed.
+func (p *Replayer) Replay(q screen.EventDeque, stop <-chan struct{}) {
+	speed := p.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	start := time.Now()
+	for _, rec := range p.Records {
+		if speed > 0 {
+			d := time.Duration(float64(rec.Time)/speed) - time.Since(start)
+			if d > 0 {
+				t := time.NewTimer(d)
+				select {
+				case <-t.C:
+				case <-stop:
+					t.Stop()
+					return
+				}
+			}
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		q.Send(rec.Event)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/mouse"
+	"golang.org/x/mobile/event/size"
+)
+
+func TestRecordReplay(t *testing.T) {
+	want := []interface{}{
+		size.Event{WidthPx: 640, HeightPx: 480},
+		mouse.Event{X: 10, Y: 20, Button: mouse.ButtonLeft, Direction: mouse.DirPress},
+		key.Event{Rune: 'a', Code: key.CodeA, Direction: key.DirPress},
+	}
+
+	src := &fifo{}
+	for _, e := range want {
+		src.Send(e)
+	}
+	rec := NewRecorder(src)
+	for range want {
+		rec.NextEvent()
+	}
+
+	buf := new(bytes.Buffer)
+	if err := rec.Encode(buf); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	records, err := DecodeRecords(buf)
+	if err != nil {
+		t.Fatalf("DecodeRecords: %v", err)
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].Time < records[i-1].Time {
+			t.Errorf("record %d: time %v is before the previous record's %v", i, records[i].Time, records[i-1].Time)
+		}
+	}
+
+	dst := &fifo{}
+	p := &Replayer{Records: records, Speed: -1}
+	p.Replay(dst, nil)
+	for i, w := range want {
+		if got := dst.NextEvent(); !reflect.DeepEqual(got, w) {
+			t.Errorf("event %d: got %#v, want %#v", i, got, w)
+		}
+	}
+}
+
+// fifo is a minimal screen.EventDeque. NextEvent returns nil, instead of
+// blocking, when it is empty.
+type fifo struct {
+	mu     sync.Mutex
+	events []interface{}
+}
+
+func (q *fifo) Send(e interface{}) {
+	q.mu.Lock()
+	q.events = append(q.events, e)
+	q.mu.Unlock()
+}
+
+func (q *fifo) SendFirst(e interface{}) {
+	q.mu.Lock()
+	q.events = append([]interface{}{e}, q.events...)
+	q.mu.Unlock()
+}
+
+func (q *fifo) NextEvent() interface{} {
+	e, _ := q.TryNextEvent()
+	return e
+}
+
+func (q *fifo) TryNextEvent() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.events) == 0 {
+		return nil, false
+	}
+	e := q.events[0]
+	q.events = q.events[1:]
+	return e, true
+}
+
+func (q *fifo) Do(f func()) {
+	f()
+}
@@ -55,12 +55,18 @@
 package screen // import "golang.org/x/exp/shiny/screen"
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"io"
+	"reflect"
+	"time"
 	"unicode/utf8"
 
 	"golang.org/x/image/math/f64"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/mouse"
 )
 
 // TODO: specify image format (Alpha or Gray, not just RGBA) for NewBuffer
@@ -71,13 +77,212 @@ type Screen interface {
 	// NewBuffer returns a new Buffer for this screen.
 	NewBuffer(size image.Point) (Buffer, error)
 
+	// NewPalettedBuffer returns a new PalettedBuffer for this screen, with
+	// the given initial palette.
+	NewPalettedBuffer(size image.Point, p color.Palette) (PalettedBuffer, error)
+
 	// NewTexture returns a new Texture for this screen.
 	NewTexture(size image.Point) (Texture, error)
 
+	// NewRenderTarget returns a new RenderTarget for this screen.
+	NewRenderTarget(size image.Point) (RenderTarget, error)
+
+	// NewTextureFromReader decodes an image from r and returns a new Texture,
+	// for this screen, holding that image.
+	//
+	// The image is decoded by image.Decode, so any format whose decoder has
+	// been registered with the standard library's image package can be used.
+	// Decoders are typically registered by importing their package for its
+	// side effects, such as:
+	//	import _ "image/png"
+	//	import _ "golang.org/x/image/webp"
+	//
+	// If decoding fails, the error is returned and no Texture is allocated.
+	NewTextureFromReader(r io.Reader) (Texture, error)
+
 	// NewWindow returns a new Window for this screen.
 	//
 	// A nil opts is valid and means to use the default option values.
 	NewWindow(opts *NewWindowOptions) (Window, error)
+
+	// Displays returns the physical displays (monitors) attached to this
+	// screen. When the set of displays changes, every Window is sent a
+	// DisplayChangeEvent.
+	Displays() ([]Display, error)
+
+	// SampleColor returns the color of the screen pixel at p, in the
+	// coordinate space that spans all of the screen's Displays.
+	SampleColor(p image.Point) (color.Color, error)
+
+	// PickColor grabs the pointer and waits for the user to click anywhere on
+	// the screen, returning the color of the pixel that was clicked.
+	PickColor() (color.Color, error)
+
+	// SetSessionID sets the client ID that the session manager assigned to
+	// this program, so that the session manager can restore the program's
+	// windows in a later session. Once set, each Window is sent a
+	// SaveSessionEvent when the session manager asks the program to save its
+	// state.
+	SetSessionID(id string)
+
+	// CreatePointerBarrier creates a barrier along the horizontal or vertical
+	// line segment from (x1, y1) to (x2, y2), in screen coordinates, that
+	// stops the pointer from crossing it, except in the given directions.
+	// This can be used to make screen edges sticky. A BarrierHitEvent is
+	// sent whenever the barrier stops the pointer.
+	CreatePointerBarrier(x1, y1, x2, y2 int, directions BarrierDirection) (Barrier, error)
+
+	// IdleTime returns how long it has been since the user last interacted
+	// with the screen via a keyboard, mouse or other input device.
+	IdleTime() (time.Duration, error)
+
+	// SetIdleThreshold sets how long the user must be idle before they are
+	// considered away. Every Window is sent a UserActiveEvent when the user
+	// resumes interacting with the screen after such an idle period. A zero
+	// or negative threshold, the default, disables UserActiveEvents.
+	SetIdleThreshold(d time.Duration)
+
+	// KeyRepeat returns the delay before a held key starts repeating, and
+	// the interval between repeats.
+	KeyRepeat() (delay, interval time.Duration, err error)
+
+	// SetKeyRepeat sets the delay before a held key starts repeating, and the
+	// interval between repeats.
+	//
+	// On X11, this changes the setting for the whole X server, not just for
+	// this program's windows. The first call records the prior setting, which
+	// RestoreKeyRepeat restores.
+	SetKeyRepeat(delay, interval time.Duration) error
+
+	// RestoreKeyRepeat restores the key repeat setting that was in effect
+	// before the first SetKeyRepeat call. It is a no-op if SetKeyRepeat has
+	// not been called.
+	RestoreKeyRepeat() error
+
+	// SetCursorSize sets the size, in pixels, of cursors subsequently set by
+	// Window.SetCursorImage. A zero or negative px restores the default size,
+	// which is based on the desktop's cursor size setting if there is one
+	// and otherwise scales with the screen's pixel density.
+	SetCursorSize(px int)
+
+	// KeyboardMapping returns the keyboard mapping: the keysyms, as defined
+	// by X11's keysymdef.h, of each keycode, indexed by keycode. Keycodes
+	// that the platform does not use have no keysyms. The mapping is kept up
+	// to date as the user changes the keyboard layout.
+	KeyboardMapping() [][]uint32
+
+	// ModifierMapping returns the keycodes bound to each of the eight X11
+	// modifiers: Shift, Lock, Control and Mod1 to Mod5.
+	ModifierMapping() [8][]uint8
+
+	// NewAlarm returns a channel that receives the time every interval,
+	// like a time.Ticker, for animation timing. Where supported, the ticks
+	// come from the display server's clock rather than the program's. Ticks
+	// are dropped if the receiver falls behind. The returned function stops
+	// the alarm; it does not close the channel.
+	NewAlarm(interval time.Duration) (<-chan time.Time, func())
+
+	// PointerMapping returns the pointer button mapping: element i is the
+	// logical button, as reported in mouse.Events, that physical button i+1
+	// is mapped to, or zero if it is disabled. The number of elements is the
+	// number of physical buttons. For example, a left-handed mapping of a
+	// three-button mouse is [3 2 1].
+	PointerMapping() []byte
+
+	// HasCompositor returns whether a compositing manager is running. See
+	// also CompositorChangeEvent.
+	HasCompositor() bool
+
+	// ClipboardTargets returns the formats, such as "image/png" or
+	// "UTF8_STRING", that the owner of the named selection, such as
+	// "CLIPBOARD" or "PRIMARY", can provide. It returns no formats if the
+	// selection has no owner.
+	ClipboardTargets(selection string) ([]string, error)
+
+	// Errors returns a channel that receives the errors that the windowing
+	// system reports asynchronously, for requests whose result the driver
+	// did not wait for, such as setting a Window's title or other
+	// properties. Such errors are typically *ProtocolErrors. The
+	// channel is buffered, and errors are dropped when it is full, so that
+	// programs need not read from it.
+	Errors() <-chan error
+
+	// SetRawEventHandler sets a handler for the driver's native events, so
+	// that programs can use parts of the windowing system that the screen
+	// package does not cover. For the X11 driver, ev is an xgb.Event, such
+	// as an event of an extension that the program has initialized itself.
+	// The handler is given every event before the driver handles it, and
+	// returns whether it consumed the event, in which case the driver does
+	// not handle it. It is called on the driver's event loop, so it should
+	// not block. A nil handler removes it.
+	SetRawEventHandler(handler func(ev interface{}) bool)
+
+	// Bell rings the system bell. The percent, from -100 to 100, adjusts the
+	// volume relative to the base volume: 0 rings at the base volume, 100 at
+	// full volume and -100 silently.
+	Bell(percent int) error
+}
+
+// Barrier is a pointer barrier created by Screen.CreatePointerBarrier.
+type Barrier interface {
+	// Release removes the barrier.
+	Release()
+}
+
+// BarrierDirection is a bitmask of the directions in which a pointer barrier
+// lets the pointer cross it.
+type BarrierDirection uint32
+
+const (
+	BarrierPositiveX BarrierDirection = 1 << iota
+	BarrierPositiveY
+	BarrierNegativeX
+	BarrierNegativeY
+
+	// BarrierBlockAll is the zero value; the barrier blocks the pointer in
+	// every direction.
+	BarrierBlockAll BarrierDirection = 0
+)
+
+// Display is a physical display, such as a monitor or a projector, that shows
+// part of a Screen.
+type Display interface {
+	// Name returns the display's name, such as "HDMI-1".
+	Name() string
+
+	// Bounds returns the part of the screen, in pixels, that is shown on the
+	// display. Displays may overlap, for example when mirroring.
+	Bounds() image.Rectangle
+
+	// Primary returns whether the display is the primary display.
+	Primary() bool
+
+	// WorkArea returns the part of Bounds that is not covered by panels or
+	// docks, where windows should be placed. It is Bounds if the window
+	// manager does not report a work area.
+	WorkArea() image.Rectangle
+
+	// Modes returns the video modes that the display supports.
+	Modes() []VideoMode
+
+	// RefreshRate returns the refresh rate, in Hz, of the display's current
+	// video mode, or zero if it is not known. Programs can pace animations
+	// to it instead of assuming 60 Hz.
+	RefreshRate() float64
+
+	// SetMode changes the display's video mode to m, which must be one of
+	// the modes returned by Modes. The original mode is restored when the
+	// driver's Main function returns.
+	SetMode(m VideoMode) error
+}
+
+// VideoMode is a display resolution and refresh rate.
+type VideoMode struct {
+	// Width and Height are the display's size in pixels.
+	Width, Height int
+
+	// RefreshRate is the display's refresh rate in Hz, or zero if unknown.
+	RefreshRate float64
 }
 
 // TODO: rename Buffer to Image, to be less confusing with a Window's back and
@@ -139,6 +344,43 @@ type Buffer interface {
 	// and so is this:
 	//	*buffer.RGBA() = anotherImageRGBA
 	RGBA() *image.RGBA
+
+	// MarkDirty records that the pixels in r have changed since the Buffer
+	// was last uploaded.
+	//
+	// Dirty-region tracking is optional. Once MarkDirty or MarkAllDirty has
+	// been called, uploads transfer only the part of their source rectangle
+	// that lies within the union of the dirty regions, and the dirty regions
+	// are cleared by an upload whose source rectangle covers them. Until
+	// then, uploads transfer their entire source rectangle.
+	MarkDirty(r image.Rectangle)
+
+	// MarkAllDirty records that every pixel has changed since the Buffer was
+	// last uploaded, forcing the next upload to transfer its entire source
+	// rectangle.
+	MarkAllDirty()
+}
+
+// PalettedBuffer is a Buffer whose pixels are indexes into a palette of up to
+// 256 colors, rather than RGBA values. Changing the palette changes the color
+// of every pixel with the changed indexes, such as for color cycling, without
+// touching the pixels themselves.
+//
+// The palette indexes are converted to RGBA when the PalettedBuffer is
+// uploaded, overwriting the uploaded part of the image returned by its RGBA
+// method. Programs should modify the image returned by Paletted instead.
+type PalettedBuffer interface {
+	Buffer
+
+	// Paletted returns the pixel buffer as an *image.Paletted. As with a
+	// Buffer's RGBA method, the pixels can be modified when the buffer is not
+	// uploading, but the Pix slice itself should not be modified. The
+	// Palette field should be changed via SetPalette instead of directly.
+	Paletted() *image.Paletted
+
+	// SetPalette sets the palette. Indexes beyond the end of p are
+	// transparent black.
+	SetPalette(p color.Palette)
 }
 
 // Texture is a pixel buffer, but not one that is directly accessible as a
@@ -166,10 +408,35 @@ type Texture interface {
 
 	Uploader
 
+	// UploadBatch uploads several parts of Buffers to the Texture, like
+	// calling Upload for each part, but without waiting for each upload to
+	// complete before starting the next. It is much faster than separate
+	// Upload calls for many small parts, such as when building a glyph
+	// atlas.
+	UploadBatch(parts []UploadPart)
+
 	// TODO: also implement Drawer? If so, merge the Uploader and Drawer
 	// interfaces??
 }
 
+// UploadPart is one part of a Texture.UploadBatch: it uploads the sr part of
+// Src to DP in the Texture, like Upload(DP, Src, SR).
+type UploadPart struct {
+	DP  image.Point
+	Src Buffer
+	SR  image.Rectangle
+}
+
+// RenderTarget is a Texture that can also be drawn on, like a Window. For
+// example, a program can draw a complex scene onto a RenderTarget once, and
+// then draw that cached result onto a Window in a single operation.
+//
+// Drawing a RenderTarget onto itself is undefined.
+type RenderTarget interface {
+	Texture
+	Drawer
+}
+
 // EventDeque is an infinitely buffered double-ended queue of events.
 type EventDeque interface {
 	// Send adds an event to the end of the deque. They are returned by
@@ -194,6 +461,17 @@ type EventDeque interface {
 	// events, of those types above or of other types, via Send or SendFirst.
 	NextEvent() interface{}
 
+	// TryNextEvent is like NextEvent, except that it does not block. If no
+	// event has been sent, it returns immediately with false. It can be
+	// freely interleaved with NextEvent.
+	TryNextEvent() (event interface{}, ok bool)
+
+	// Do arranges for f to be called by the goroutine that calls NextEvent
+	// or TryNextEvent, in order with the other events, instead of that event
+	// being returned. It is safe to call from any goroutine, and is how other
+	// goroutines should update state owned by the event-processing goroutine.
+	Do(f func())
+
 	// TODO: LatestLifecycleEvent? Is that still worth it if the
 	// lifecycle.Event struct type loses its DrawContext field?
 
@@ -218,16 +496,439 @@ type Window interface {
 	// swaps the back buffer to the front.
 	Publish() PublishResult
 
+	// RequestPaint sends a paint.Event to the window, unless one is already
+	// pending, so that multiple requests made before the app handles the
+	// event result in a single paint.
+	RequestPaint()
+
+	// SetMaxFrameRate limits how often RequestPaint sends paint.Events to
+	// fps per second. Requests that come sooner than that after the previous
+	// paint.Event are coalesced into one that is sent when the interval has
+	// elapsed. Paint events that the driver sends itself, such as when the
+	// window is exposed, are not limited. Zero or a negative fps removes the
+	// limit, which is the default.
+	SetMaxFrameRate(fps int)
+
+	// FramesPublished returns the number of times Publish has been called.
+	FramesPublished() uint64
+
+	// BeginFrame starts a frame: a scope for drawing to the window's back
+	// buffer that ends with publishing it. It is an alternative to calling
+	// the window's Uploader and Drawer methods followed by Publish, that
+	// makes the start of each frame explicit to the driver.
+	BeginFrame() Frame
+
 	SetTitle(string) error
+
+	// Title returns the window's title, as set by SetTitle or
+	// NewWindowOptions.Title, or by another program. It returns an empty
+	// string if the window has no title.
+	Title() (string, error)
+
+	// Show shows the window, if it is hidden, like any newly created
+	// window.
+	Show() error
+
+	// ShowInactive shows the window, if it is hidden, without giving it the
+	// keyboard focus or raising it above the active window, for example
+	// for a notification.
+	ShowInactive() error
+
+	// Hide hides the window, as if it had been created with
+	// NewWindowOptions.Hidden, until Show or ShowInactive is called.
+	Hide() error
+
+	// Focus gives the window the keyboard focus. It does nothing if the
+	// window is not shown or was created with NewWindowOptions.NoFocus.
+	// The window manager may refuse the request, for example to stop
+	// windows from stealing the focus while the user is typing elsewhere.
+	Focus() error
+
 	SetCursor(Cursor) error
 	WarpMouse(p image.Point) error
+
+	// SetCursorImage sets the window's cursor to img, scaled so that its
+	// larger dimension is the cursor size set by Screen.SetCursorSize.
+	// hotspot is the point of img, in img's coordinate space, that marks the
+	// cursor's position.
+	SetCursorImage(img image.Image, hotspot image.Point) error
+
+	// SetAnimatedCursor is like SetCursorImage, but the cursor cycles
+	// through frames, showing each for the corresponding delay, such as
+	// for a busy spinner. The windowing system animates the cursor, so the
+	// program need not do anything per frame. hotspot is in the coordinate
+	// space of every frame.
+	SetAnimatedCursor(frames []image.Image, delays []time.Duration, hotspot image.Point) error
+
+	// DrawImage draws img to the window, with img's top-left at dp,
+	// composited with op. It uploads img to a scratch texture that the
+	// driver reuses across calls, so it suits images that change every frame
+	// or are drawn only once. Images that are drawn repeatedly are better
+	// uploaded once to a Texture.
+	DrawImage(dp image.Point, img image.Image, op draw.Op)
+
+	// DrawLine draws a line from p0 to p1, of the given width in pixels,
+	// filled with src and composited with op. The line's ends are square
+	// and, for a width of 1, the line passes through the centers of the
+	// pixels at p0 and p1.
+	DrawLine(p0, p1 image.Point, width float64, src color.Color, style StrokeStyle, op draw.Op)
+
+	// GrabKeyboard makes the window receive all keyboard input, including
+	// key combinations that would otherwise be intercepted by the window
+	// manager, until UngrabKeyboard or Release is called. The window manager
+	// or another client holding a grab may cause the request to be denied, in
+	// which case an error is returned.
+	GrabKeyboard() error
+
+	// UngrabKeyboard releases a grab obtained by GrabKeyboard. It is a no-op
+	// if the keyboard is not grabbed.
+	UngrabKeyboard() error
+
+	// GrabButton makes the window receive all mouse events, even when the
+	// pointer is outside the window, until btn is released or UngrabButton
+	// or Release is called. It is typically called when btn is pressed, to
+	// start a drag that should see the matching release. Another client
+	// holding a grab may cause the request to be denied, in which case an
+	// error is returned.
+	GrabButton(btn mouse.Button) error
+
+	// UngrabButton releases a grab obtained by GrabButton. It is a no-op if
+	// the pointer is not grabbed.
+	UngrabButton() error
+
+	// SetSizeHints tells the window manager the sizes that the user can
+	// resize the window to. Window managers apply them to interactive
+	// resizes, and may also apply them otherwise.
+	SetSizeHints(h SizeHints) error
+
+	// SetFrameExtents tells the compositor the size, in pixels, of the
+	// client-side decorations (such as a drop shadow) drawn in the window's
+	// margins, so that they are not treated as part of the window's visible
+	// area when, for example, tiling or snapping the window.
+	SetFrameExtents(left, top, right, bottom int) error
+
+	// SetCompositorBypass hints whether the compositor should stop compositing
+	// the window, drawing it directly to the screen instead. Bypassing the
+	// compositor can reduce latency for fullscreen windows such as games.
+	// Passing false asks the compositor to always composite the window.
+	SetCompositorBypass(on bool)
+
+	// SetBackgroundColor sets the color that the display server fills newly
+	// exposed parts of the window with, such as when the window is enlarged,
+	// before the program publishes a new frame. By default, such parts are
+	// left with whatever was previously shown there.
+	SetBackgroundColor(c color.Color)
+
+	// WaitForState blocks until predicate returns true for the window's
+	// state, as set by the window manager, or until timeout elapses, in which
+	// case it returns an error. Window managers apply requests such as
+	// FullscreenOnMonitor asynchronously, so this lets, for example, a test
+	// wait until the window is actually fullscreen.
+	WaitForState(predicate func(WindowState) bool, timeout time.Duration) error
+
+	// SetPointerAcceleration sets whether the pointer is accelerated, moving
+	// further for fast movements of the mouse than for slow ones of the
+	// same distance. Games that use the mouse for aiming typically turn it
+	// off. The setting applies only while the window has the keyboard focus;
+	// the previous setting is restored when it loses the focus or is
+	// released. The X11 driver can only change the setting for all devices
+	// and programs while the window has the focus.
+	SetPointerAcceleration(enabled bool) error
+
+	// OnProtocol sets the handler for client messages from the window
+	// manager for the given window manager protocol, such as an X11
+	// WM_PROTOCOLS atom name, and advertises that the window supports it.
+	// data is the message's data: for X11, data[0] is the protocol's atom
+	// and data[1] is usually a timestamp. The handler is called on the
+	// driver's event loop, so it should not block. A nil handler removes the
+	// protocol. Protocols that the driver handles itself, such as
+	// _NET_WM_PING, cannot be overridden.
+	OnProtocol(protocol string, handler func(data [5]uint32)) error
+
+	// SetOpacity sets the opacity of the whole window, including any
+	// decorations, from 0 (fully transparent) to 1 (fully opaque). It
+	// requires a compositing manager, and cancels any fade in progress.
+	SetOpacity(opacity float64)
+
+	// FadeOutAndRelease fades the window out to fully transparent over d,
+	// then releases it. It returns once the window is released. Like
+	// NewWindowOptions.FadeIn, fading requires a compositing manager.
+	FadeOutAndRelease(d time.Duration)
+
+	// FullscreenOnMonitor makes the window fullscreen on the Display with
+	// index i in Screen.Displays, rather than on whichever display the
+	// window manager chooses. For example, a presentation program can show
+	// its slides on a projector while keeping its controls on a laptop's
+	// built-in display. A negative i leaves fullscreen.
+	FullscreenOnMonitor(i int) error
+
+	// SetWorkspace moves the window to the workspace, also known as a
+	// virtual desktop, with index n, counting from 0.
+	SetWorkspace(n int) error
+
+	// SetAllWorkspaces sets whether the window appears on all workspaces,
+	// like a dock or panel. Calling it with false leaves the window on just
+	// the current workspace.
+	SetAllWorkspaces(all bool) error
+
+	// Workspace returns the index of the workspace that the window is on, or
+	// -1 if it is on all workspaces.
+	Workspace() (int, error)
+
+	// Lower moves the window to the bottom of the stacking order, below all
+	// of its siblings.
+	Lower() error
+
+	// StackBelow moves the window in the stacking order to be directly below
+	// other, which must be a Window created by the same Screen.
+	StackBelow(other Window) error
+
+	// SetTransientFor marks the window as a transient window, such as a
+	// dialog, that belongs to parent. parent must be a Window created by the
+	// same Screen, or nil to clear the mark.
+	SetTransientFor(parent Window) error
+
+	// SetModal sets whether the window is modal, blocking interaction with
+	// the parent given to SetTransientFor while it is open.
+	//
+	// The window manager is asked to enforce modality. In case it ignores the
+	// request, the parent window is also not sent any key or mouse events
+	// while a modal window belonging to it is open.
+	SetModal(on bool)
+
+	// StartDrag starts dragging data from the window to another program,
+	// keyed by MIME type, such as "text/plain". It should be called while a
+	// mouse button is pressed, and the data is dropped where the button is
+	// released. hotspot is the pointer position at the start of the drag, in
+	// window coordinates.
+	//
+	// A DragFinishedEvent is sent to the window when the drag is finished.
+	// If the drop target does not answer within a few seconds of the button
+	// being released, the drag is finished as not accepted.
+	StartDrag(data map[string][]byte, hotspot image.Point) error
+
+	// SetClipboard makes the window the owner of the named selection, such
+	// as "CLIPBOARD" or "PRIMARY", offering data, keyed by MIME type such as
+	// "text/html" or "image/png". Programs pasting from the selection can
+	// ask for any of the types. The data is offered until the window
+	// receives a ClipboardLostEvent or is released, and must not be
+	// modified in the meantime.
+	SetClipboard(selection string, data map[string][]byte) error
+
+	// SetShape makes the window non-rectangular. The window only consists of
+	// the pixels, in window coordinates, where region is at least half
+	// opaque: it is not drawn elsewhere, mouse clicks elsewhere go to the
+	// windows below, and the window manager does not draw decorations
+	// elsewhere. A nil region restores the window's rectangular shape.
+	SetShape(region image.Image) error
+
+	// Embed embeds foreign, a window of another program such as a video
+	// player, at the top-left of this window, per the XEmbed protocol.
+	// foreign is the native window ID, such as an X11 window ID. An
+	// EmbedEvent is sent when foreign is embedded, and when it stops being
+	// embedded, for example because the other program destroyed it.
+	Embed(foreign uint32) error
+
+	// Unembed gives foreign, which must have been passed to Embed, back to
+	// the desktop.
+	Unembed(foreign uint32) error
+
+	// SetEmbeddedBounds sets the position and size of the embedded window
+	// foreign, in window coordinates.
+	SetEmbeddedBounds(foreign uint32, r image.Rectangle) error
+
+	// SetDecorationGeometry sets, together, the geometry of a window that
+	// draws its own decorations: the visible part of the window, the part
+	// that receives mouse input, and the frame extents as for
+	// SetFrameExtents, indexed by left, top, right and bottom. An empty
+	// visible or input rectangle means the whole window. It should be called
+	// again when the window is resized.
+	SetDecorationGeometry(visible, input image.Rectangle, shadow [4]int) error
+
+	// PushClip restricts subsequent Upload, Fill and Drawer calls on the
+	// window to the intersection of r and the current clip rectangle, if any.
+	// Each PushClip call should be balanced by a PopClip call.
+	PushClip(r image.Rectangle)
+
+	// PopClip restores the clip rectangle that was in effect before the most
+	// recent PushClip call. It is a no-op if the clip stack is empty.
+	PopClip()
+
+	// ScaleAspect is like Scale, but preserves the aspect ratio of sr,
+	// centering the scaled source in dr. FitContain scales the source to
+	// fit inside dr, leaving the rest of dr untouched, and FitCover scales
+	// it to cover all of dr, cropping the parts that overflow.
+	ScaleAspect(dr image.Rectangle, src Texture, sr image.Rectangle, fit ScaleFit, op draw.Op)
+
+	// FillTexture fills dr with copies of src, tiled horizontally and
+	// vertically so that one copy's top-left corner is at offset, and
+	// composited with op. It is more efficient than calling Copy for each
+	// tile, such as for patterned backgrounds.
+	FillTexture(dr image.Rectangle, src Texture, offset image.Point, op draw.Op)
+
+	// PressedKeys returns the keys that are currently held down, whether or
+	// not the window has the keyboard focus, and including keys pressed
+	// before it gained the focus. It lets programs such as games poll the
+	// keyboard once per frame instead of tracking key.Events.
+	PressedKeys() (map[key.Code]bool, error)
+
+	// EventsByType returns a channel that receives the events, sent after
+	// the call, whose type is one of kinds, instead of NextEvent. For
+	// example, a program can handle key.Event and mouse.Event values on one
+	// goroutine while another handles paint.Event values:
+	//
+	//	input := w.EventsByType(reflect.TypeOf(key.Event{}), reflect.TypeOf(mouse.Event{}))
+	//
+	// Each channel has its own queue, so that a slow receiver on one channel
+	// does not hold up the others or NextEvent. A later call for the same
+	// type replaces the earlier one for that type, and a channel that no
+	// longer receives any type is closed.
+	EventsByType(kinds ...reflect.Type) <-chan interface{}
+
+	// Geometry returns the window's current position, size and maximized
+	// state. See also SaveGeometry.
+	Geometry() (GeometrySpec, error)
+
+	// CurrentDisplay returns the Display that shows the largest part of the
+	// window, or nil if the window is not on any display.
+	CurrentDisplay() Display
+
+	// ModifierState returns which modifier keys are held down and which lock
+	// keys are on, without waiting for a key event. For example, it can be
+	// called when the window gains the keyboard focus.
+	ModifierState() (key.Modifiers, LockState, error)
+
+	// CaptureBackBuffer returns a copy of the window's back buffer: what
+	// has been drawn to the window, whether or not it has been published.
+	// Unlike a capture of the window on screen, it is not affected by other
+	// windows covering this one, which makes it suitable for automated
+	// visual testing.
+	CaptureBackBuffer() (*image.RGBA, error)
 }
 
+// LockState is a bitmask of the lock keys that are on.
+type LockState uint32
+
+const (
+	CapsLock LockState = 1 << iota
+	NumLock
+	ScrollLock
+)
+
+// ProtocolError is an error reported by the windowing system for a request
+// that the driver made.
+type ProtocolError struct {
+	// Request names the request that failed. For X11, it is the name of a
+	// core request, such as "CopyArea", or an extension's name and minor
+	// opcode, such as "RENDER:8".
+	Request string
+
+	// Resource is the ID of the resource, such as a window or pixmap, that
+	// the error is about, if any.
+	Resource uint32
+
+	// Err is the driver's underlying error.
+	Err error
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("screen: %s request for resource %#x failed: %v", e.Request, e.Resource, e.Err)
+}
+
+// ScaleFit is how Window.ScaleAspect fits a source rectangle into a
+// destination rectangle with a different aspect ratio.
+type ScaleFit uint8
+
+const (
+	// FitContain letterboxes the source, scaling it to the largest size that
+	// fits inside the destination.
+	FitContain ScaleFit = iota
+	// FitCover crops the source, scaling it to the smallest size that
+	// covers the destination.
+	FitCover
+)
+
+// Rect returns the rectangle, centered in dr, that a source of the given size
+// is scaled to, preserving its aspect ratio. For FitCover, the result can
+// extend past dr. If size is empty, the result is the empty rectangle at
+// dr.Min.
+func (f ScaleFit) Rect(dr image.Rectangle, size image.Point) image.Rectangle {
+	if size.X <= 0 || size.Y <= 0 {
+		return image.Rectangle{Min: dr.Min}
+	}
+	// Compare dr.Dx()/size.X with dr.Dy()/size.Y without dividing.
+	wider := dr.Dx()*size.Y > dr.Dy()*size.X
+	if wider == (f == FitContain) {
+		size = image.Point{size.X * dr.Dy() / size.Y, dr.Dy()}
+	} else {
+		size = image.Point{dr.Dx(), size.Y * dr.Dx() / size.X}
+	}
+	min := dr.Min.Add(dr.Size().Sub(size).Div(2))
+	return image.Rectangle{Min: min, Max: min.Add(size)}
+}
+
+// SizeHints are the constraints on a Window's size, in pixels, set by
+// Window.SetSizeHints. A zero field means no constraint.
+type SizeHints struct {
+	// Min and Max are the minimum and maximum size.
+	Min, Max image.Point
+
+	// Aspect, if both its X and Y are positive, is the aspect ratio,
+	// width:height, that the window keeps, such as (16, 9).
+	Aspect image.Point
+}
+
+// WindowState is the state of a Window, as set by the window manager.
+type WindowState struct {
+	Fullscreen bool
+	Maximized  bool
+	Minimized  bool
+}
+
+// Frame is a frame started by Window.BeginFrame. Its Uploader and Drawer
+// methods draw to the window's back buffer. A Frame must not be used after
+// its End method is called.
+type Frame interface {
+	Uploader
+	Drawer
+
+	// Size returns the size of the back buffer, which is the window's size
+	// when the frame began.
+	Size() image.Point
+
+	// End ends the frame and publishes it, like Window.Publish.
+	End() PublishResult
+}
+
+// StrokeStyle is the style of a line drawn by Window.DrawLine.
+type StrokeStyle int
+
+const (
+	StrokeSolid StrokeStyle = iota
+	StrokeDashed
+	StrokeDotted
+)
+
 // PublishResult is the result of an Window.Publish call.
 type PublishResult struct {
 	// BackBufferPreserved is whether the contents of the back buffer was
 	// preserved. If false, the contents are undefined.
 	BackBufferPreserved bool
+
+	// FrameTime is approximately how long the server took to process the
+	// drawing requests of the published frame, measured by waiting for it to
+	// catch up. It is zero if the window was created with
+	// NewWindowOptions.Unthrottled, as Publish then does not wait. A
+	// FrameTime that is large compared to the frame interval means that the
+	// server, rather than the program, is the bottleneck.
+	FrameTime time.Duration
+
+	// LastInputTime is the timestamp of the most recent key or mouse event
+	// that the window received before the frame was published, in
+	// milliseconds on the windowing system's clock, or zero if there has
+	// been no such event. The driver cannot tell which events the program
+	// has processed, only which it has been sent.
+	LastInputTime uint32
 }
 
 // NewWindowOptions are optional arguments to NewWindow.
@@ -240,9 +941,115 @@ type NewWindowOptions struct {
 	// Title specifies the window title.
 	Title string
 
+	// Unthrottled disables the flow control that Window.Publish otherwise
+	// performs, waiting for the server to catch up on previously sent
+	// drawing requests. Without it, a program can send drawing requests far
+	// faster than the server can process them, overloading it and making it
+	// unresponsive. It should only be used for benchmarking maximum render
+	// throughput, alongside Window.FramesPublished.
+	Unthrottled bool
+
+	// PreventClose prevents the user from closing the window, such as via
+	// the window manager's close button. The program can still close the
+	// window by calling its Release method. On X11, this is done by not
+	// advertising the WM_DELETE_WINDOW protocol, and some window managers
+	// respond to a close request for such a window by disconnecting the
+	// program from the X server.
+	PreventClose bool
+
+	// ReportDIP specifies that mouse event coordinates are in device
+	// independent points, consistent with size.Event's WidthPt and HeightPt,
+	// instead of pixels. The point passed to the window's WarpMouse method is
+	// then also in points.
+	ReportDIP bool
+
+	// NoFocus specifies that the window never takes the keyboard focus, as
+	// for tool palettes and on-screen keyboards. The window still receives
+	// mouse events, but key events go to whichever window had the focus.
+	NoFocus bool
+
+	// Overlay specifies that the window is a transient overlay, such as a
+	// notification. Overlay windows are not managed by the window manager:
+	// they have no decorations, stay above normal windows and, where
+	// supported, do not receive key or mouse events, which instead pass
+	// through to the windows below.
+	Overlay bool
+
+	// Tooltip specifies that an Overlay window is a tooltip. Unlike other
+	// overlays, tooltips receive mouse events, so that they can be hovered
+	// over or dismissed with a click. It is ignored for other windows.
+	Tooltip bool
+
+	// X and Y specify the position, in screen coordinates, of the top-left
+	// corner of an Overlay window. They are ignored for other windows.
+	X, Y int
+
+	// MaxQueuedEvents, if positive, is the number of events that the
+	// window's event queue holds before QueueOverflow applies. The default,
+	// zero, means that the queue grows without bound.
+	MaxQueuedEvents int
+
+	// QueueOverflow is what happens when an event is sent to a full event
+	// queue. The default is OverflowDropMotion.
+	QueueOverflow OverflowPolicy
+
+	// Geometry, if non-nil, is the window's initial position and maximized
+	// state. It is usually set by ApplyGeometry, which also sets Width and
+	// Height. It is ignored for Overlay windows.
+	Geometry *GeometrySpec
+
+	// FadeIn, if positive, is how long the window takes to fade in from
+	// fully transparent when it is first shown. Fading requires a
+	// compositing manager; without one, the window is shown immediately.
+	// See also Window.FadeOutAndRelease.
+	FadeIn time.Duration
+
+	// VisualID, if non-zero, is the ID of the X11 visual that the window
+	// uses, instead of the root window's, such as one chosen with GLX for
+	// rendering to the window with OpenGL. The visual must be a TrueColor
+	// visual of depth 24 or 32. Drivers other than the X11 driver ignore it.
+	VisualID uint32
+
+	// ResizeDebounce, if positive, coalesces the many size changes of an
+	// interactive resize: a size.Event is only sent once the window has not
+	// been resized for ResizeDebounce, for the window's final size. The first
+	// size.Event is sent immediately. The default, zero, sends a size.Event
+	// for every size change.
+	ResizeDebounce time.Duration
+
+	// Hidden is whether the window is not shown when it is created. Call
+	// Window.Show or Window.ShowInactive to show it. FadeIn is ignored for
+	// hidden windows.
+	Hidden bool
+
 	// TODO: fullscreen, icon, cursorHidden?
 }
 
+// OverflowPolicy is what happens when an event is sent to a full event queue.
+// Events sent via EventDeque.SendFirst are never dropped and never block. With
+// either policy, a paint.Event sent to a full queue that already holds one is
+// dropped, as the queued one suffices.
+type OverflowPolicy uint8
+
+const (
+	// OverflowDropMotion drops the oldest queued mouse motion event to make
+	// room. Other events, such as key events and mouse button and wheel
+	// events, are never dropped, so the queue can still grow beyond its
+	// limit if it holds nothing else.
+	OverflowDropMotion OverflowPolicy = iota
+
+	// OverflowBlock blocks the sender until the queue has room. The
+	// goroutine that calls NextEvent must then not send events to its own
+	// full queue, or it will deadlock.
+	//
+	// Only the program's own Send and SendUnique calls block. The events
+	// that the driver sends, such as key, mouse and size events, are
+	// handled as for OverflowDropMotion instead, as blocking the driver's
+	// event loop would stall every window, and any Upload waiting for the
+	// X11 server to finish with a buffer.
+	OverflowBlock
+)
+
 // GetTitle returns a sanitized form of o.Title. In particular, its length will
 // not exceed 4096, and it may be further truncated so that it is valid UTF-8
 // and will not contain the NUL byte.
@@ -292,7 +1099,9 @@ type Uploader interface {
 	Upload(dp image.Point, src Buffer, sr image.Rectangle)
 
 	// Fill fills that part of the destination (the method receiver) defined by
-	// dr with the given color.
+	// dr with the given color. For a Texture, this operates directly on the
+	// Texture's pixels, so clearing a Texture does not need a Buffer. For
+	// example, Fill(t.Bounds(), color.Transparent, draw.Src) clears t.
 	//
 	// When filling a Window, there will not be any visible effect until
 	// Publish is called.
@@ -351,12 +1160,81 @@ const (
 	Src  = draw.Src
 )
 
-// DrawOptions are optional arguments to Draw.
+// DrawOptions are optional arguments to Draw. A nil *DrawOptions is
+// equivalent to a pointer to the zero value. See also NewDrawOptions.
 type DrawOptions struct {
-	// TODO: transparency in [0x0000, 0xffff]?
-	// TODO: scaler (nearest neighbor vs linear)?
+	// Mask, if non-nil, is a Texture whose values modulate the color of a
+	// DrawUniform call, such as a Texture holding rasterized glyphs. The sr
+	// argument to DrawUniform is then in the Mask's coordinate space. A Mask
+	// is only supported when src2dst is a scale and translation; it is
+	// ignored by the other Drawer methods.
+	Mask Texture
+
+	// SubpixelOrder is the order of the display's physical subpixels, for LCD
+	// (subpixel) antialiasing. If it is not SubpixelNone, the Mask's red,
+	// green and blue channels hold the coverage of the left, middle and right
+	// thirds of each pixel, as produced by the text.DrawLCD function, and each
+	// modulates the corresponding color component separately. The zero value
+	// means grayscale antialiasing, where only the Mask's alpha channel is
+	// used.
+	SubpixelOrder SubpixelOrder
+
+	// Blend, if not BlendNone, is the blend mode used to combine the source
+	// with the destination, instead of the draw.Op argument.
+	//
+	// BlendAdd is always supported. The other modes are separable blend
+	// modes as defined by PDF and SVG compositing, and may not be supported
+	// by every driver. For example, the X11 driver requires version 0.11 or
+	// later of the X Rendering Extension. When unsupported, they are emulated
+	// by drawing with the draw.Over operator.
+	Blend BlendMode
+
+	// Filter is how a Texture is sampled when it is scaled or transformed by
+	// the Draw, Copy and Scale methods. The zero value means FilterNearest.
+	Filter Filter
+
+	// Clip, if not empty, restricts the drawing to the dst-space rectangle
+	// Clip, in addition to any Window.PushClip clip rectangle.
+	Clip image.Rectangle
+
+	// Transparency, if positive, multiplies the alpha of everything drawn by
+	// the Draw, Copy, Scale and DrawUniform methods by 1 - Transparency, for
+	// example to fade a whole layer uniformly. The zero value means that
+	// what is drawn is unchanged, and 1 or more means that it is fully
+	// transparent, so that a fade out can animate Transparency from 0 to 1.
+	Transparency float64
 }
 
+// Filter is the sampling filter for the Drawer methods.
+type Filter uint8
+
+const (
+	FilterNearest Filter = iota
+	FilterLinear
+)
+
+// BlendMode is a blend mode for the Drawer methods.
+type BlendMode uint8
+
+const (
+	BlendNone BlendMode = iota
+	BlendAdd
+	BlendMultiply
+	BlendScreen
+	BlendDarken
+	BlendLighten
+)
+
+// SubpixelOrder is the horizontal order of a display's red, green and blue
+// subpixels.
+type SubpixelOrder uint8
+
+const (
+	SubpixelNone SubpixelOrder = iota
+	SubpixelRGB
+	SubpixelBGR
+)
+
 type Cursor int
 
 const (
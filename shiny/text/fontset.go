@@ -0,0 +1,116 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontSet is a font.Face that combines an ordered list of faces. For each
+// rune, it uses the first face that has a glyph for that rune, so that, for
+// example, a Latin text face can fall back on an emoji or CJK face.
+//
+// Since a FontSet is itself a font.Face, it can be passed to Frame.SetFace,
+// MeasureRunes, DrawLCD or a font.Drawer, and measuring and drawing the same
+// text will always pick the same face for each rune.
+type FontSet struct {
+	faces []font.Face
+}
+
+// NewFontSet returns a FontSet that falls back on faces in the given order.
+// It panics if faces is empty.
+func NewFontSet(faces ...font.Face) *FontSet {
+	if len(faces) == 0 {
+		panic("text: NewFontSet called with no faces")
+	}
+	return &FontSet{faces: append([]font.Face(nil), faces...)}
+}
+
+// FaceFor returns the face that f uses for r. If none of f's faces has a
+// glyph for r, it returns the first face.
+func (f *FontSet) FaceFor(r rune) font.Face {
+	face, _ := f.lookup(r)
+	return face
+}
+
+func (f *FontSet) lookup(r rune) (face font.Face, ok bool) {
+	for _, face := range f.faces {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face, true
+		}
+	}
+	return f.faces[0], false
+}
+
+// Close closes all of f's faces, returning the first error encountered.
+func (f *FontSet) Close() error {
+	var err error
+	for _, face := range f.faces {
+		if err1 := face.Close(); err == nil {
+			err = err1
+		}
+	}
+	return err
+}
+
+func (f *FontSet) Glyph(dot fixed.Point26_6, r rune) (
+	dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+
+	face, ok := f.lookup(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	return face.Glyph(dot, r)
+}
+
+func (f *FontSet) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	face, ok := f.lookup(r)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	return face.GlyphBounds(r)
+}
+
+func (f *FontSet) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	face, ok := f.lookup(r)
+	if !ok {
+		return 0, false
+	}
+	return face.GlyphAdvance(r)
+}
+
+// Kern returns the kerning adjustment for r0 followed by r1. Runes drawn
+// with different faces are not kerned.
+func (f *FontSet) Kern(r0, r1 rune) fixed.Int26_6 {
+	face0, ok0 := f.lookup(r0)
+	face1, ok1 := f.lookup(r1)
+	if !ok0 || !ok1 || face0 != face1 {
+		return 0
+	}
+	return face0.Kern(r0, r1)
+}
+
+// Metrics returns the metrics of f's first face, with the ascent, descent
+// and height enlarged to fit the fallback faces, so that lines of text with
+// fallback glyphs are not clipped.
+func (f *FontSet) Metrics() font.Metrics {
+	m := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		n := face.Metrics()
+		if m.Ascent < n.Ascent {
+			m.Ascent = n.Ascent
+		}
+		if m.Descent < n.Descent {
+			m.Descent = n.Descent
+		}
+		if m.Height < n.Height {
+			m.Height = n.Height
+		}
+	}
+	return m
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/inconsolata"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestFontSet(t *testing.T) {
+	// basicfont.Face7x13 only has ASCII glyphs, so é and € fall back on
+	// inconsolata.
+	primary, fallback := basicfont.Face7x13, inconsolata.Regular8x16
+	fs := NewFontSet(primary, fallback)
+
+	testCases := []struct {
+		r    rune
+		want font.Face
+		ok   bool
+	}{
+		{'a', primary, true},
+		{'é', fallback, true},
+		{'€', fallback, true},
+		{'世', primary, false},
+	}
+	for _, tc := range testCases {
+		if got := fs.FaceFor(tc.r); got != tc.want {
+			t.Errorf("%q: FaceFor: got %v, want %v", tc.r, got, tc.want)
+		}
+		got, ok := fs.GlyphAdvance(tc.r)
+		if ok != tc.ok {
+			t.Errorf("%q: GlyphAdvance: got ok=%t, want %t", tc.r, ok, tc.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if want, _ := tc.want.GlyphAdvance(tc.r); got != want {
+			t.Errorf("%q: GlyphAdvance: got %v, want %v", tc.r, got, want)
+		}
+		if _, _, _, a, _ := fs.Glyph(fixed.Point26_6{}, tc.r); a != got {
+			t.Errorf("%q: Glyph advance: got %v, want %v", tc.r, a, got)
+		}
+	}
+
+	// Measuring agrees with the faces used for drawing.
+	s := "café €5"
+	advances := MeasureRunes(fs, s)
+	if total, want := advances[len(advances)-1], font.MeasureString(fs, s); total != want {
+		t.Errorf("MeasureRunes total: got %v, want %v", total, want)
+	}
+
+	if got, want := fs.Metrics().Ascent, fallback.Metrics().Ascent; got < want {
+		t.Errorf("Metrics: Ascent: got %v, want at least %v", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// LayoutLine is a line of text laid out by Layout.
+type LayoutLine struct {
+	// s[Start:End] is the line's text, where s is the string passed to
+	// Layout. It excludes the newline or spaces at which the line was
+	// broken.
+	Start, End int
+
+	// Runs are the parts of the line's text that are drawn with the same
+	// face. Unless Layout's face is a *FontSet, there is exactly one Run.
+	Runs []Run
+
+	// Bounds is the line's bounding box, relative to the top-left of the
+	// laid out text. Its height is the face's line height, and its width
+	// is the advance width of the line's text.
+	Bounds fixed.Rectangle26_6
+
+	// Baseline is the y coordinate, in the same coordinate space as Bounds,
+	// of the line's baseline.
+	Baseline fixed.Int26_6
+}
+
+// Run is a contiguous part of a LayoutLine drawn with a single face.
+type Run struct {
+	Face font.Face
+
+	// s[Start:End] is the run's text, where s is the string passed to
+	// Layout.
+	Start, End int
+
+	// X is the distance from the start of the line to the start of the run.
+	X fixed.Int26_6
+}
+
+// Layout lays out s as lines of text drawn with face, breaking lines at
+// newlines and, if maxWidth is positive, wrapping them at spaces so that no
+// line is wider than maxWidth. A word that is wider than maxWidth by itself
+// is broken between runes. An empty s or an empty line in s results in an
+// empty LayoutLine.
+func Layout(face font.Face, s string, maxWidth fixed.Int26_6) []LayoutLine {
+	// The line height matches that of a Frame.
+	m := face.Metrics()
+	ascent := fixed.I(m.Ascent.Ceil())
+	height := fixed.I(m.Ascent.Ceil() + m.Descent.Ceil())
+
+	var lines []LayoutLine
+	emit := func(start, end int) {
+		y := height * fixed.Int26_6(len(lines))
+		width := font.MeasureString(face, s[start:end])
+		lines = append(lines, LayoutLine{
+			Start: start,
+			End:   end,
+			Runs:  layoutRuns(face, s, start, end),
+			Bounds: fixed.Rectangle26_6{
+				Min: fixed.Point26_6{Y: y},
+				Max: fixed.Point26_6{X: width, Y: y + height},
+			},
+			Baseline: y + ascent,
+		})
+	}
+
+	for off := 0; ; {
+		n := strings.IndexByte(s[off:], '\n')
+		if n < 0 {
+			layoutParagraph(face, s, off, len(s), maxWidth, emit)
+			break
+		}
+		layoutParagraph(face, s, off, off+n, maxWidth, emit)
+		off += n + 1
+	}
+	return lines
+}
+
+// layoutParagraph wraps s[start:end], which contains no newlines, calling
+// emit with the start and end of each line.
+func layoutParagraph(face font.Face, s string, start, end int, maxWidth fixed.Int26_6, emit func(start, end int)) {
+	// offsets[i] is the byte offset in s of the i'th rune of the paragraph,
+	// and advances[i] is the distance from the start of the paragraph to the
+	// end of that rune.
+	var offsets []int
+	for i := range s[start:end] {
+		offsets = append(offsets, start+i)
+	}
+	offsets = append(offsets, end)
+	advances := MeasureRunes(face, s[start:end])
+	isSpace := func(i int) bool { return s[offsets[i]] == ' ' }
+
+	// first is the first rune of the current line, and brk, if greater than
+	// first, is the space after the line's last whole word.
+	first, brk := 0, -1
+	for i := 0; i < len(advances); i++ {
+		if isSpace(i) {
+			if i > first && !isSpace(i-1) {
+				brk = i
+			}
+			continue
+		}
+		x0 := fixed.Int26_6(0)
+		if first > 0 {
+			x0 = advances[first-1]
+		}
+		if maxWidth <= 0 || advances[i]-x0 <= maxWidth || i == first {
+			continue
+		}
+
+		next := i
+		if brk > first {
+			emit(offsets[first], offsets[brk])
+			for next = brk; isSpace(next); next++ {
+			}
+		} else {
+			// No space to break at, so break mid-word.
+			emit(offsets[first], offsets[i])
+		}
+		first, brk = next, -1
+		// Lay out the runes after the break again, as they may not fit on
+		// the new line either.
+		i = first - 1
+	}
+	emit(offsets[first], end)
+}
+
+// layoutRuns splits s[start:end] into Runs.
+func layoutRuns(face font.Face, s string, start, end int) []Run {
+	fs, ok := face.(*FontSet)
+	if !ok || start == end {
+		return []Run{{Face: face, Start: start, End: end}}
+	}
+	var runs []Run
+	for i := start; i < end; {
+		r, n := utf8.DecodeRuneInString(s[i:end])
+		f := fs.FaceFor(r)
+		if len(runs) == 0 || runs[len(runs)-1].Face != f {
+			runs = append(runs, Run{
+				Face:  f,
+				Start: i,
+				X:     font.MeasureString(face, s[start:i]),
+			})
+		}
+		i += n
+		runs[len(runs)-1].End = i
+	}
+	return runs
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/inconsolata"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestLayout(t *testing.T) {
+	// Every glyph of inconsolata.Regular8x16 is 8 pixels wide.
+	face := inconsolata.Regular8x16
+	testCases := []struct {
+		s        string
+		maxWidth int // In glyphs.
+		want     []string
+	}{
+		{"", 10, []string{""}},
+		{"hello", 0, []string{"hello"}},
+		{"hello world", 0, []string{"hello world"}},
+		{"hello world", 11, []string{"hello world"}},
+		{"hello world", 10, []string{"hello", "world"}},
+		{"hello world", 5, []string{"hello", "world"}},
+		{"hello   world", 7, []string{"hello", "world"}},
+		{"a b c d e", 3, []string{"a b", "c d", "e"}},
+		{"one\ntwo\n\nthree", 0, []string{"one", "two", "", "three"}},
+		{"one\n", 10, []string{"one", ""}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"ab abcdefghij", 4, []string{"ab", "abcd", "efgh", "ij"}},
+		{"  indented text", 10, []string{"  indented", "text"}},
+		{"héllo wörld", 6, []string{"héllo", "wörld"}},
+		{"x", 0, []string{"x"}},
+	}
+	for _, tc := range testCases {
+		lines := Layout(face, tc.s, fixed.I(8*tc.maxWidth))
+		var got []string
+		for _, l := range lines {
+			got = append(got, tc.s[l.Start:l.End])
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q, maxWidth %d: got %q, want %q", tc.s, tc.maxWidth, got, tc.want)
+		}
+	}
+}
+
+func TestLayoutBounds(t *testing.T) {
+	face := inconsolata.Regular8x16
+	m := face.Metrics()
+	height := fixed.I(m.Ascent.Ceil() + m.Descent.Ceil())
+
+	lines := Layout(face, "ab cde", fixed.I(8*4))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	for i, l := range lines {
+		n := l.End - l.Start
+		want := fixed.Rectangle26_6{
+			Min: fixed.Point26_6{Y: height * fixed.Int26_6(i)},
+			Max: fixed.Point26_6{X: fixed.I(8 * n), Y: height * fixed.Int26_6(i+1)},
+		}
+		if l.Bounds != want {
+			t.Errorf("line %d: Bounds: got %v, want %v", i, l.Bounds, want)
+		}
+		if want := l.Bounds.Min.Y + fixed.I(m.Ascent.Ceil()); l.Baseline != want {
+			t.Errorf("line %d: Baseline: got %v, want %v", i, l.Baseline, want)
+		}
+	}
+}
+
+func TestLayoutRuns(t *testing.T) {
+	primary, fallback := basicfont.Face7x13, inconsolata.Regular8x16
+	fs := NewFontSet(primary, fallback)
+	s := "café ok"
+	lines := Layout(fs, s, 0)
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1", len(lines))
+	}
+	runs := lines[0].Runs
+	if len(runs) != 3 {
+		t.Fatalf("got %d runs, want 3", len(runs))
+	}
+	wantText := []string{"caf", "é", " ok"}
+	for i, r := range runs {
+		if got := s[r.Start:r.End]; got != wantText[i] {
+			t.Errorf("run %d: got %q, want %q", i, got, wantText[i])
+		}
+	}
+	if runs[0].Face != primary || runs[1].Face != fallback || runs[2].Face != primary {
+		t.Errorf("runs used the wrong faces")
+	}
+	if got, want := runs[1].X, fixed.I(3*7); got != want {
+		t.Errorf("run 1: X: got %v, want %v", got, want)
+	}
+}
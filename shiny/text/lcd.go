@@ -0,0 +1,79 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// DrawLCD rasterizes s onto dst, starting at dot, as per-subpixel coverage for
+// LCD (subpixel) antialiasing. It returns the advance width of s.
+//
+// Instead of a single grayscale coverage value per pixel, the red, green and
+// blue channels of dst hold the coverage of the left, middle and right thirds
+// of each pixel, and the alpha channel holds the largest of the three. Each
+// third is rasterized by asking face for the glyph at a dot shifted by a third
+// of a pixel, so faces that round the dot to whole pixels will produce the
+// same result as grayscale antialiasing.
+//
+// The result is meant to be uploaded to a screen.Texture and used as a
+// screen.DrawOptions Mask with a non-zero SubpixelOrder, which maps the left,
+// middle and right thirds to the display's physical subpixels.
+func DrawLCD(dst *image.RGBA, face font.Face, dot fixed.Point26_6, s string) fixed.Int26_6 {
+	// third is a third of a pixel, in 26.6 fixed point.
+	const third = fixed.Int26_6(64 / 3)
+
+	x0 := dot.X
+	prevC := rune(-1)
+	for _, c := range s {
+		if prevC >= 0 {
+			dot.X += face.Kern(prevC, c)
+		}
+		var advance fixed.Int26_6
+		for k := 0; k < 3; k++ {
+			// The left third's coverage is that of a whole pixel a third of
+			// a pixel to the left, which is the glyph shifted a third of a
+			// pixel to the right, and vice versa for the right third.
+			p := fixed.Point26_6{X: dot.X + fixed.Int26_6(1-k)*third, Y: dot.Y}
+			dr, mask, maskp, a, ok := face.Glyph(p, c)
+			if !ok {
+				// TODO: is falling back on the U+FFFD glyph the responsibility
+				// of the caller or the Face?
+				continue
+			}
+			advance = a
+			drawLCDChannel(dst, k, dr, mask, maskp)
+		}
+		dot.X += advance
+		prevC = c
+	}
+	return dot.X - x0
+}
+
+// drawLCDChannel sets channel k (0 for red, 1 for green, 2 for blue) of dst
+// to the coverage given by mask, keeping the largest of the existing and new
+// coverage values. It also updates dst's alpha channel to be the largest of
+// its red, green and blue channels, so that dst stays valid premultiplied
+// color.
+func drawLCDChannel(dst *image.RGBA, k int, dr image.Rectangle, mask image.Image, maskp image.Point) {
+	r := dr.Intersect(dst.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			_, _, _, ma := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA()
+			cov := uint8(ma >> 8)
+			i := dst.PixOffset(x, y)
+			pix := dst.Pix[i : i+4 : i+4]
+			if pix[k] < cov {
+				pix[k] = cov
+			}
+			if pix[3] < pix[k] {
+				pix[3] = pix[k]
+			}
+		}
+	}
+}
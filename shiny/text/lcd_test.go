@@ -0,0 +1,116 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/inconsolata"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestDrawLCD(t *testing.T) {
+	face := inconsolata.Regular8x16
+	const s = "Hello"
+	dst := image.NewRGBA(image.Rect(0, 0, 64, 16))
+	dot := fixed.P(2, face.Metrics().Ascent.Ceil())
+
+	advance := DrawLCD(dst, face, dot, s)
+	if want := font.MeasureString(face, s); advance != want {
+		t.Errorf("advance: got %v, want %v", advance, want)
+	}
+
+	drawn := false
+	for i := 0; i < len(dst.Pix); i += 4 {
+		r, g, b, a := dst.Pix[i+0], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3]
+		max := r
+		if max < g {
+			max = g
+		}
+		if max < b {
+			max = b
+		}
+		if a != max {
+			t.Fatalf("pixel %d: alpha %#02x is not the maximum of (%#02x, %#02x, %#02x)", i/4, a, r, g, b)
+		}
+		drawn = drawn || a != 0
+	}
+	if !drawn {
+		t.Fatal("no pixels were drawn")
+	}
+}
+
+// barFace implements the font.Face interface with glyphs that are a
+// barWidth pixel wide bar, antialiased with exact horizontal coverage, so
+// that every subpixel shift of the dot changes the result.
+type barFace struct{}
+
+const barWidth = 4
+
+func (barFace) Close() error {
+	return nil
+}
+
+func (barFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	x0, x1 := dot.X, dot.X+fixed.I(barWidth)
+	dr := image.Rect(x0.Floor(), dot.Y.Floor()-barWidth, x1.Ceil(), dot.Y.Floor())
+	mask := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	for x := dr.Min.X; x < dr.Max.X; x++ {
+		lo, hi := fixed.I(x), fixed.I(x+1)
+		if lo < x0 {
+			lo = x0
+		}
+		if hi > x1 {
+			hi = x1
+		}
+		cov := color.Alpha{uint8(int(hi-lo) * 0xff / 64)}
+		for y := 0; y < dr.Dy(); y++ {
+			mask.SetAlpha(x-dr.Min.X, y, cov)
+		}
+	}
+	return dr, mask, image.Point{}, fixed.I(barWidth + 1), true
+}
+
+func (barFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return fixed.R(0, -barWidth, barWidth, 0), fixed.I(barWidth + 1), true
+}
+
+func (barFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return fixed.I(barWidth + 1), true
+}
+
+func (barFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return 0
+}
+
+func (barFace) Metrics() font.Metrics {
+	return font.Metrics{
+		Ascent: fixed.I(barWidth),
+	}
+}
+
+func TestDrawLCDSubpixelOrder(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 16, barWidth))
+	// The bar covers [2.5, 6.5) in pixels, so that pixel 2 is its left edge
+	// and pixel 6 its right edge.
+	dot := fixed.Point26_6{X: fixed.I(2) + 32, Y: fixed.I(barWidth)}
+	DrawLCD(dst, barFace{}, dot, "|")
+
+	// At the left edge, the right (blue) third of the pixel is inside the
+	// bar and the left (red) third is outside it, and vice versa at the
+	// right edge.
+	if c := dst.RGBAAt(2, 0); c.R >= c.B {
+		t.Errorf("left edge: got %v, want R < B", c)
+	}
+	if c := dst.RGBAAt(6, 0); c.R <= c.B {
+		t.Errorf("right edge: got %v, want R > B", c)
+	}
+	if c := dst.RGBAAt(4, 0); c.R != 0xff || c.G != 0xff || c.B != 0xff {
+		t.Errorf("middle: got %v, want full coverage", c)
+	}
+}
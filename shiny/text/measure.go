@@ -0,0 +1,36 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// MeasureRunes returns the cumulative advance widths of the runes in s, when
+// drawn with face, accounting for kerning. The returned slice has one element
+// per rune: the i'th element is the distance from the start of s to the end
+// of the i'th rune. A caret placed after the i'th rune is at that offset, and
+// one placed before the first rune is at zero.
+//
+// Runes that face has no glyph for have a zero advance width.
+func MeasureRunes(face font.Face, s string) []fixed.Int26_6 {
+	var (
+		ret   []fixed.Int26_6
+		x     fixed.Int26_6
+		prevC = rune(-1)
+	)
+	for _, c := range s {
+		if prevC >= 0 {
+			x += face.Kern(prevC, c)
+		}
+		if a, ok := face.GlyphAdvance(c); ok {
+			x += a
+		}
+		ret = append(ret, x)
+		prevC = c
+	}
+	return ret
+}
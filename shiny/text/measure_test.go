@@ -0,0 +1,47 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/inconsolata"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestMeasureRunes(t *testing.T) {
+	face := inconsolata.Regular8x16
+	testCases := []string{
+		"",
+		"a",
+		"Hello",
+		"héllo, 世界",
+	}
+	for _, s := range testCases {
+		got := MeasureRunes(face, s)
+		if n := len([]rune(s)); len(got) != n {
+			t.Errorf("%q: got %d advances, want %d", s, len(got), n)
+			continue
+		}
+		i := 0
+		for j := range s {
+			if j == 0 {
+				continue
+			}
+			if want := font.MeasureString(face, s[:j]); got[i] != want {
+				t.Errorf("%q: rune %d: got %v, want %v", s, i, got[i], want)
+			}
+			i++
+		}
+		want := fixed.Int26_6(0)
+		if len(got) > 0 {
+			want = got[len(got)-1]
+		}
+		if total := font.MeasureString(face, s); want != total {
+			t.Errorf("%q: total: got %v, want %v", s, want, total)
+		}
+	}
+}
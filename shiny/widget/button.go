@@ -0,0 +1,224 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/exp/shiny/unit"
+	"golang.org/x/exp/shiny/widget/node"
+	"golang.org/x/exp/shiny/widget/theme"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/mouse"
+)
+
+// Button is a shell widget that calls OnClick when clicked with the left
+// mouse button or, when it has the keyboard focus, when Space or Enter is
+// pressed. A left click gives it the keyboard focus.
+//
+// Its inner widget, typically a Label, is drawn over a background whose
+// color shows whether the mouse pointer is over the button, whether the
+// button is pressed and whether it is disabled.
+type Button struct {
+	node.ShellEmbed
+	OnClick func()
+
+	// Disabled is whether the button ignores clicks and key presses. A
+	// disabled button still consumes mouse events, so that they do not
+	// reach the widgets behind it. Call SetDisabled, instead of setting
+	// Disabled directly, to also repaint the button.
+	Disabled bool
+
+	hovered, pressed, focused bool
+}
+
+// NewButton returns a new Button widget.
+func NewButton(inner node.Node, onClick func()) *Button {
+	w := &Button{
+		OnClick: onClick,
+	}
+	w.Wrapper = w
+	if inner != nil {
+		w.Insert(inner, nil)
+	}
+	return w
+}
+
+// NewTextButton returns a new Button widget holding a Label with the given
+// text.
+func NewTextButton(text string, onClick func()) *Button {
+	return NewButton(NewLabel(text), onClick)
+}
+
+// buttonMargin is the space between a Button's edges and its inner widget.
+var buttonMargin = unit.Ems(0.25)
+
+// SetDisabled sets w.Disabled, repainting w if it changed.
+func (w *Button) SetDisabled(disabled bool) {
+	if w.Disabled == disabled {
+		return
+	}
+	w.Disabled = disabled
+	w.pressed = false
+	w.Mark(node.MarkNeedsPaintBase)
+}
+
+// setState sets w's state, repainting w if it changed.
+func (w *Button) setState(hovered, pressed, focused bool) {
+	if w.hovered == hovered && w.pressed == pressed && w.focused == focused {
+		return
+	}
+	w.hovered, w.pressed, w.focused = hovered, pressed, focused
+	w.Mark(node.MarkNeedsPaintBase)
+}
+
+func (w *Button) setHovered(hovered bool) {
+	// A button that the pointer left is no longer pressed, so releasing
+	// the mouse button elsewhere does not click it.
+	w.setState(hovered, w.pressed && hovered, w.focused)
+}
+
+func (w *Button) setFocused(focused bool) {
+	w.setState(w.hovered, w.pressed, focused)
+}
+
+func (w *Button) click() {
+	if !w.Disabled && w.OnClick != nil {
+		w.OnClick()
+	}
+}
+
+func (w *Button) Measure(t *theme.Theme, widthHint, heightHint int) {
+	margin2 := t.Pixels(buttonMargin).Round() * 2
+	if widthHint >= 0 {
+		widthHint = max0(widthHint - margin2)
+	}
+	if heightHint >= 0 {
+		heightHint = max0(heightHint - margin2)
+	}
+	w.ShellEmbed.Measure(t, widthHint, heightHint)
+	w.MeasuredSize.X += margin2
+	w.MeasuredSize.Y += margin2
+}
+
+func max0(x int) int {
+	if x < 0 {
+		return 0
+	}
+	return x
+}
+
+func (w *Button) Layout(t *theme.Theme) {
+	if c := w.FirstChild; c != nil {
+		c.Rect = w.Rect.Sub(w.Rect.Min).Inset(t.Pixels(buttonMargin).Round())
+		c.Wrapper.Layout(t)
+	}
+}
+
+func (w *Button) PaintBase(ctx *node.PaintBaseContext, origin image.Point) error {
+	w.Marks.UnmarkNeedsPaintBase()
+	r := w.Rect.Add(origin)
+
+	bg := theme.Neutral
+	switch {
+	case w.Disabled:
+		bg = theme.Background
+	case w.pressed:
+		bg = theme.Dark
+	case w.hovered:
+		bg = theme.Light
+	}
+	draw.Draw(ctx.Dst, r, bg.Uniform(ctx.Theme), image.Point{}, draw.Src)
+	if w.focused && !w.Disabled {
+		drawOutline(ctx.Dst, r, theme.Accent.Uniform(ctx.Theme))
+	}
+
+	if c := w.FirstChild; c != nil {
+		if err := c.Wrapper.PaintBase(ctx, origin.Add(w.Rect.Min)); err != nil {
+			return err
+		}
+	}
+	if w.Disabled {
+		// Fade the inner widget into the background.
+		draw.DrawMask(ctx.Dst, r, bg.Uniform(ctx.Theme), image.Point{},
+			image.NewUniform(color.Alpha{0x80}), image.Point{}, draw.Over)
+	}
+	return nil
+}
+
+// drawOutline draws a one pixel wide outline just inside r.
+func drawOutline(dst draw.Image, r image.Rectangle, src image.Image) {
+	draw.Draw(dst, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+1), src, image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(r.Min.X, r.Max.Y-1, r.Max.X, r.Max.Y), src, image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(r.Min.X, r.Min.Y, r.Min.X+1, r.Max.Y), src, image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(r.Max.X-1, r.Min.Y, r.Max.X, r.Max.Y), src, image.Point{}, draw.Src)
+}
+
+func (w *Button) OnInputEvent(e interface{}, origin image.Point) node.EventHandled {
+	switch e := e.(type) {
+	case mouse.Event:
+		if w.Disabled {
+			return node.Handled
+		}
+		p := image.Point{int(e.X), int(e.Y)}
+		inside := p.In(w.Rect.Add(origin))
+		if e.Button != mouse.ButtonLeft {
+			w.setHovered(inside)
+			return node.Handled
+		}
+		switch e.Direction {
+		case mouse.DirPress:
+			w.setState(inside, inside, w.focused)
+		case mouse.DirRelease:
+			clicked := w.pressed && inside
+			w.setState(inside, false, w.focused)
+			if clicked {
+				w.click()
+			}
+		}
+		return node.Handled
+
+	case key.Event:
+		if !w.focused || w.Disabled || e.Direction != key.DirPress {
+			break
+		}
+		switch e.Code {
+		case key.CodeSpacebar, key.CodeReturnEnter, key.CodeKeypadEnter:
+			w.click()
+			return node.Handled
+		}
+	}
+	return node.NotHandled
+}
+
+// interactive is a widget, such as a Button, that tracks whether the mouse
+// pointer is over it and whether it has the keyboard focus. RunWindow sends
+// key events only to the focused interactive widget.
+type interactive interface {
+	node.Node
+	setHovered(hovered bool)
+	setFocused(focused bool)
+}
+
+// interactiveAt returns the innermost interactive widget in the tree rooted
+// at n that contains p, and the origin to pass to its OnInputEvent method, or
+// nil if there is no such widget.
+func interactiveAt(n node.Node, p, origin image.Point) (interactive, image.Point) {
+	e := n.Wrappee()
+	if !p.In(e.Rect.Add(origin)) {
+		return nil, image.Point{}
+	}
+	for c := e.FirstChild; c != nil; c = c.NextSibling {
+		if i, o := interactiveAt(c.Wrapper, p, origin.Add(e.Rect.Min)); i != nil {
+			return i, o
+		}
+	}
+	if i, ok := n.(interactive); ok {
+		return i, origin
+	}
+	return nil, image.Point{}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package widget
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/exp/shiny/widget/node"
+	"golang.org/x/mobile/event/key"
+	"golang.org/x/mobile/event/mouse"
+)
+
+func TestButton(t *testing.T) {
+	clicks := 0
+	b := NewButton(nil, func() { clicks++ })
+	b.Rect = image.Rect(10, 10, 50, 30)
+
+	press := func(x, y float32, dir mouse.Direction) {
+		b.OnInputEvent(mouse.Event{X: x, Y: y, Button: mouse.ButtonLeft, Direction: dir}, image.Point{})
+	}
+
+	press(20, 20, mouse.DirPress)
+	if !b.pressed || !b.Marks.NeedsPaintBase() {
+		t.Fatalf("after press: pressed=%t, marks=%v", b.pressed, b.Marks)
+	}
+	press(20, 20, mouse.DirRelease)
+	if clicks != 1 || b.pressed {
+		t.Fatalf("after release: clicks=%d, pressed=%t", clicks, b.pressed)
+	}
+
+	// Releasing outside the button does not click it.
+	press(20, 20, mouse.DirPress)
+	press(100, 100, mouse.DirRelease)
+	if clicks != 1 {
+		t.Fatalf("after release outside: clicks=%d", clicks)
+	}
+
+	// Unchanged state does not repaint.
+	b.Marks.UnmarkNeedsPaintBase()
+	b.setHovered(b.hovered)
+	if b.Marks.NeedsPaintBase() {
+		t.Fatal("unchanged state marked the button")
+	}
+
+	enter := key.Event{Code: key.CodeReturnEnter, Direction: key.DirPress}
+	if got := b.OnInputEvent(enter, image.Point{}); got != node.NotHandled || clicks != 1 {
+		t.Fatalf("key without focus: handled=%v, clicks=%d", got, clicks)
+	}
+	b.setFocused(true)
+	if got := b.OnInputEvent(enter, image.Point{}); got != node.Handled || clicks != 2 {
+		t.Fatalf("key with focus: handled=%v, clicks=%d", got, clicks)
+	}
+
+	b.SetDisabled(true)
+	press(20, 20, mouse.DirPress)
+	press(20, 20, mouse.DirRelease)
+	b.OnInputEvent(enter, image.Point{})
+	if clicks != 2 {
+		t.Fatalf("disabled: clicks=%d", clicks)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package widget
+
+import (
+	"image"
+
+	"golang.org/x/exp/shiny/widget/node"
+	"golang.org/x/exp/shiny/widget/theme"
+)
+
+// Canvas is a leaf widget that paints by calling a function, for custom
+// drawing such as charts or game views.
+type Canvas struct {
+	node.LeafEmbed
+
+	// Size is the Canvas' measured size.
+	Size image.Point
+
+	// PaintFunc, if non-nil, is called by Paint with the Canvas' layout
+	// rectangle, in ctx.Drawer's coordinate space. If ctx.Drawer is a
+	// screen.Window, drawing is clipped to that rectangle.
+	PaintFunc func(ctx *node.PaintContext, rect image.Rectangle)
+}
+
+// NewCanvas returns a new Canvas widget of the given measured size that
+// paints by calling f.
+func NewCanvas(size image.Point, f func(ctx *node.PaintContext, rect image.Rectangle)) *Canvas {
+	w := &Canvas{
+		Size:      size,
+		PaintFunc: f,
+	}
+	w.Wrapper = w
+	return w
+}
+
+func (w *Canvas) Measure(t *theme.Theme, widthHint, heightHint int) {
+	w.MeasuredSize = w.Size
+}
+
+func (w *Canvas) Paint(ctx *node.PaintContext, origin image.Point) error {
+	w.Marks.UnmarkNeedsPaint()
+	if w.PaintFunc == nil {
+		return nil
+	}
+
+	// rect is the layout rectangle in dst-space, assuming that ctx.Src2Dst
+	// is a translation, as it is for RunWindow and Sheet.
+	rect := w.Rect.Add(origin).Add(image.Point{
+		X: int(ctx.Src2Dst[2]),
+		Y: int(ctx.Src2Dst[5]),
+	})
+	type clipper interface {
+		PushClip(r image.Rectangle)
+		PopClip()
+	}
+	if c, ok := ctx.Drawer.(clipper); ok {
+		c.PushClip(rect)
+		defer c.PopClip()
+	}
+	w.PaintFunc(ctx, rect)
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package widget
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/shiny/widget/node"
+	"golang.org/x/mobile/event/key"
+)
+
+// Dump writes the widget tree rooted at root to w, for debugging layout. It
+// writes one line per node, indented by the node's depth in the tree, holding
+// the node's type, Rect, MeasuredSize and Marks.
+func Dump(root node.Node, w io.Writer) error {
+	return dump(w, root.Wrappee(), 0)
+}
+
+func dump(w io.Writer, e *node.Embed, depth int) error {
+	_, err := fmt.Fprintf(w, "%s%T rect=%v measured=%v marks=%s\n",
+		strings.Repeat("  ", depth), e.Wrapper, e.Rect, e.MeasuredSize, marksString(e.Marks))
+	if err != nil {
+		return err
+	}
+	for c := e.FirstChild; c != nil; c = c.NextSibling {
+		if err := dump(w, c, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marksString(m node.Marks) string {
+	var s []string
+	if m.NeedsMeasureLayout() {
+		s = append(s, "NeedsMeasureLayout")
+	}
+	if m.NeedsPaint() {
+		s = append(s, "NeedsPaint")
+	}
+	if m.NeedsPaintBase() {
+		s = append(s, "NeedsPaintBase")
+	}
+	if len(s) == 0 {
+		return "0"
+	}
+	return strings.Join(s, "|")
+}
+
+// debugColors are the outline colors used by PaintDebugOverlay, indexed by
+// node depth modulo their length.
+var debugColors = [...]color.Color{
+	color.RGBA{0xc0, 0x00, 0x00, 0xc0},
+	color.RGBA{0x00, 0xa0, 0x00, 0xc0},
+	color.RGBA{0x00, 0x00, 0xc0, 0xc0},
+	color.RGBA{0xa0, 0x80, 0x00, 0xc0},
+	color.RGBA{0x80, 0x00, 0xa0, 0xc0},
+	color.RGBA{0x00, 0x80, 0xa0, 0xc0},
+}
+
+// PaintDebugOverlay outlines the bounds of every node in the widget tree
+// rooted at root, for debugging layout. The outline colors vary with the
+// node's depth in the tree.
+//
+// origin is root's parent's origin, as for the Node.Paint method.
+func PaintDebugOverlay(ctx *node.PaintContext, root node.Node, origin image.Point) {
+	paintDebugOverlay(ctx, root.Wrappee(), origin, 0)
+}
+
+func paintDebugOverlay(ctx *node.PaintContext, e *node.Embed, origin image.Point, depth int) {
+	r := e.Rect.Add(origin)
+	paintOutline(ctx, r, debugColors[depth%len(debugColors)])
+	for c := e.FirstChild; c != nil; c = c.NextSibling {
+		paintDebugOverlay(ctx, c, r.Min, depth+1)
+	}
+}
+
+// paintOutline draws a one pixel wide outline just inside r.
+func paintOutline(ctx *node.PaintContext, r image.Rectangle, c color.Color) {
+	for _, edge := range [...]image.Rectangle{
+		{r.Min, image.Point{r.Max.X, r.Min.Y + 1}},
+		{image.Point{r.Min.X, r.Max.Y - 1}, r.Max},
+		{image.Point{r.Min.X, r.Min.Y + 1}, image.Point{r.Min.X + 1, r.Max.Y - 1}},
+		{image.Point{r.Max.X - 1, r.Min.Y + 1}, image.Point{r.Max.X, r.Max.Y - 1}},
+	} {
+		if !edge.Empty() {
+			ctx.Drawer.DrawUniform(ctx.Src2Dst, c, edge, draw.Over, nil)
+		}
+	}
+}
+
+// inspectKeyEnv is the environment variable that enables the layout
+// inspector in RunWindow. Its value names the key that toggles the
+// inspector, such as "F12". While the inspector is on, the node under the
+// mouse pointer is highlighted and its bounds are printed to standard error.
+const inspectKeyEnv = "SHINY_INSPECT_KEY"
+
+// inspectKey returns the key named by the inspectKeyEnv environment
+// variable, and whether there is such a key.
+func inspectKey() (key.Code, bool) {
+	name := os.Getenv(inspectKeyEnv)
+	if name == "" {
+		return 0, false
+	}
+	// The key.Code String method returns names such as "CodeF12".
+	for c := key.Code(0); c <= key.CodeRightGUI; c++ {
+		if c.String() == "Code"+name {
+			return c, true
+		}
+	}
+	log.Printf("widget: unknown %s key %q", inspectKeyEnv, name)
+	return 0, false
+}
+
+// nodeAt returns the innermost node in the tree rooted at e that contains p,
+// and that node's parent's origin, or nil if e does not contain p. Later
+// siblings are painted over earlier ones, so they are searched first.
+func nodeAt(e *node.Embed, p, origin image.Point) (*node.Embed, image.Point) {
+	if !p.In(e.Rect.Add(origin)) {
+		return nil, image.Point{}
+	}
+	for c := e.LastChild; c != nil; c = c.PrevSibling {
+		if n, o := nodeAt(c, p, origin.Add(e.Rect.Min)); n != nil {
+			return n, o
+		}
+	}
+	return e, origin
+}
+
+// inspectColor is the highlight color of the node under the mouse pointer.
+var inspectColor = color.RGBA{0x00, 0x40, 0x80, 0x40}
+
+// paintInspectOverlay highlights the innermost node in the tree rooted at
+// root that contains p, and returns that node, or nil if there is none.
+func paintInspectOverlay(ctx *node.PaintContext, root node.Node, p image.Point) *node.Embed {
+	n, origin := nodeAt(root.Wrappee(), p, image.Point{})
+	if n == nil {
+		return nil
+	}
+	r := n.Rect.Add(origin)
+	ctx.Drawer.DrawUniform(ctx.Src2Dst, inspectColor, r, draw.Over, nil)
+	paintOutline(ctx, r, debugColors[0])
+	return n
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package widget
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"golang.org/x/exp/shiny/widget/node"
+)
+
+func TestDump(t *testing.T) {
+	inner := NewSpace()
+	inner.Rect = image.Rect(2, 3, 12, 13)
+	inner.MeasuredSize = image.Point{10, 10}
+	root := NewUniform(nil, inner)
+	root.Rect = image.Rect(0, 0, 20, 20)
+	root.Mark(node.MarkNeedsPaint | node.MarkNeedsPaintBase)
+
+	buf := new(bytes.Buffer)
+	if err := Dump(root, buf); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	const want = "" +
+		"*widget.Uniform rect=(0,0)-(20,20) measured=(0,0) marks=NeedsPaint|NeedsPaintBase\n" +
+		"  *widget.Space rect=(2,3)-(12,13) measured=(10,10) marks=0\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNodeAt(t *testing.T) {
+	inner := NewSpace()
+	inner.Rect = image.Rect(2, 3, 12, 13)
+	root := NewUniform(nil, inner)
+	root.Rect = image.Rect(10, 10, 30, 30)
+
+	testCases := []struct {
+		p          image.Point
+		want       *node.Embed
+		wantOrigin image.Point
+	}{
+		{image.Point{5, 5}, nil, image.Point{}},
+		{image.Point{10, 10}, &root.Embed, image.Point{}},
+		{image.Point{12, 13}, &inner.Embed, image.Point{10, 10}},
+		{image.Point{21, 22}, &inner.Embed, image.Point{10, 10}},
+		{image.Point{22, 23}, &root.Embed, image.Point{}},
+	}
+	for _, tc := range testCases {
+		got, origin := nodeAt(root.Wrappee(), tc.p, image.Point{})
+		if got != tc.want || origin != tc.wantOrigin {
+			t.Errorf("p=%v: got %p at %v, want %p at %v", tc.p, got, origin, tc.want, tc.wantOrigin)
+		}
+	}
+}
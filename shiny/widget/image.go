@@ -7,9 +7,13 @@ package widget
 import (
 	"image"
 	"image/draw"
+	"reflect"
 
+	"golang.org/x/exp/shiny/screen"
 	"golang.org/x/exp/shiny/widget/node"
 	"golang.org/x/exp/shiny/widget/theme"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/mobile/event/lifecycle"
 )
 
 // TODO: mask and maskPoint, not just src and srcRect.
@@ -17,17 +21,58 @@ import (
 // TODO: be able to specify the draw operator: Src instead of Over.
 
 // TODO: if the measured size differs from the actual size, specify a
-// background color (or tile-able image like a checkerboard)? Specify a
-// draw.Scaler from the golang.org/x/image/draw package? Be able to center the
-// source image within the widget?
+// background color (or tile-able image like a checkerboard)?
 
-// Image is a leaf widget that paints an image.Image.
+// Image is a leaf widget that paints an image.Image or a screen.Texture.
+//
+// With the default ScaleNone scaling and no Texture, the image is painted by
+// PaintBase, onto the buffer of an enclosing Sheet. Otherwise, it is painted
+// by Paint, scaled by the screen.Drawer. An image.Image Src is then uploaded
+// to a cached screen.Texture, which is only re-uploaded when SrcRect changes
+// or Src is set to a different image. Src is compared by pointer, so a Src
+// whose type is not a pointer, unlike the image package's types, is
+// re-uploaded every time.
 type Image struct {
 	node.LeafEmbed
 	Src     image.Image
 	SrcRect image.Rectangle
+
+	// Texture, if non-nil, is painted instead of Src. SrcRect is then in
+	// the Texture's coordinate space.
+	Texture screen.Texture
+
+	// Scaling is how the image is scaled to the widget's layout rectangle.
+	Scaling ImageScaling
+
+	// tex is the texture that src and srcRect were uploaded to.
+	tex     screen.Texture
+	src     image.Image
+	srcRect image.Rectangle
 }
 
+// ImageScaling is how an Image widget scales its image to its layout
+// rectangle.
+type ImageScaling uint8
+
+const (
+	// ScaleNone paints the image unscaled, at the top-left of the layout
+	// rectangle.
+	ScaleNone ImageScaling = iota
+	// ScaleCenter paints the image unscaled, centered in the layout
+	// rectangle.
+	ScaleCenter
+	// ScaleFit scales the image, preserving its aspect ratio, to the largest
+	// size that fits in the layout rectangle, and centers it.
+	ScaleFit
+	// ScaleFill scales the image, preserving its aspect ratio, to the
+	// smallest size that covers the layout rectangle, and centers it. Parts
+	// of the image may be cropped.
+	ScaleFill
+	// ScaleStretch scales the image to the layout rectangle, ignoring its
+	// aspect ratio.
+	ScaleStretch
+)
+
 // NewImage returns a new Image widget for the part of a source image defined
 // by src and srcRect.
 func NewImage(src image.Image, srcRect image.Rectangle) *Image {
@@ -39,13 +84,30 @@ func NewImage(src image.Image, srcRect image.Rectangle) *Image {
 	return w
 }
 
+// NewTextureImage returns a new Image widget for the part of a texture
+// defined by src and srcRect, scaled by the given scaling.
+func NewTextureImage(src screen.Texture, srcRect image.Rectangle, scaling ImageScaling) *Image {
+	w := &Image{
+		Texture: src,
+		SrcRect: srcRect,
+		Scaling: scaling,
+	}
+	w.Wrapper = w
+	return w
+}
+
 func (w *Image) Measure(t *theme.Theme, widthHint, heightHint int) {
 	w.MeasuredSize = w.SrcRect.Size()
 }
 
+// paintsBase returns whether w is painted by PaintBase instead of Paint.
+func (w *Image) paintsBase() bool {
+	return w.Texture == nil && w.Scaling == ScaleNone
+}
+
 func (w *Image) PaintBase(ctx *node.PaintBaseContext, origin image.Point) error {
 	w.Marks.UnmarkNeedsPaintBase()
-	if w.Src == nil {
+	if w.Src == nil || !w.paintsBase() {
 		return nil
 	}
 
@@ -60,3 +122,121 @@ func (w *Image) PaintBase(ctx *node.PaintBaseContext, origin image.Point) error
 	draw.Draw(ctx.Dst, wRect.Intersect(sRect), w.Src, w.SrcRect.Min, draw.Over)
 	return nil
 }
+
+func (w *Image) Paint(ctx *node.PaintContext, origin image.Point) error {
+	w.Marks.UnmarkNeedsPaint()
+	if w.paintsBase() {
+		w.release()
+		return nil
+	}
+	tex := w.Texture
+	if tex == nil {
+		if w.Src == nil {
+			w.release()
+			return nil
+		}
+		var err error
+		if tex, err = w.upload(ctx.Screen); err != nil {
+			return err
+		}
+	}
+	sr := w.SrcRect
+	if sr.Empty() {
+		return nil
+	}
+
+	wRect := w.Rect.Add(origin)
+	dr := scaleRect(w.Scaling, wRect, sr.Size())
+	src2dst := ctx.Src2Dst
+	translate(&src2dst, float64(dr.Min.X), float64(dr.Min.Y))
+	scale(&src2dst, float64(dr.Dx())/float64(sr.Dx()), float64(dr.Dy())/float64(sr.Dy()))
+	translate(&src2dst, -float64(sr.Min.X), -float64(sr.Min.Y))
+
+	opts := screen.NewDrawOptions(screen.WithFilter(screen.FilterLinear))
+	if ctx.Src2Dst[1] == 0 && ctx.Src2Dst[3] == 0 {
+		// Only clip to wRect if the clip, like wRect, is axis-aligned in dst
+		// space.
+		opts.Clip = image.Rect(
+			int(ctx.Src2Dst[0]*float64(wRect.Min.X)+ctx.Src2Dst[2]),
+			int(ctx.Src2Dst[4]*float64(wRect.Min.Y)+ctx.Src2Dst[5]),
+			int(ctx.Src2Dst[0]*float64(wRect.Max.X)+ctx.Src2Dst[2]),
+			int(ctx.Src2Dst[4]*float64(wRect.Max.Y)+ctx.Src2Dst[5]),
+		).Canon()
+		if opts.Clip.Empty() {
+			return nil
+		}
+	}
+	ctx.Drawer.Draw(src2dst, tex, sr, draw.Over, opts)
+	return nil
+}
+
+// upload returns the texture holding w.Src, uploading it if w.Src or
+// w.SrcRect has changed since the last upload.
+func (w *Image) upload(s screen.Screen) (screen.Texture, error) {
+	if w.tex != nil && sameImage(w.src, w.Src) && w.srcRect == w.SrcRect {
+		return w.tex, nil
+	}
+	w.release()
+
+	// The texture and buffer have the same coordinate space as SrcRect, so
+	// that Paint can use SrcRect without translating it.
+	size := w.SrcRect.Max
+	buf, err := s.NewBuffer(size)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Release()
+	draw.Draw(buf.RGBA(), w.SrcRect, w.Src, w.SrcRect.Min, draw.Src)
+	tex, err := s.NewTexture(size)
+	if err != nil {
+		return nil, err
+	}
+	tex.Upload(w.SrcRect.Min, buf, w.SrcRect)
+	w.tex, w.src, w.srcRect = tex, w.Src, w.SrcRect
+	return tex, nil
+}
+
+// sameImage reports whether a and b are the same pointer. Comparing other
+// image.Image values can panic, as their types need not be comparable.
+func sameImage(a, b image.Image) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Kind() == reflect.Ptr && vb.Kind() == reflect.Ptr &&
+		va.Type() == vb.Type() && va.Pointer() == vb.Pointer()
+}
+
+func (w *Image) release() {
+	if w.tex != nil {
+		w.tex.Release()
+		w.tex, w.src, w.srcRect = nil, nil, image.Rectangle{}
+	}
+}
+
+func (w *Image) OnLifecycleEvent(e lifecycle.Event) {
+	if e.Crosses(lifecycle.StageVisible) == lifecycle.CrossOff {
+		w.release()
+	}
+}
+
+// scaleRect returns where an image of the given size is painted within r,
+// for the given scaling.
+func scaleRect(scaling ImageScaling, r image.Rectangle, size image.Point) image.Rectangle {
+	switch scaling {
+	case ScaleNone:
+		return image.Rectangle{Min: r.Min, Max: r.Min.Add(size)}
+	case ScaleStretch:
+		return r
+	case ScaleFit:
+		return screen.FitContain.Rect(r, size)
+	case ScaleFill:
+		return screen.FitCover.Rect(r, size)
+	}
+	min := r.Min.Add(r.Size().Sub(size).Div(2))
+	return image.Rectangle{Min: min, Max: min.Add(size)}
+}
+
+func scale(a *f64.Aff3, sx, sy float64) {
+	a[0] *= sx
+	a[1] *= sy
+	a[3] *= sx
+	a[4] *= sy
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package widget
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestScaleRect(t *testing.T) {
+	r := image.Rect(10, 10, 110, 60) // 100x50.
+	size := image.Point{20, 20}
+	testCases := []struct {
+		scaling ImageScaling
+		want    image.Rectangle
+	}{
+		{ScaleNone, image.Rect(10, 10, 30, 30)},
+		{ScaleCenter, image.Rect(50, 25, 70, 45)},
+		{ScaleFit, image.Rect(35, 10, 85, 60)},
+		{ScaleFill, image.Rect(10, -15, 110, 85)},
+		{ScaleStretch, r},
+	}
+	for _, tc := range testCases {
+		if got := scaleRect(tc.scaling, r, size); got != tc.want {
+			t.Errorf("scaling %d: got %v, want %v", tc.scaling, got, tc.want)
+		}
+	}
+}
+
+// funcImage is an image.Image whose type is not comparable.
+type funcImage func(x, y int) color.Color
+
+func (f funcImage) ColorModel() color.Model { return color.RGBAModel }
+func (f funcImage) Bounds() image.Rectangle { return image.Rect(0, 0, 1, 1) }
+func (f funcImage) At(x, y int) color.Color { return f(x, y) }
+
+func TestSameImage(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	b := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	f := funcImage(func(x, y int) color.Color { return color.Black })
+	testCases := []struct {
+		desc string
+		x, y image.Image
+		want bool
+	}{
+		{"same pointer", a, a, true},
+		{"different pointers", a, b, false},
+		{"nil", nil, a, false},
+		{"both nil", nil, nil, false},
+		{"not comparable", f, f, false},
+	}
+	for _, tc := range testCases {
+		if got := sameImage(tc.x, tc.y); got != tc.want {
+			t.Errorf("%s: got %t, want %t", tc.desc, got, tc.want)
+		}
+	}
+}
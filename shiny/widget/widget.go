@@ -9,6 +9,7 @@ package widget // import "golang.org/x/exp/shiny/widget"
 
 import (
 	"image"
+	"log"
 
 	"golang.org/x/exp/shiny/gesture"
 	"golang.org/x/exp/shiny/screen"
@@ -16,6 +17,7 @@ import (
 	"golang.org/x/exp/shiny/widget/node"
 	"golang.org/x/exp/shiny/widget/theme"
 	"golang.org/x/image/math/f64"
+	"golang.org/x/mobile/event/key"
 	"golang.org/x/mobile/event/lifecycle"
 	"golang.org/x/mobile/event/mouse"
 	"golang.org/x/mobile/event/paint"
@@ -48,6 +50,10 @@ type RunWindowOptions struct {
 	NewWindowOptions screen.NewWindowOptions
 	Theme            theme.Theme
 
+	// DebugLayout outlines the bounds of every node, via PaintDebugOverlay,
+	// on every paint.
+	DebugLayout bool
+
 	// TODO: some mechanism to process, filter and inject events. Perhaps a
 	// screen.EventFilter interface, and note that the zero value in this
 	// RunWindowOptions implicitly includes the gesture.EventFilter?
@@ -59,7 +65,7 @@ type RunWindowOptions struct {
 
 // TODO: how do widgets signal that they need repaint or relayout?
 
-// TODO: propagate keyboard / mouse / touch events.
+// TODO: propagate touch events.
 
 // RunWindow creates a new window for s, with the given widget tree, and runs
 // its event loop.
@@ -92,6 +98,18 @@ func RunWindow(s screen.Screen, root node.Node, opts *RunWindowOptions) error {
 	// throttle like this, should it be provided at a lower level?
 	paintPending := false
 
+	// hovered is the interactive widget, such as a Button, under the mouse
+	// pointer, and focused is the one that receives key events.
+	var hovered, focused interactive
+	var focusedOrigin image.Point
+
+	// The layout inspector, if enabled by the SHINY_INSPECT_KEY environment
+	// variable, highlights the node under the mouse pointer.
+	inspectCode, canInspect := inspectKey()
+	inspecting := false
+	var pointer image.Point
+	var inspected *node.Embed
+
 	gef := gesture.EventFilter{EventDeque: w}
 	for {
 		e := w.NextEvent()
@@ -107,9 +125,50 @@ func RunWindow(s screen.Screen, root node.Node, opts *RunWindowOptions) error {
 				return nil
 			}
 
-		case gesture.Event, mouse.Event:
+		case gesture.Event:
 			root.OnInputEvent(e, image.Point{})
 
+		case mouse.Event:
+			root.OnInputEvent(e, image.Point{})
+
+			pointer = image.Point{int(e.X), int(e.Y)}
+			if inspecting {
+				root.Mark(node.MarkNeedsPaint)
+			}
+
+			i, origin := interactiveAt(root, pointer, image.Point{})
+			if i != hovered {
+				if hovered != nil {
+					hovered.setHovered(false)
+				}
+				if i != nil {
+					i.setHovered(true)
+				}
+				hovered = i
+			}
+			if e.Direction == mouse.DirPress && i != focused {
+				if focused != nil {
+					focused.setFocused(false)
+				}
+				if i != nil {
+					i.setFocused(true)
+				}
+				focused, focusedOrigin = i, origin
+			}
+
+		case key.Event:
+			if canInspect && e.Code == inspectCode {
+				if e.Direction == key.DirPress {
+					inspecting = !inspecting
+					inspected = nil
+					root.Mark(node.MarkNeedsPaint)
+				}
+				break
+			}
+			if focused != nil {
+				focused.OnInputEvent(e, focusedOrigin)
+			}
+
 		case paint.Event:
 			ctx := &node.PaintContext{
 				Theme:  t,
@@ -123,6 +182,15 @@ func RunWindow(s screen.Screen, root node.Node, opts *RunWindowOptions) error {
 			if err := root.Paint(ctx, image.Point{}); err != nil {
 				return err
 			}
+			if opts != nil && opts.DebugLayout {
+				PaintDebugOverlay(ctx, root, image.Point{})
+			}
+			if inspecting {
+				if n := paintInspectOverlay(ctx, root, pointer); n != nil && n != inspected {
+					log.Printf("widget: inspecting %T rect=%v measured=%v", n.Wrapper, n.Rect, n.MeasuredSize)
+					inspected = n
+				}
+			}
 			w.Publish()
 			paintPending = false
 